@@ -0,0 +1,328 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package address
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/zoobc/zed25519/zed"
+)
+
+//
+//  An address is a text encoding of a public key with a checksum, so that a
+//  mistyped or truncated address is rejected rather than silently naming
+//  another key. Two encodings are supported:
+//
+//    - Bech32 (BIP-173): hrp || "1" || base32(key) || checksum, where the
+//      human-readable part hrp names the network or application (e.g. "zbc"),
+//      and the 6-character BCH checksum detects any error affecting up to 4
+//      characters. Addresses are lower-case, or all upper-case for QR codes.
+//
+//    - Base58Check: base58(version || key || sha256(sha256(version || key))[:4]),
+//      where the version bytes name the network or application, as in Bitcoin
+//      addresses.
+//
+//  In both, the key is the 32-byte public key itself, and decoding checks the
+//  prefix, the checksum, the length and that the key is the canonical
+//  encoding of a curve point, so that each key has exactly one address for a
+//  given prefix.
+//
+//  REFERENCES:
+//    [1] Pieter Wuille, Greg Maxwell, "BIP-173: Base32 address format for
+//        native v0-16 witness outputs"
+//        https://github.com/bitcoin/bips/blob/master/bip-0173.mediawiki
+//
+
+// ErrChecksum is returned when an address has a bad checksum, which means it
+// was mistyped or corrupted.
+var ErrChecksum = errors.New("address: bad checksum")
+
+// bech32Charset is the alphabet of the data part of a Bech32 string.
+const bech32Charset = "qpzry9x8gf2tvdw0s3jn54khce6mua7l"
+
+// bech32MaxLength is the maximum length of a Bech32 string.
+const bech32MaxLength = 90
+
+// EncodeBech32 encodes the public key as a lower-case Bech32 address with the
+// human-readable part hrp. It returns an error if hrp is empty, has
+// characters outside of ASCII 33 to 126 or upper-case letters, or is too long
+// for the address to fit in 90 characters.
+func EncodeBech32(hrp string, pk *zed.Public) (string, error) {
+	if err := checkHrp(hrp); err != nil {
+		return "", errors.New("EncodeBech32: " + err.Error())
+	}
+	if hrp != strings.ToLower(hrp) {
+		return "", errors.New("EncodeBech32: upper-case human-readable part")
+	}
+
+	var key = pk.Key()
+	var data = convertBits(key[:], 8, 5)
+	if len(hrp)+1+len(data)+6 > bech32MaxLength {
+		return "", errors.New("EncodeBech32: human-readable part too long")
+	}
+
+	// data || checksum
+	var values = append(data, bech32Checksum(hrp, data)...)
+	var addr = make([]byte, 0, len(hrp)+1+len(values))
+	addr = append(addr, hrp...)
+	addr = append(addr, '1')
+	for _, v := range values {
+		addr = append(addr, bech32Charset[v])
+	}
+
+	return string(addr), nil
+}
+
+// DecodeBech32 decodes a Bech32 address made by EncodeBech32, checking that
+// its human-readable part is hrp, and returns the public key. Either all
+// lower-case or all upper-case addresses are accepted, but not a mix.
+// ErrChecksum is returned for a bad checksum.
+func DecodeBech32(hrp, addr string) (*zed.Public, error) {
+	if len(addr) > bech32MaxLength {
+		return nil, errors.New("DecodeBech32: address too long")
+	}
+	var lower = strings.ToLower(addr)
+	if addr != lower && addr != strings.ToUpper(addr) {
+		return nil, errors.New("DecodeBech32: mixed case")
+	}
+
+	// addr = hrp || "1" || data, split at the last "1"
+	var sep = strings.LastIndexByte(lower, '1')
+	if sep < 1 || len(lower)-sep-1 < 6 {
+		return nil, errors.New("DecodeBech32: bad format")
+	}
+	var gotHrp = lower[:sep]
+	if err := checkHrp(gotHrp); err != nil {
+		return nil, errors.New("DecodeBech32: " + err.Error())
+	}
+	if gotHrp != strings.ToLower(hrp) {
+		return nil, errors.New("DecodeBech32: wrong human-readable part: " + gotHrp)
+	}
+
+	var values = make([]byte, 0, len(lower)-sep-1)
+	for i := sep + 1; i < len(lower); i++ {
+		var v = strings.IndexByte(bech32Charset, lower[i])
+		if v < 0 {
+			return nil, errors.New("DecodeBech32: invalid character")
+		}
+		values = append(values, byte(v))
+	}
+	if bech32Polymod(gotHrp, values) != 1 {
+		return nil, ErrChecksum
+	}
+
+	// key = data, 8 bits per byte, with no more than 4 zero padding bits
+	var data = values[:len(values)-6]
+	if len(data) != (32*8+4)/5 {
+		return nil, errors.New("DecodeBech32: bad key length")
+	}
+	var key = convertBits(data, 5, 8)
+	if len(key) != 33 || key[32] != 0 {
+		return nil, errors.New("DecodeBech32: bad padding")
+	}
+
+	var pk, err = zed.PublicFromKeyStrict(key[:32])
+	if err != nil {
+		return nil, errors.New("DecodeBech32: " + err.Error())
+	}
+	return pk, nil
+}
+
+// checkHrp checks the characters and length of a human-readable part.
+func checkHrp(hrp string) error {
+	if len(hrp) < 1 || len(hrp) > 83 {
+		return errors.New("bad human-readable part length")
+	}
+	for i := 0; i < len(hrp); i++ {
+		if hrp[i] < 33 || hrp[i] > 126 {
+			return errors.New("invalid human-readable part character")
+		}
+	}
+	return nil
+}
+
+// bech32Polymod computes the BCH checksum polynomial of hrp and values.
+func bech32Polymod(hrp string, values []byte) uint32 {
+	var generator = [5]uint32{0x3b6a57b2, 0x26508e6d, 0x1ea119fa, 0x3d4233dd, 0x2a1462b3}
+
+	var chk uint32 = 1
+	var step = func(v byte) {
+		var top = chk >> 25
+		chk = (chk&0x1ffffff)<<5 ^ uint32(v)
+		for i := range generator {
+			if (top>>uint(i))&1 == 1 {
+				chk ^= generator[i]
+			}
+		}
+	}
+
+	// hrp is expanded to (high bits of each char) || 0 || (low bits of each char)
+	for i := 0; i < len(hrp); i++ {
+		step(hrp[i] >> 5)
+	}
+	step(0)
+	for i := 0; i < len(hrp); i++ {
+		step(hrp[i] & 31)
+	}
+	for _, v := range values {
+		step(v)
+	}
+
+	return chk
+}
+
+// bech32Checksum computes the 6 checksum values of hrp and data.
+func bech32Checksum(hrp string, data []byte) []byte {
+	var values = append(append([]byte{}, data...), 0, 0, 0, 0, 0, 0)
+	var mod = bech32Polymod(hrp, values) ^ 1
+
+	var checksum = make([]byte, 6)
+	for i := range checksum {
+		checksum[i] = byte(mod>>uint(5*(5-i))) & 31
+	}
+	return checksum
+}
+
+// convertBits regroups data from groups of from bits into groups of to bits,
+// padding the last group with zero bits.
+func convertBits(data []byte, from, to uint) []byte {
+	var acc, bits uint
+	var out []byte
+	for _, v := range data {
+		acc = acc<<from | uint(v)
+		bits += from
+		for bits >= to {
+			bits -= to
+			out = append(out, byte(acc>>bits)&(1<<to-1))
+		}
+	}
+	if bits > 0 {
+		out = append(out, byte(acc<<(to-bits))&(1<<to-1))
+	}
+	return out
+}
+
+// base58Alphabet is the Base58 alphabet used by Bitcoin.
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// EncodeBase58Check encodes the public key as a Base58Check address with the
+// given version bytes, which may be empty.
+func EncodeBase58Check(version []byte, pk *zed.Public) string {
+	var key = pk.Key()
+
+	// payload = version || key || sha256(sha256(version || key))[:4]
+	var payload = append(append([]byte{}, version...), key[:]...)
+	var checksum = base58Checksum(payload)
+	payload = append(payload, checksum[:]...)
+
+	return base58Encode(payload)
+}
+
+// DecodeBase58Check decodes a Base58Check address made by EncodeBase58Check,
+// checking that it has the given version bytes, and returns the public key.
+// ErrChecksum is returned for a bad checksum.
+func DecodeBase58Check(version []byte, addr string) (*zed.Public, error) {
+	var payload, ok = base58Decode(addr)
+	if !ok {
+		return nil, errors.New("DecodeBase58Check: invalid character")
+	}
+	if len(payload) != len(version)+32+4 {
+		return nil, errors.New("DecodeBase58Check: bad address length")
+	}
+
+	// if checksum != sha256(sha256(version || key))[:4], fail
+	var body = payload[:len(payload)-4]
+	var checksum = base58Checksum(body)
+	if !bytes.Equal(checksum[:], payload[len(body):]) {
+		return nil, ErrChecksum
+	}
+	if !bytes.Equal(body[:len(version)], version) {
+		return nil, errors.New("DecodeBase58Check: wrong version")
+	}
+
+	var pk, err = zed.PublicFromKeyStrict(body[len(version):])
+	if err != nil {
+		return nil, errors.New("DecodeBase58Check: " + err.Error())
+	}
+	return pk, nil
+}
+
+// base58Checksum computes sha256(sha256(payload))[:4].
+func base58Checksum(payload []byte) [4]byte {
+	var h1 = sha256.Sum256(payload)
+	var h2 = sha256.Sum256(h1[:])
+
+	var checksum [4]byte
+	copy(checksum[:], h2[:])
+	return checksum
+}
+
+// base58Encode encodes b in Base58, with each leading zero byte as a "1".
+func base58Encode(b []byte) string {
+	var n = new(big.Int).SetBytes(b)
+	var radix = big.NewInt(58)
+	var mod = new(big.Int)
+
+	var out []byte
+	for n.Sign() > 0 {
+		n.DivMod(n, radix, mod)
+		out = append(out, base58Alphabet[mod.Int64()])
+	}
+	for i := 0; i < len(b) && b[i] == 0; i++ {
+		out = append(out, '1')
+	}
+
+	// digits were produced least significant first
+	for i, j := 0, len(out)-1; i < j; i, j = i+1, j-1 {
+		out[i], out[j] = out[j], out[i]
+	}
+	return string(out)
+}
+
+// base58Decode decodes a Base58 string, or reports false if it has a character
+// outside of the alphabet.
+func base58Decode(s string) ([]byte, bool) {
+	var n = new(big.Int)
+	var radix = big.NewInt(58)
+	var zeros = 0
+	for zeros < len(s) && s[zeros] == '1' {
+		zeros++
+	}
+	for i := 0; i < len(s); i++ {
+		var d = strings.IndexByte(base58Alphabet, s[i])
+		if d < 0 {
+			return nil, false
+		}
+		n.Mul(n, radix)
+		n.Add(n, big.NewInt(int64(d)))
+	}
+
+	return append(make([]byte, zeros), n.Bytes()...), true
+}
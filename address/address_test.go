@@ -0,0 +1,239 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package address
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/zoobc/zed25519/zed"
+)
+
+// testKey is the public key of RFC 8032 section 7.1 test 1.
+var testKey = "d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"
+
+func testPublic() *zed.Public {
+	var key, _ = hex.DecodeString(testKey)
+	return zed.PublicFromKey(key)
+}
+
+// bech32Split splits a Bech32 string into its human-readable part and data
+// values, or returns ok = false.
+func bech32Split(s string) (hrp string, values []byte, ok bool) {
+	s = strings.ToLower(s)
+	var sep = strings.LastIndexByte(s, '1')
+	if sep < 0 {
+		return "", nil, false
+	}
+	for i := sep + 1; i < len(s); i++ {
+		var v = strings.IndexByte(bech32Charset, s[i])
+		if v < 0 {
+			return "", nil, false
+		}
+		values = append(values, byte(v))
+	}
+	return s[:sep], values, true
+}
+
+// bech32ChecksumTests are the valid Bech32 strings of BIP-173, and a string
+// whose checksum was computed over the upper-case form of its
+// human-readable part.
+var bech32ChecksumTests = []struct {
+	s     string
+	valid bool
+}{
+	{"A12UEL5L", true},
+	{"a12uel5l", true},
+	{"an83characterlonghumanreadablepartthatcontainsthenumber1andtheexcludedcharactersbio1tt5tgs", true},
+	{"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw", true},
+	{"split1checkupstagehandshakeupstreamerranterredcaperred2y9e3w", true},
+	{"?1ezyfcl", true},
+	{"A1G7SGD8", false},
+}
+
+func TestBech32Checksum(t *testing.T) {
+	for _, test := range bech32ChecksumTests {
+		var hrp, values, ok = bech32Split(test.s)
+		if !ok {
+			t.Fatalf("%s: cannot split", test.s)
+		}
+		if valid := bech32Polymod(hrp, values) == 1; valid != test.valid {
+			t.Errorf("%s: checksum valid = %v, want %v", test.s, valid, test.valid)
+		}
+		if test.valid {
+			var data = values[:len(values)-6]
+			var checksum = bech32Checksum(hrp, data)
+			if string(checksum) != string(values[len(data):]) {
+				t.Errorf("%s: bech32Checksum = %v, want %v", test.s, checksum, values[len(data):])
+			}
+		}
+	}
+}
+
+func TestBech32(t *testing.T) {
+	var pk = testPublic()
+
+	// computed with the BIP-173 reference implementation
+	var want = "zbc16adfsqvzky9t042tlmfujeq88g8wzuhnm2nzxfd0qgdx3ac82ydqrzzn3t"
+	var addr, err = EncodeBech32("zbc", pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if addr != want {
+		t.Errorf("EncodeBech32 = %s, want %s", addr, want)
+	}
+
+	for _, s := range []string{want, strings.ToUpper(want)} {
+		var got, err = DecodeBech32("zbc", s)
+		if err != nil {
+			t.Fatalf("DecodeBech32(%s) = %v", s, err)
+		}
+		if got.Key() != pk.Key() {
+			t.Errorf("DecodeBech32(%s) did not recover the key", s)
+		}
+	}
+}
+
+var decodeBech32ErrorTests = []string{
+	"zbc16adfsqvzky9t042tlmfujeq88g8wzuhnm2nzxfd0qgdx3ac82ydqrzzn3T",
+	"zbc16adfsqvzky9t042tlmfujeq88g8wzuhnm2nzxfd0qgdx3ac82ydqrzzn3q",
+	"zbc16adfsqvzky9t042tlmfujeq88g8wzuhnm2nzxfd0qgdx3ac82ydqrzzm3t",
+	"zbc16adfsqvzky9t042tlmfujeq88g8wzuhnm2nzxfd0qgdx3ac82ydqrzz",
+	"zbc1b6adfsqvzky9t042tlmfujeq88g8wzuhnm2nzxfd0qgdx3ac82ydqrzzn3t",
+	"abcdef1qpzry9x8gf2tvdw0s3jn54khce6mua7lmqqqxw",
+	"10a06t8",
+	"1qzzfhee",
+	"pzry9x0s0muk",
+	"li1dgmt3",
+}
+
+func TestDecodeBech32Errors(t *testing.T) {
+	for _, addr := range decodeBech32ErrorTests {
+		if _, err := DecodeBech32("zbc", addr); err == nil {
+			t.Errorf("DecodeBech32 accepted %s", addr)
+		}
+	}
+
+	// a single changed character is caught by the checksum
+	var _, err = DecodeBech32("zbc", "zbc16adfsqvzky9t042tlmfujeq88g8wzuhnm2nzxfd0qgdx3ac82ydqrzzm3t")
+	if err != ErrChecksum {
+		t.Errorf("DecodeBech32 of a mistyped address = %v, want ErrChecksum", err)
+	}
+	if _, err := DecodeBech32("zed", "zbc16adfsqvzky9t042tlmfujeq88g8wzuhnm2nzxfd0qgdx3ac82ydqrzzn3t"); err == nil {
+		t.Error("DecodeBech32 accepted another human-readable part")
+	}
+
+	for _, hrp := range []string{"", "ZBC", "z bc", strings.Repeat("z", 32)} {
+		if _, err := EncodeBech32(hrp, testPublic()); err == nil {
+			t.Errorf("EncodeBech32 accepted the human-readable part %q", hrp)
+		}
+	}
+}
+
+// base58Tests are the Base58 vectors of Bitcoin Core.
+var base58Tests = []struct{ hex, b58 string }{
+	{"", ""},
+	{"61", "2g"},
+	{"626262", "a3gV"},
+	{"636363", "aPEr"},
+	{"73696d706c792061206c6f6e6720737472696e67", "2cFupjhnEsSn59qHXstmK2ffpLv2"},
+	{"00eb15231dfceb60925886b67d065299925915aeb172c06647", "1NS17iag9jJgTHD1VXjvLCEnZuQ3rJDE9L"},
+	{"516b6fcd0f", "ABnLTmg"},
+	{"bf4f89001e670274dd", "3SEo3LWLoPntC"},
+	{"572e4794", "3EFU7m"},
+	{"ecac89cad93923c02321", "EJDM8drfXA6uyA"},
+	{"10c8511e", "Rt5zm"},
+	{"00000000000000000000", "1111111111"},
+}
+
+func TestBase58(t *testing.T) {
+	for _, test := range base58Tests {
+		var b, _ = hex.DecodeString(test.hex)
+		if got := base58Encode(b); got != test.b58 {
+			t.Errorf("base58Encode(%s) = %s, want %s", test.hex, got, test.b58)
+		}
+		var got, ok = base58Decode(test.b58)
+		if !ok || hex.EncodeToString(got) != test.hex {
+			t.Errorf("base58Decode(%s) = %x, want %s", test.b58, got, test.hex)
+		}
+	}
+	if _, ok := base58Decode("0OIl"); ok {
+		t.Error("base58Decode accepted characters outside of the alphabet")
+	}
+}
+
+func TestBase58Checksum(t *testing.T) {
+
+	// the Bitcoin genesis block address, 1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa
+	var payload, _ = hex.DecodeString("0062e907b15cbf27d5425399ebf6f0fb50ebb88f18")
+	var checksum = base58Checksum(payload)
+	if hex.EncodeToString(checksum[:]) != "c29b7d93" {
+		t.Errorf("base58Checksum = %x, want c29b7d93", checksum)
+	}
+	if got := base58Encode(append(payload, checksum[:]...)); got != "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa" {
+		t.Errorf("genesis address = %s", got)
+	}
+}
+
+var base58CheckTests = []struct {
+	version []byte
+	addr    string
+}{
+	{nil, "2dqvheyJXzEYpywfm8g7TshzLbaXWTwHKQPkh4rYX3Db2B3TPZ"},
+	{[]byte{1, 2}, "9g9uSXTDAJUfLUuuZKGUCCZWFmUrFBsrJDgAhLY91amGuRsFU8j"},
+}
+
+func TestBase58Check(t *testing.T) {
+	var pk = testPublic()
+	for _, test := range base58CheckTests {
+		if got := EncodeBase58Check(test.version, pk); got != test.addr {
+			t.Errorf("EncodeBase58Check(%x) = %s, want %s", test.version, got, test.addr)
+		}
+		var got, err = DecodeBase58Check(test.version, test.addr)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Key() != pk.Key() {
+			t.Errorf("DecodeBase58Check(%s) did not recover the key", test.addr)
+		}
+	}
+
+	var addr = base58CheckTests[1].addr
+	var mistyped = addr[:10] + "h" + addr[11:]
+	if _, err := DecodeBase58Check([]byte{1, 2}, mistyped); err != ErrChecksum {
+		t.Errorf("DecodeBase58Check of a mistyped address = %v, want ErrChecksum", err)
+	}
+	if _, err := DecodeBase58Check([]byte{1, 3}, addr); err == nil {
+		t.Error("DecodeBase58Check accepted another version")
+	}
+	if _, err := DecodeBase58Check(nil, addr); err == nil {
+		t.Error("DecodeBase58Check accepted a longer payload")
+	}
+	if _, err := DecodeBase58Check([]byte{0}, "1A1zP1eP5QGefi2DMPTfTL5SLmv7DivfNa"); err == nil {
+		t.Error("DecodeBase58Check accepted a 20-byte payload")
+	}
+}
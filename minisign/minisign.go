@@ -0,0 +1,486 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package minisign
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"errors"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/zoobc/zed25519/zed"
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/scrypt"
+)
+
+//
+//  minisign is a simple file signing tool built on Ed25519. This package
+//  reads and writes its key and signature files, so that files signed with
+//  zed keys can be verified with minisign, and the other way around.
+//
+//  A public key file holds a comment line and the base64 encoding of
+//
+//    "Ed" || key_id || As
+//
+//  where key_id is a random 8-byte identifier of the key pair. A signature
+//  file holds an untrusted comment, the base64 encoding of
+//
+//    "ED" || key_id || sign(blake2b_512(m))
+//
+//  a trusted comment, and a global signature sign(sig || trusted_comment),
+//  which authenticates the trusted comment together with the file. Legacy
+//  signatures with algorithm "Ed" sign the file m itself instead of its hash.
+//
+//  A secret key file holds the seed and public key, encrypted with a key
+//  derived from a password by scrypt, and a blake2b checksum to detect a
+//  wrong password:
+//
+//    "Ed" || "Sc" || "B2" || salt || opslimit || memlimit ||
+//      ((key_id || seed || As || chk) xor scrypt(password, salt))
+//
+//  A secret key file without a password has "\0\0" instead of "Sc", and its
+//  key material is not encrypted.
+//
+//  REFERENCES:
+//    [1] Frank Denis, "Minisign"
+//        https://jedisct1.github.io/minisign/
+//
+
+// Lengths of the binary structures of minisign files.
+const (
+	keyIDSize     = 8
+	publicKeySize = 2 + keyIDSize + 32
+	signatureSize = 2 + keyIDSize + 64
+	secretKeySize = 6 + 32 + 8 + 8 + keyIDSize + 64 + 32
+)
+
+// opsLimit and memLimit are the scrypt parameters written to encrypted secret
+// keys, which are the minisign defaults. In terms of scrypt, they give N =
+// 2^20, r = 8 and p = 1.
+const (
+	opsLimit = 33554432
+	memLimit = 1073741824
+)
+
+// ErrInvalidSignature is returned by Verify when the signature or the global
+// signature of a file is not valid.
+var ErrInvalidSignature = errors.New("minisign: invalid signature")
+
+// PublicKey is a zed public key with its minisign key ID.
+type PublicKey struct {
+	KeyID  [keyIDSize]byte
+	Public *zed.Public
+}
+
+// PrivateKey is a zed secret key with its minisign key ID.
+type PrivateKey struct {
+	KeyID  [keyIDSize]byte
+	Secret *zed.Secret
+}
+
+// Signature is a parsed minisign signature file.
+type Signature struct {
+	Algorithm        string
+	KeyID            [keyIDSize]byte
+	Signature        zed.Signature
+	UntrustedComment string
+	TrustedComment   string
+	GlobalSignature  zed.Signature
+}
+
+// NewPrivateKey wraps sk as a minisign key, with a new random key ID. Since
+// minisign key files hold the seed, sk must have been created with
+// zed.SecretFromSeed; otherwise zed.ErrNoSeed is returned.
+func NewPrivateKey(sk *zed.Secret) (*PrivateKey, error) {
+	if _, ok := sk.Seed(); !ok {
+		return nil, zed.ErrNoSeed
+	}
+
+	var key = &PrivateKey{Secret: sk}
+	if _, err := rand.Read(key.KeyID[:]); err != nil {
+		return nil, err
+	}
+
+	return key, nil
+}
+
+// Public returns the public key of the key pair, with the same key ID.
+func (key *PrivateKey) Public() *PublicKey {
+	return &PublicKey{KeyID: key.KeyID, Public: key.Secret.Public()}
+}
+
+// keyIDString formats a key ID the way minisign prints it, as the hex of its
+// little-endian value.
+func keyIDString(id [keyIDSize]byte) string {
+	return strings.ToUpper(strconv.FormatUint(binary.LittleEndian.Uint64(id[:]), 16))
+}
+
+// Marshal encodes the public key as the contents of a minisign public key
+// file.
+func (key *PublicKey) Marshal() []byte {
+	var As = key.Public.Key()
+
+	var bin = make([]byte, 0, publicKeySize)
+	bin = append(bin, "Ed"...)
+	bin = append(bin, key.KeyID[:]...)
+	bin = append(bin, As[:]...)
+
+	return encodeFile("minisign public key "+keyIDString(key.KeyID), bin)
+}
+
+// ParsePublicKey decodes a minisign public key, either from the contents of a
+// public key file, or from its base64 line alone (as minisign -P takes it).
+func ParsePublicKey(data []byte) (*PublicKey, error) {
+	var lines = fileLines(data)
+	if len(lines) == 1 {
+		lines = []string{"", lines[0]}
+	}
+	if len(lines) < 2 {
+		return nil, errors.New("ParsePublicKey: missing public key")
+	}
+
+	var bin, err = base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(bin) != publicKeySize {
+		return nil, errors.New("ParsePublicKey: invalid public key encoding")
+	}
+	if string(bin[:2]) != "Ed" {
+		return nil, errors.New("ParsePublicKey: unsupported algorithm")
+	}
+
+	var As zed.Buffer256
+	var A zed.Point
+	copy(As[:], bin[2+keyIDSize:])
+	if !zed.DecompressPoint(&A, &As) {
+		return nil, errors.New("ParsePublicKey: invalid public key")
+	}
+
+	var key = &PublicKey{Public: zed.PublicFromKey(As[:])}
+	copy(key.KeyID[:], bin[2:])
+
+	return key, nil
+}
+
+// Marshal encodes the secret key as the contents of a minisign secret key
+// file, encrypted with password as minisign does. If password is empty, the
+// key is stored unencrypted, as minisign -W does.
+func (key *PrivateKey) Marshal(password []byte) ([]byte, error) {
+	var std, ok = key.Secret.StdPrivateKey()
+	if !ok {
+		return nil, zed.ErrNoSeed
+	}
+	defer wipe(std)
+
+	var bin = make([]byte, 0, secretKeySize)
+	bin = append(bin, "Ed"...)
+	if len(password) > 0 {
+		bin = append(bin, "Sc"...)
+	} else {
+		bin = append(bin, 0, 0)
+	}
+	bin = append(bin, "B2"...)
+	var salt [32]byte
+	if _, err := rand.Read(salt[:]); err != nil {
+		return nil, err
+	}
+	bin = append(bin, salt[:]...)
+	var limits [16]byte
+	binary.LittleEndian.PutUint64(limits[:8], opsLimit)
+	binary.LittleEndian.PutUint64(limits[8:], memLimit)
+	bin = append(bin, limits[:]...)
+
+	// key_id || seed || As || chk
+	bin = append(bin, key.KeyID[:]...)
+	bin = append(bin, std...)
+	var chk = secretKeyChecksum(key.KeyID[:], std)
+	bin = append(bin, chk[:]...)
+	defer wipe(bin)
+
+	if len(password) > 0 {
+		if err := cryptSecretKey(bin, password); err != nil {
+			return nil, err
+		}
+	}
+
+	var comment = "minisign secret key"
+	if len(password) > 0 {
+		comment = "minisign encrypted secret key"
+	}
+
+	return encodeFile(comment, bin), nil
+}
+
+// ParsePrivateKey decodes the contents of a minisign secret key file,
+// decrypting it with password if it is encrypted.
+func ParsePrivateKey(data []byte, password []byte) (*PrivateKey, error) {
+	var lines = fileLines(data)
+	if len(lines) < 2 {
+		return nil, errors.New("ParsePrivateKey: missing secret key")
+	}
+
+	var bin, err = base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(bin) != secretKeySize {
+		return nil, errors.New("ParsePrivateKey: invalid secret key encoding")
+	}
+	defer wipe(bin)
+	if string(bin[:2]) != "Ed" || string(bin[4:6]) != "B2" {
+		return nil, errors.New("ParsePrivateKey: unsupported algorithm")
+	}
+
+	switch string(bin[2:4]) {
+	case "Sc":
+		if len(password) == 0 {
+			return nil, errors.New("ParsePrivateKey: key is encrypted")
+		}
+		if err = cryptSecretKey(bin, password); err != nil {
+			return nil, err
+		}
+	case "\x00\x00":
+	default:
+		return nil, errors.New("ParsePrivateKey: unsupported key derivation")
+	}
+
+	// key_id || seed || As || chk
+	var keyNum = bin[6+32+16:]
+	var std = keyNum[keyIDSize : keyIDSize+64]
+	var chk = secretKeyChecksum(keyNum[:keyIDSize], std)
+	if !bytes.Equal(chk[:], keyNum[keyIDSize+64:]) {
+		return nil, errors.New("ParsePrivateKey: wrong password or corrupt key")
+	}
+
+	var key = &PrivateKey{Secret: zed.SecretFromSeed(std[:32])}
+	copy(key.KeyID[:], keyNum)
+
+	return key, nil
+}
+
+// secretKeyChecksum computes chk = blake2b_256("Ed" || key_id || seed || As).
+func secretKeyChecksum(keyID, std []byte) [32]byte {
+	var hash, _ = blake2b.New256(nil)
+	var chk [32]byte
+	hash.Write([]byte("Ed"))
+	hash.Write(keyID)
+	hash.Write(std)
+	hash.Sum(chk[:0])
+	return chk
+}
+
+// cryptSecretKey encrypts or decrypts the key material of a secret key file in
+// place, by XORing it with the scrypt output for password and the file's
+// salt and limits.
+func cryptSecretKey(bin, password []byte) error {
+	var salt = bin[6:38]
+	var ops = binary.LittleEndian.Uint64(bin[38:46])
+	var mem = binary.LittleEndian.Uint64(bin[46:54])
+	var keyNum = bin[54:]
+
+	var logN, r, p = scryptParams(ops, mem)
+	if logN > 22 {
+		return errors.New("minisign: scrypt limits too high")
+	}
+	var stream, err = scrypt.Key(password, salt, 1<<logN, r, p, len(keyNum))
+	if err != nil {
+		return err
+	}
+	defer wipe(stream)
+
+	for i := range keyNum {
+		keyNum[i] ^= stream[i]
+	}
+	return nil
+}
+
+// scryptParams converts libsodium's opslimit and memlimit to the scrypt
+// parameters log2(N), r and p, the way libsodium does.
+func scryptParams(ops, mem uint64) (logN uint, r, p int) {
+	if ops < 32768 {
+		ops = 32768
+	}
+	r = 8
+	if ops < mem/32 {
+		p = 1
+		var maxN = ops / (uint64(r) * 4)
+		for logN = 1; logN < 63; logN++ {
+			if uint64(1)<<logN > maxN/2 {
+				break
+			}
+		}
+		return logN, r, p
+	}
+
+	var maxN = mem / (uint64(r) * 128)
+	for logN = 1; logN < 63; logN++ {
+		if uint64(1)<<logN > maxN/2 {
+			break
+		}
+	}
+	var maxRP = (ops / 4) / (uint64(1) << logN)
+	if maxRP > 0x3fffffff {
+		maxRP = 0x3fffffff
+	}
+	return logN, r, int(maxRP) / r
+}
+
+// Sign signs the contents of msg with the key, returning the contents of a
+// minisign signature file with the given comments. The trusted comment is
+// signed too; the untrusted comment defaults to minisign's if it is empty.
+func (key *PrivateKey) Sign(msg io.Reader, trustedComment, untrustedComment string) ([]byte, error) {
+	if untrustedComment == "" {
+		untrustedComment = "signature from minisign secret key"
+	}
+	if strings.ContainsAny(trustedComment+untrustedComment, "\r\n") {
+		return nil, errors.New("Sign: comments must be single lines")
+	}
+
+	// sig = sign(blake2b_512(m))
+	var hash, _ = blake2b.New512(nil)
+	if _, err := io.Copy(hash, msg); err != nil {
+		return nil, err
+	}
+	var sig = key.Secret.Sign(hash.Sum(nil))
+
+	// global_sig = sign(sig || trusted_comment)
+	var global = key.Secret.Sign(append(sig[:], trustedComment...))
+
+	var bin = make([]byte, 0, signatureSize)
+	bin = append(bin, "ED"...)
+	bin = append(bin, key.KeyID[:]...)
+	bin = append(bin, sig[:]...)
+
+	var file = encodeFile(untrustedComment, bin)
+	file = append(file, "trusted comment: "+trustedComment+"\n"...)
+	return append(file, base64.StdEncoding.EncodeToString(global[:])+"\n"...), nil
+}
+
+// ParseSignature decodes the contents of a minisign signature file. The
+// signatures are not checked until Verify is called.
+func ParseSignature(data []byte) (*Signature, error) {
+	var lines = fileLines(data)
+	if len(lines) < 4 {
+		return nil, errors.New("ParseSignature: truncated signature file")
+	}
+	if !strings.HasPrefix(lines[0], "untrusted comment: ") ||
+		!strings.HasPrefix(lines[2], "trusted comment: ") {
+		return nil, errors.New("ParseSignature: missing comment")
+	}
+
+	var bin, err = base64.StdEncoding.DecodeString(lines[1])
+	if err != nil || len(bin) != signatureSize {
+		return nil, errors.New("ParseSignature: invalid signature encoding")
+	}
+	global, err := base64.StdEncoding.DecodeString(lines[3])
+	if err != nil || len(global) != 64 {
+		return nil, errors.New("ParseSignature: invalid global signature encoding")
+	}
+
+	var sig = &Signature{
+		Algorithm:        string(bin[:2]),
+		UntrustedComment: strings.TrimPrefix(lines[0], "untrusted comment: "),
+		TrustedComment:   strings.TrimPrefix(lines[2], "trusted comment: "),
+	}
+	if sig.Algorithm != "ED" && sig.Algorithm != "Ed" {
+		return nil, errors.New("ParseSignature: unsupported algorithm")
+	}
+	copy(sig.KeyID[:], bin[2:])
+	copy(sig.Signature[:], bin[2+keyIDSize:])
+	copy(sig.GlobalSignature[:], global)
+
+	return sig, nil
+}
+
+// Verify checks a minisign signature by the key on the contents of msg, and
+// its global signature on the trusted comment. It returns nil if both are
+// valid, and ErrInvalidSignature if either is not, or if the signature was
+// made with another key.
+func (key *PublicKey) Verify(msg io.Reader, sig *Signature) error {
+	if sig.KeyID != key.KeyID {
+		return ErrInvalidSignature
+	}
+
+	// "ED" signs blake2b_512(m), legacy "Ed" signs m itself
+	var signed []byte
+	var err error
+	switch sig.Algorithm {
+	case "ED":
+		var hash, _ = blake2b.New512(nil)
+		if _, err = io.Copy(hash, msg); err != nil {
+			return err
+		}
+		signed = hash.Sum(nil)
+	case "Ed":
+		if signed, err = readAll(msg); err != nil {
+			return err
+		}
+	default:
+		return ErrInvalidSignature
+	}
+
+	if !key.Public.Verify(signed, sig.Signature[:]) {
+		return ErrInvalidSignature
+	}
+	if !key.Public.Verify(append(sig.Signature[:], sig.TrustedComment...), sig.GlobalSignature[:]) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// readAll reads msg to EOF.
+func readAll(msg io.Reader) ([]byte, error) {
+	var buf bytes.Buffer
+	var _, err = buf.ReadFrom(msg)
+	return buf.Bytes(), err
+}
+
+// encodeFile builds a minisign file from its untrusted comment and binary
+// contents.
+func encodeFile(untrustedComment string, bin []byte) []byte {
+	return []byte("untrusted comment: " + untrustedComment + "\n" +
+		base64.StdEncoding.EncodeToString(bin) + "\n")
+}
+
+// fileLines splits a minisign file into its non-empty lines.
+func fileLines(data []byte) []string {
+	var lines []string
+	var scanner = bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		if line := strings.TrimRight(scanner.Text(), "\r"); line != "" {
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// wipe zeroes a buffer which held secret data.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
@@ -0,0 +1,214 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package minisign
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"github.com/zoobc/zed25519/zed"
+)
+
+// A public key and a legacy ("Ed") signature of "Hello World!\n" made with
+// the minisign tool, from the test data of aead.dev/minisign.
+var (
+	testPublicKey = "untrusted comment: minisign public key C373193807678450\n" +
+		"RWRQhGcHOBlzw4CoKyugkk4ioDfoxlXxC9LBx+VNhJ3w9w+cAxgvPsuo\n"
+	testMessage   = "Hello World!\n"
+	testSignature = "untrusted comment: signature from minisign secret key\n" +
+		"RWRQhGcHOBlzwxrJCyuC+rJfHSfyRKRxkuwa3JJ0bWEs7RHjL1OUmqnTr+V1B9JzFuJIH/ybR2Eus9oEZKt9RbitpF/L4D3+5wg=\n" +
+		"trusted comment: timestamp:1614549543\tfile:message.txt\n" +
+		"P/722+ynQ+tIy0qadFHwLx5MsyNz/jDKJkDWQj4dDD2OKnVte8m/M14mwPE/1NMwzShPMSBhMXqZGdbe+UZjDg==\n"
+)
+
+// Legacy ("Ed") and prehashed ("ED") signatures of "test", from the tests of
+// the minisign author's Go package, github.com/jedisct1/go-minisign.
+var (
+	testAuthorKey       = "RWQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"
+	testLegacySignature = "untrusted comment: signature from minisign secret key\n" +
+		"RWQf6LRCGA9i59SLOFxz6NxvASXDJeRtuZykwQepbDEGt87ig1BNpWaVWuNrm73YiIiJbq71Wi+dP9eKL8OC351vwIasSSbXxwA=\n" +
+		"trusted comment: timestamp:1635442742\tfile:test\n" +
+		"0YteLgV960ia80vnA/fHbvkyjl/IoP/HNOCaZfrF0CdhAlp7ok+Tpkya+VpWPX5C/Is3q8a/kEDSY7fBmmgJCg==\n"
+	testPrehashedSignature = "untrusted comment: signature from minisign secret key\n" +
+		"RUQf6LRCGA9i559r3g7V1qNyJDApGip8MfqcadIgT9CuhV3EMhHoN1mGTkUidF/z7SrlQgXdy8ofjb7bNJJylDOocrCo8KLzZwo=\n" +
+		"trusted comment: timestamp:1635443258\tfile:test\thashed\n" +
+		"/cj37GK60vryibFn+ftOgbCvW9NKhKYgjVpFFQUcWPAnjO23wrvVDTt7cloNC06maoBli9q6qwZDXXoaxweICQ==\n"
+)
+
+// An encrypted secret key with the password "correct horse battery staple"
+// and key ID A345BDA18A33D06, from the examples of aead.dev/minisign. Its
+// scrypt limits are lower than minisign's defaults, so it decrypts quickly.
+var testSecretKey = "untrusted comment: minisign encrypted secret key\n" +
+	"RWRTY0IyorAWr/1gdweGki6ua7GpmoPqS+7rMBSmBy6hedA53dAAABAAAAAAAAAAAAIAAAAAwfmyB6qIIW2eGNiQaFzgs1oi52iN8cRHBPRupc9TVdfAeJvlPdvzu3TfA2DHTW2PZi98uihcr5sEB5fefFml2d0xBk72ZOGNJpOTsn95eHgEH/qUfzQZ018JfiVwWf8pNpdgNFX8ROs=\n"
+
+func TestVerifyReference(t *testing.T) {
+	var key, err = ParsePublicKey([]byte(testPublicKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := keyIDString(key.KeyID); id != "C373193807678450" {
+		t.Errorf("key ID %s, want C373193807678450", id)
+	}
+	if string(key.Marshal()) != testPublicKey {
+		t.Errorf("Marshal = %q, want %q", key.Marshal(), testPublicKey)
+	}
+
+	sig, err := ParseSignature([]byte(testSignature))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig.Algorithm != "Ed" || sig.TrustedComment != "timestamp:1614549543\tfile:message.txt" {
+		t.Errorf("ParseSignature = %+v", sig)
+	}
+	if err := key.Verify(strings.NewReader(testMessage), sig); err != nil {
+		t.Errorf("Verify = %v", err)
+	}
+	if err := key.Verify(strings.NewReader("Hello World?\n"), sig); err != ErrInvalidSignature {
+		t.Errorf("Verify of another message = %v, want ErrInvalidSignature", err)
+	}
+
+	// the trusted comment is covered by the global signature
+	var changed = *sig
+	changed.TrustedComment = "timestamp:1614549543\tfile:other.txt"
+	if err := key.Verify(strings.NewReader(testMessage), &changed); err != ErrInvalidSignature {
+		t.Errorf("Verify with a changed trusted comment = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestVerifyLegacy(t *testing.T) {
+	var key, err = ParsePublicKey([]byte(testAuthorKey))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, data := range []string{testLegacySignature, testPrehashedSignature} {
+		var sig, err = ParseSignature([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := key.Verify(strings.NewReader("test"), sig); err != nil {
+			t.Errorf("%s: Verify = %v", sig.Algorithm, err)
+		}
+		if err := key.Verify(strings.NewReader("tesT"), sig); err != ErrInvalidSignature {
+			t.Errorf("%s: Verify of another message = %v, want ErrInvalidSignature", sig.Algorithm, err)
+		}
+	}
+
+	// a signature by another key is rejected by its key ID
+	var other, _ = ParsePublicKey([]byte(testPublicKey))
+	var sig, _ = ParseSignature([]byte(testPrehashedSignature))
+	if err := other.Verify(strings.NewReader("test"), sig); err != ErrInvalidSignature {
+		t.Errorf("Verify under another key = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestParsePrivateKey(t *testing.T) {
+	var key, err = ParsePrivateKey([]byte(testSecretKey), []byte("correct horse battery staple"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if id := keyIDString(key.KeyID); id != "A345BDA18A33D06" {
+		t.Errorf("key ID %s, want A345BDA18A33D06", id)
+	}
+
+	if _, err := ParsePrivateKey([]byte(testSecretKey), []byte("wrong horse battery staple")); err == nil {
+		t.Error("ParsePrivateKey accepted the wrong password")
+	}
+	if _, err := ParsePrivateKey([]byte(testSecretKey), nil); err == nil {
+		t.Error("ParsePrivateKey decrypted a key without a password")
+	}
+	if _, err := ParsePrivateKey([]byte(testPublicKey), nil); err == nil {
+		t.Error("ParsePrivateKey accepted a public key")
+	}
+}
+
+func TestSignVerify(t *testing.T) {
+	var key, err = NewPrivateKey(zed.SecretFromSeed(bytes.Repeat([]byte{2}, 32)))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the unencrypted secret key file round-trips
+	data, err := key.Marshal(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := ParsePrivateKey(data, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.KeyID != key.KeyID || !parsed.Secret.Equal(key.Secret) {
+		t.Error("secret key does not round-trip")
+	}
+
+	pub, err := ParsePublicKey(key.Public().Marshal())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	file, err := key.Sign(strings.NewReader(testMessage), "file:message.txt", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := ParseSignature(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig.Algorithm != "ED" || sig.UntrustedComment != "signature from minisign secret key" || sig.TrustedComment != "file:message.txt" {
+		t.Errorf("ParseSignature = %+v", sig)
+	}
+	if err := pub.Verify(strings.NewReader(testMessage), sig); err != nil {
+		t.Errorf("Verify = %v", err)
+	}
+
+	if _, err := key.Sign(strings.NewReader(testMessage), "two\nlines", ""); err == nil {
+		t.Error("Sign accepted a trusted comment of two lines")
+	}
+	var derived = zed.SecretFromSeed(bytes.Repeat([]byte{2}, 32)).Derive([]byte{1}, nil)
+	if _, err := NewPrivateKey(derived); err != zed.ErrNoSeed {
+		t.Errorf("NewPrivateKey of a derived key = %v, want zed.ErrNoSeed", err)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	var lines = strings.Split(testSignature, "\n")
+	for _, data := range []string{
+		strings.Join(lines[:3], "\n"),
+		strings.Join([]string{"comment", lines[1], lines[2], lines[3]}, "\n"),
+		strings.Join([]string{lines[0], lines[1][:40], lines[2], lines[3]}, "\n"),
+		strings.Join([]string{lines[0], lines[1], lines[2], "!!"}, "\n"),
+	} {
+		if _, err := ParseSignature([]byte(data)); err == nil {
+			t.Errorf("ParseSignature accepted %q", data)
+		}
+	}
+
+	for _, data := range []string{"", "RWRQhGcHOBlzw4CoKyugkk4ioDfo", "RUQf6LRCGA9i53mlYecO4IzT51TGPpvWucNSCh1CBM0QTaLn73Y7GFO3"} {
+		if _, err := ParsePublicKey([]byte(data)); err == nil {
+			t.Errorf("ParsePublicKey accepted %q", data)
+		}
+	}
+}
@@ -0,0 +1,232 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package mnemonic
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/zoobc/zed25519/zed"
+	"golang.org/x/crypto/pbkdf2"
+)
+
+//
+//  BIP-39 encodes a random seed as a "mnemonic" phrase of 12 to 24 words, so
+//  that it can be written down and typed back in by a human:
+//
+//    - ENT bits of entropy (128 to 256, a multiple of 32) are extended with a
+//      checksum of CS = ENT / 32 bits, the first bits of sha256(entropy).
+//
+//    - The ENT + CS bits are split into groups of 11 bits, and each group
+//      selects one word from a list of 2048 words.
+//
+//    - The phrase is turned into a 64-byte seed by key stretching, with an
+//      optional passphrase:
+//
+//        seed = pbkdf2_hmac_sha512(phrase, "mnemonic" || passphrase, 2048)
+//
+//  The seed can then be used with zed.SecretFromSeed, or, for keys which are
+//  compatible with hardware wallets, with zed.DeriveSLIP10.
+//
+//  BIP-39 requires the phrase and passphrase to be normalized to Unicode NFKD
+//  before stretching. The wordlists here are already in NFKD, and phrases
+//  produced by this package are too, but phrases and passphrases typed in by
+//  a user which contain accented or non-Latin characters must be normalized
+//  by the caller (e.g. with golang.org/x/text/unicode/norm) before use.
+//
+//  REFERENCES:
+//    [1] Marek Palatinus, Pavol Rusnak, Aaron Voisine, Sean Bowe
+//        "BIP-39: Mnemonic code for generating deterministic keys"
+//        https://github.com/bitcoin/bips/blob/master/bip-0039.mediawiki
+//
+
+// Wordlist is one of the BIP-39 wordlists of 2048 words, such as English.
+type Wordlist struct {
+	separator string
+	words     []string
+	index     map[string]int
+	once      sync.Once
+}
+
+// newWordlist builds a Wordlist from its newline-separated words, and the
+// string used to separate words in a phrase.
+func newWordlist(separator, words string) *Wordlist {
+	return &Wordlist{separator: separator, words: strings.Fields(words)}
+}
+
+// Word gets the word with index i, between 0 and 2047.
+func (wl *Wordlist) Word(i int) string {
+	return wl.words[i]
+}
+
+// Index gets the index of a word in the list, and whether it is in the list.
+func (wl *Wordlist) Index(word string) (int, bool) {
+	wl.once.Do(func() {
+		wl.index = make(map[string]int, len(wl.words))
+		for i, w := range wl.words {
+			wl.index[w] = i
+		}
+	})
+	var i, ok = wl.index[word]
+	return i, ok
+}
+
+// Generate creates a new random mnemonic phrase from bits bits of entropy,
+// which must be 128, 160, 192, 224 or 256, giving 12 to 24 words from wl.
+func Generate(bits int, wl *Wordlist) (string, error) {
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return "", errors.New("Generate: bad entropy size: " + strconv.Itoa(bits))
+	}
+
+	var entropy = make([]byte, bits/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", err
+	}
+
+	return FromEntropy(entropy, wl)
+}
+
+// FromEntropy encodes entropy, which must be 16, 20, 24, 28 or 32 bytes long,
+// as a mnemonic phrase of words from wl.
+func FromEntropy(entropy []byte, wl *Wordlist) (string, error) {
+	var ent = len(entropy) * 8
+	if ent < 128 || ent > 256 || ent%32 != 0 {
+		return "", errors.New("FromEntropy: bad entropy length: " + strconv.Itoa(len(entropy)))
+	}
+
+	// bits = entropy || sha256(entropy)[:CS bits]
+	var checksum = sha256.Sum256(entropy)
+	var bits = append(append([]byte{}, entropy...), checksum[0])
+
+	// each group of 11 bits selects one word
+	var n = (ent + ent/32) / 11
+	var words = make([]string, n)
+	for i := range words {
+		var idx = 0
+		for j := 0; j < 11; j++ {
+			var b = i*11 + j
+			idx = idx<<1 | int(bits[b/8]>>(7-uint(b%8))&1)
+		}
+		words[i] = wl.words[idx]
+	}
+
+	return strings.Join(words, wl.separator), nil
+}
+
+// ToEntropy decodes a mnemonic phrase of words from wl back into its entropy,
+// checking the number of words, that every word is in the list, and the
+// checksum. Words may be separated by any whitespace.
+func ToEntropy(phrase string, wl *Wordlist) ([]byte, error) {
+	var words = strings.Fields(phrase)
+	var n = len(words)
+	if n < 12 || n > 24 || n%3 != 0 {
+		return nil, errors.New("ToEntropy: bad number of words: " + strconv.Itoa(n))
+	}
+
+	// bits = the 11-bit index of each word
+	var bits = make([]byte, (n*11+7)/8)
+	for i, word := range words {
+		var idx, ok = wl.Index(word)
+		if !ok {
+			return nil, errors.New("ToEntropy: unknown word: " + word)
+		}
+		for j := 0; j < 11; j++ {
+			if idx>>(10-uint(j))&1 == 1 {
+				var b = i*11 + j
+				bits[b/8] |= 1 << (7 - uint(b%8))
+			}
+		}
+	}
+
+	// (entropy || checksum) = bits, check checksum
+	var ent = n * 11 * 32 / 33
+	var entropy = bits[:ent/8]
+	var checksum = sha256.Sum256(entropy)
+	var cs = uint(ent / 32)
+	if bits[ent/8]>>(8-cs) != checksum[0]>>(8-cs) {
+		return nil, errors.New("ToEntropy: bad checksum")
+	}
+
+	return entropy, nil
+}
+
+// Validate checks that phrase is a valid mnemonic phrase of words from wl.
+func Validate(phrase string, wl *Wordlist) error {
+	var _, err = ToEntropy(phrase, wl)
+	return err
+}
+
+// FingerprintWords renders the fingerprint of a public key as n words from
+// wl, each selected by the next 11 bits of the fingerprint, so that keys can
+// be compared by reading words aloud. n must be between 1 and 23; 6 words
+// (66 bits) are enough to compare keys in person. The words are not a BIP-39
+// phrase, and have no checksum.
+func FingerprintWords(fp zed.Fingerprint, n int, wl *Wordlist) (string, error) {
+	if n < 1 || n > len(fp)*8/11 {
+		return "", errors.New("FingerprintWords: bad number of words: " + strconv.Itoa(n))
+	}
+
+	// each group of 11 bits selects one word
+	var words = make([]string, n)
+	for i := range words {
+		var idx = 0
+		for j := 0; j < 11; j++ {
+			var b = i*11 + j
+			idx = idx<<1 | int(fp[b/8]>>(7-uint(b%8))&1)
+		}
+		words[i] = wl.words[idx]
+	}
+
+	return strings.Join(words, wl.separator), nil
+}
+
+// Seed computes the 64-byte BIP-39 seed of a mnemonic phrase with an optional
+// passphrase (which may be empty). As BIP-39 specifies, the phrase is not
+// checked against any wordlist; use Validate or NewSecret for that. Both must
+// already be normalized to NFKD.
+func Seed(phrase, passphrase string) []byte {
+
+	// words are separated by single spaces in the normalized phrase
+	var normalized = strings.Join(strings.Fields(phrase), " ")
+
+	// seed = pbkdf2_hmac_sha512(phrase, "mnemonic" || passphrase, 2048)
+	return pbkdf2.Key([]byte(normalized), []byte("mnemonic"+passphrase), 2048, 64, sha512.New)
+}
+
+// NewSecret validates a mnemonic phrase of words from wl, and creates the
+// Secret for its seed with zed.SecretFromSeed, which uses the first 32 bytes
+// of the 64-byte seed.
+func NewSecret(phrase, passphrase string, wl *Wordlist) (*zed.Secret, error) {
+	if err := Validate(phrase, wl); err != nil {
+		return nil, err
+	}
+	return zed.SecretFromSeed(Seed(phrase, passphrase)), nil
+}
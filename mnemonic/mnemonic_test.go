@@ -0,0 +1,221 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package mnemonic
+
+import (
+	"bytes"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/zoobc/zed25519/zed"
+)
+
+// Test vectors of BIP-39 for the English wordlist, with the passphrase
+// "TREZOR".
+var bip39Tests = []struct {
+	entropy, phrase, seed string
+}{
+	{
+		"00000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+		"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+	},
+	{
+		"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		"legal winner thank year wave sausage worth useful legal winner thank yellow",
+		"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+	},
+	{
+		"80808080808080808080808080808080",
+		"letter advice cage absurd amount doctor acoustic avoid letter advice cage above",
+		"d71de856f81a8acc65e6fc851a38d4d7ec216fd0796d0a6827a3ad6ed5511a30fa280f12eb2e47ed2ac03b5c462a0358d18d69fe4f985ec81778c1b370b652a8",
+	},
+	{
+		"ffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo wrong",
+		"ac27495480225222079d7be181583751e86f571027b0497b5b5d11218e0a8a13332572917f0f8e5a589620c6f15b11c61dee327651a14c34e18231052e48c069",
+	},
+	{
+		"000000000000000000000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon agent",
+		"035895f2f481b1b0f01fcf8c289c794660b289981a78f8106447707fdd9666ca06da5a9a565181599b79f53b844d8a71dd9f439c52a3d7b3e8a79c906ac845fa",
+	},
+	{
+		"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		"legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal will",
+		"f2b94508732bcbacbcc020faefecfc89feafa6649a5491b8c952cede496c214a0c7b3c392d168748f2d4a612bada0753b52a1c7ac53c1e93abd5c6320b9e95dd",
+	},
+	{
+		"808080808080808080808080808080808080808080808080",
+		"letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic avoid letter always",
+		"107d7c02a5aa6f38c58083ff74f04c607c2d2c0ecc55501dadd72d025b751bc27fe913ffb796f841c49b1d33b610cf0e91d3aa239027f5e99fe4ce9e5088cd65",
+	},
+	{
+		"ffffffffffffffffffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo when",
+		"0cd6e5d827bb62eb8fc1e262254223817fd068a74b5b449cc2f667c3f1f985a76379b43348d952e2265b4cd129090758b3e3c2c49103b5051aac2eaeb890a528",
+	},
+	{
+		"0000000000000000000000000000000000000000000000000000000000000000",
+		"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon art",
+		"bda85446c68413707090a52022edd26a1c9462295029f2e60cd7c4f2bbd3097170af7a4d73245cafa9c3cca8d561a7c3de6f5d4a10be8ed2a5e608d68f92fcc8",
+	},
+	{
+		"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+		"legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth useful legal winner thank year wave sausage worth title",
+		"bc09fca1804f7e69da93c2f2028eb238c227f2e9dda30cd63699232578480a4021b146ad717fbb7e451ce9eb835f43620bf5c514db0f8add49f5d121449d3e87",
+	},
+	{
+		"8080808080808080808080808080808080808080808080808080808080808080",
+		"letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic avoid letter advice cage absurd amount doctor acoustic bless",
+		"c0c519bd0e91a2ed54357d9d1ebef6f5af218a153624cf4f2da911a0ed8f7a09e2ef61af0aca007096df430022f7a2b6fb91661a9589097069720d015e4e982f",
+	},
+	{
+		"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote",
+		"dd48c104698c30cfe2b6142103248622fb7bb0ff692eebb00089b32d22484e1613912f0a5b694407be899ffd31ed3992c456cdf60f5d4564b8ba3f05a69890ad",
+	},
+	{
+		"9e885d952ad362caeb4efe34a8e91bd2",
+		"ozone drill grab fiber curtain grace pudding thank cruise elder eight picnic",
+		"274ddc525802f7c828d8ef7ddbcdc5304e87ac3535913611fbbfa986d0c9e5476c91689f9c8a54fd55bd38606aa6a8595ad213d4c9c9f9aca3fb217069a41028",
+	},
+	{
+		"6610b25967cdcca9d59875f5cb50b0ea75433311869e930b",
+		"gravity machine north sort system female filter attitude volume fold club stay feature office ecology stable narrow fog",
+		"628c3827a8823298ee685db84f55caa34b5cc195a778e52d45f59bcf75aba68e4d7590e101dc414bc1bbd5737666fbbef35d1f1903953b66624f910feef245ac",
+	},
+	{
+		"68a79eaca2324873eacc50cb9c6eca8cc68ea5d936f98787c60c7ebc74e6ce7c",
+		"hamster diagram private dutch cause delay private meat slide toddler razor book happy fancy gospel tennis maple dilemma loan word shrug inflict delay length",
+		"64c87cde7e12ecf6704ab95bb1408bef047c22db4cc7491c4271d170a1b213d20b385bc1588d9c7b38f1b39d415665b8a9030c9ec653d75e65f847d8fc1fc440",
+	},
+}
+
+func TestBIP39(t *testing.T) {
+	for _, test := range bip39Tests {
+		var entropy, _ = hex.DecodeString(test.entropy)
+		var phrase, err = FromEntropy(entropy, English)
+		if err != nil || phrase != test.phrase {
+			t.Errorf("FromEntropy(%s) = %q, %v", test.entropy, phrase, err)
+		}
+
+		decoded, err := ToEntropy(test.phrase, English)
+		if err != nil || !bytes.Equal(decoded, entropy) {
+			t.Errorf("ToEntropy(%q) = %x, %v", test.phrase, decoded, err)
+		}
+
+		var seed = Seed(test.phrase, "TREZOR")
+		if got := hex.EncodeToString(seed); got != test.seed {
+			t.Errorf("Seed(%q) = %s, want %s", test.phrase, got, test.seed)
+		}
+
+		sk, err := NewSecret(test.phrase, "TREZOR", English)
+		if err != nil || !sk.Equal(zed.SecretFromSeed(seed)) {
+			t.Errorf("NewSecret(%q) = %v", test.phrase, err)
+		}
+	}
+}
+
+var toEntropyErrorTests = []struct {
+	name, phrase string
+}{
+	{"bad checksum", strings.Repeat("abandon ", 12)},
+	{"bad checksum, 24 words", strings.Repeat("zoo ", 24)},
+	{"unknown word", strings.Repeat("abandon ", 11) + "abut"},
+	{"11 words", strings.Repeat("abandon ", 10) + "about"},
+	{"13 words", strings.Repeat("abandon ", 12) + "about"},
+	{"27 words", strings.Repeat("abandon ", 26) + "about"},
+	{"empty", ""},
+}
+
+func TestToEntropyErrors(t *testing.T) {
+	for _, test := range toEntropyErrorTests {
+		if _, err := ToEntropy(test.phrase, English); err == nil {
+			t.Errorf("%s: ToEntropy accepted %q", test.name, test.phrase)
+		}
+		if _, err := NewSecret(test.phrase, "", English); err == nil {
+			t.Errorf("%s: NewSecret accepted %q", test.name, test.phrase)
+		}
+	}
+}
+
+func TestSeedWhitespace(t *testing.T) {
+	var test = bip39Tests[0]
+	var messy = " " + strings.Replace(test.phrase, " ", " \t\n  ", -1) + "\n"
+	if got := hex.EncodeToString(Seed(messy, "TREZOR")); got != test.seed {
+		t.Errorf("Seed with extra whitespace = %s", got)
+	}
+	if err := Validate(messy, English); err != nil {
+		t.Errorf("Validate with extra whitespace = %v", err)
+	}
+}
+
+func TestSeedNFKD(t *testing.T) {
+	var phrase = bip39Tests[0].phrase
+
+	// "café" normalized to NFKD by the caller gives the BIP-39 seed
+	var nfkd = hex.EncodeToString(Seed(phrase, "cafe\u0301"))
+	if nfkd != "af8bbd2566df7b69d926f2b09dfdbd75db6c994a3399b2cc65f928d63e3fd4e61218ee0d15f8c810be4d45e66d47b43c15a5cc753976b1666912377ff7ae9818" {
+		t.Errorf("Seed with an NFKD passphrase = %s", nfkd)
+	}
+
+	// the same passphrase left precomposed is not normalized by Seed
+	var raw = hex.EncodeToString(Seed(phrase, "caf\u00e9"))
+	if raw != "3f71749629d237c8505c83d334e9a05d9430650ee41a5da768cb4bfe1ebd0882d4ee4fe800aed9a22d8769a9bd404eedec49dcdb80b4e07e624312de775e995e" {
+		t.Errorf("Seed with a precomposed passphrase = %s", raw)
+	}
+}
+
+func TestFromEntropyErrors(t *testing.T) {
+	for _, n := range []int{0, 12, 15, 17, 33, 64} {
+		if _, err := FromEntropy(make([]byte, n), English); err == nil {
+			t.Errorf("FromEntropy accepted %d bytes", n)
+		}
+	}
+	for _, bits := range []int{0, 96, 129, 288} {
+		if _, err := Generate(bits, English); err == nil {
+			t.Errorf("Generate accepted %d bits", bits)
+		}
+	}
+}
+
+func TestGenerate(t *testing.T) {
+	for _, bits := range []int{128, 160, 192, 224, 256} {
+		var phrase, err = Generate(bits, English)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if n := len(strings.Fields(phrase)); n != bits/32*3 {
+			t.Errorf("%d bits: %d words", bits, n)
+		}
+		entropy, err := ToEntropy(phrase, English)
+		if err != nil || len(entropy) != bits/8 {
+			t.Errorf("%d bits: ToEntropy = %d bytes, %v", bits, len(entropy), err)
+		}
+	}
+}
@@ -0,0 +1,15 @@
+module github.com/zoobc/zed25519/pkcs11
+
+go 1.18
+
+require (
+	github.com/miekg/pkcs11 v1.1.1
+	github.com/zoobc/zed25519 v0.0.0-00010101000000-000000000000
+)
+
+require (
+	golang.org/x/crypto v0.0.0-20200820211705-5c72a883971a // indirect
+	golang.org/x/sys v0.0.0-20190412213103-97732733099d // indirect
+)
+
+replace github.com/zoobc/zed25519 => ../
@@ -0,0 +1,285 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package pkcs11
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"sync"
+
+	p11 "github.com/miekg/pkcs11"
+	"github.com/zoobc/zed25519/zed"
+)
+
+//
+//  PKCS#11 is the standard C interface to hardware security modules (HSMs)
+//  and cryptographic tokens. Version 3.0 of the standard added Ed25519 keys
+//  (key type CKK_EC_EDWARDS) and signatures (mechanism CKM_EDDSA), which this
+//  package uses to implement a zed.RemoteSigner: the private key is generated
+//  or imported on the token, and never leaves it. Only the Public Key is read
+//  from the token, and verification and public key derivation are done with
+//  it in zed as usual.
+//
+//  A Signer is opened by naming the PKCS#11 module (the shared library of
+//  the token vendor), the label of the token, its user PIN, and the label of
+//  the key pair on the token:
+//
+//    signer, err := pkcs11.Open("/usr/lib/softhsm/libsofthsm2.so",
+//        "my-token", "1234", "my-key")
+//    defer signer.Close()
+//    sig, err := zed.RemoteSign(signer, msg)
+//
+//  Tokens only make plain Ed25519 signatures, so Signer.VrfEval returns
+//  zed.ErrRemoteUnsupported. The signatures are checked against the Public
+//  Key before they are returned.
+//
+//  Loading a module needs cgo, so this package is a module of its own,
+//  github.com/zoobc/zed25519/pkcs11, and the zed25519 module itself does
+//  not depend on github.com/miekg/pkcs11 or on cgo.
+//
+//  REFERENCES:
+//    [1] OASIS, "PKCS #11 Cryptographic Token Interface Current Mechanisms
+//        Specification Version 3.0", section 2.3.14 (EdDSA)
+//        https://docs.oasis-open.org/pkcs11/pkcs11-curr/v3.0/pkcs11-curr-v3.0.html
+//
+
+// PKCS#11 3.0 values, which predate the headers of the pkcs11 package.
+const (
+	ckkEcEdwards = 0x00000040 // CKK_EC_EDWARDS
+	ckmEddsa     = 0x00001057 // CKM_EDDSA
+)
+
+// Signer signs with an Ed25519 key pair held by a PKCS#11 token. A Signer
+// may be used from several goroutines, but signs one message at a time,
+// since a PKCS#11 session can only perform one operation at a time.
+type Signer struct {
+	mu      sync.Mutex
+	ctx     *p11.Ctx
+	session p11.SessionHandle
+	key     p11.ObjectHandle
+	pk      *zed.Public
+}
+
+// Open loads the PKCS#11 module, opens a session with the token labelled
+// tokenLabel, logs in with pin, and finds the Ed25519 key pair labelled
+// keyLabel. The Signer must be closed with Close when it is no longer needed.
+func Open(module, tokenLabel, pin, keyLabel string) (*Signer, error) {
+	var ctx = p11.New(module)
+	if ctx == nil {
+		return nil, errors.New("Open: cannot load module " + module)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, fmt.Errorf("Open: %w", err)
+	}
+
+	var s = &Signer{ctx: ctx}
+	if err := s.open(tokenLabel, pin, keyLabel); err != nil {
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, fmt.Errorf("Open: %w", err)
+	}
+	return s, nil
+}
+
+// open opens and logs in to the session, and finds the key pair.
+func (s *Signer) open(tokenLabel, pin, keyLabel string) error {
+	var slot, err = s.findSlot(tokenLabel)
+	if err != nil {
+		return err
+	}
+
+	s.session, err = s.ctx.OpenSession(slot, p11.CKF_SERIAL_SESSION)
+	if err != nil {
+		return err
+	}
+	if err = s.ctx.Login(s.session, p11.CKU_USER, pin); err != nil {
+		s.ctx.CloseSession(s.session)
+		return err
+	}
+
+	if err = s.findKeys(keyLabel); err != nil {
+		s.ctx.Logout(s.session)
+		s.ctx.CloseSession(s.session)
+		return err
+	}
+	return nil
+}
+
+// findSlot returns the slot of the token labelled tokenLabel.
+func (s *Signer) findSlot(tokenLabel string) (uint, error) {
+	var slots, err = s.ctx.GetSlotList(true)
+	if err != nil {
+		return 0, err
+	}
+	for _, slot := range slots {
+		var info, err = s.ctx.GetTokenInfo(slot)
+		if err != nil {
+			return 0, err
+		}
+		// labels are padded with spaces to 32 bytes
+		if strings.TrimRight(info.Label, " \x00") == tokenLabel {
+			return slot, nil
+		}
+	}
+	return 0, errors.New("token not found: " + tokenLabel)
+}
+
+// findKeys finds the private key and reads the public key of the key pair
+// labelled keyLabel.
+func (s *Signer) findKeys(keyLabel string) error {
+	var key, err = s.findObject(p11.CKO_PRIVATE_KEY, keyLabel)
+	if err != nil {
+		return err
+	}
+	pub, err := s.findObject(p11.CKO_PUBLIC_KEY, keyLabel)
+	if err != nil {
+		return err
+	}
+
+	attrs, err := s.ctx.GetAttributeValue(s.session, pub, []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return err
+	}
+	if len(attrs) != 1 {
+		return errors.New("public key has no CKA_EC_POINT")
+	}
+	pk, err := parseEcPoint(attrs[0].Value)
+	if err != nil {
+		return err
+	}
+
+	s.key = key
+	s.pk = pk
+	return nil
+}
+
+// findObject returns the single Ed25519 key object of class class labelled
+// label.
+func (s *Signer) findObject(class uint, label string) (p11.ObjectHandle, error) {
+	var template = []*p11.Attribute{
+		p11.NewAttribute(p11.CKA_CLASS, class),
+		p11.NewAttribute(p11.CKA_KEY_TYPE, ckkEcEdwards),
+		p11.NewAttribute(p11.CKA_LABEL, label),
+	}
+	if err := s.ctx.FindObjectsInit(s.session, template); err != nil {
+		return 0, err
+	}
+	var objs, _, err = s.ctx.FindObjects(s.session, 2)
+	if ferr := s.ctx.FindObjectsFinal(s.session); err == nil {
+		err = ferr
+	}
+	if err != nil {
+		return 0, err
+	}
+
+	switch len(objs) {
+	case 0:
+		return 0, errors.New("key not found: " + label)
+	case 1:
+		return objs[0], nil
+	default:
+		return 0, errors.New("more than one key labelled: " + label)
+	}
+}
+
+// parseEcPoint parses the CKA_EC_POINT of an Ed25519 public key, which is the
+// 32-byte key encoded as a DER OCTET STRING. Some tokens omit the encoding,
+// so a bare 32-byte key is also accepted.
+func parseEcPoint(buf []byte) (*zed.Public, error) {
+	if len(buf) == 34 && buf[0] == 0x04 && buf[1] == 32 {
+		buf = buf[2:]
+	}
+	if len(buf) != 32 {
+		return nil, fmt.Errorf("bad CKA_EC_POINT length: %d", len(buf))
+	}
+	return zed.PublicFromKeyStrict(buf)
+}
+
+// Close logs out of the token, and closes the session and the module.
+func (s *Signer) Close() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	var err = s.ctx.Logout(s.session)
+	if cerr := s.ctx.CloseSession(s.session); err == nil {
+		err = cerr
+	}
+	if ferr := s.ctx.Finalize(); err == nil {
+		err = ferr
+	}
+	s.ctx.Destroy()
+	if err != nil {
+		return fmt.Errorf("Close: %w", err)
+	}
+	return nil
+}
+
+// Public returns the public key of the signer.
+func (s *Signer) Public() *zed.Public {
+	return s.pk
+}
+
+// Sign asks the token to sign msg, and returns the signature, which is a
+// standard Ed25519 signature by the public key of the signer. An error is
+// returned if the token fails to sign, or if the signature it returns is
+// invalid.
+func (s *Signer) Sign(msg []byte) (zed.Signature, error) {
+	var sig zed.Signature
+
+	s.mu.Lock()
+	var mech = []*p11.Mechanism{p11.NewMechanism(ckmEddsa, nil)}
+	var err = s.ctx.SignInit(s.session, mech, s.key)
+	var buf []byte
+	if err == nil {
+		buf, err = s.ctx.Sign(s.session, msg)
+	}
+	s.mu.Unlock()
+
+	if err != nil {
+		return sig, fmt.Errorf("Signer.Sign: %w", err)
+	}
+	if len(buf) != len(sig) {
+		return sig, fmt.Errorf("Signer.Sign: bad signature length: %d", len(buf))
+	}
+	copy(sig[:], buf)
+
+	// check the signature, rather than trusting the token
+	if !s.pk.Verify(msg, sig[:]) {
+		return zed.Signature{}, errors.New("Signer.Sign: token returned an invalid signature")
+	}
+
+	return sig, nil
+}
+
+// VrfEval returns zed.ErrRemoteUnsupported, since PKCS#11 has no VRF
+// mechanism for Ed25519 keys. It makes Signer a zed.RemoteSigner.
+func (s *Signer) VrfEval(x []byte) (zed.VrfResult, zed.VrfProof, error) {
+	return zed.VrfResult{}, zed.VrfProof{}, zed.ErrRemoteUnsupported
+}
@@ -0,0 +1,84 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package pkcs11
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+
+	"github.com/zoobc/zed25519/zed"
+)
+
+// testKey is the public key of RFC 8032 section 7.1 test 1.
+var testKey, _ = hex.DecodeString("d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a")
+
+func TestParseEcPoint(t *testing.T) {
+
+	// DER OCTET STRING, as the standard has it, and the bare key
+	for _, buf := range [][]byte{append([]byte{0x04, 32}, testKey...), testKey} {
+		var pk, err = parseEcPoint(buf)
+		if err != nil {
+			t.Fatalf("parseEcPoint(%x) = %v", buf, err)
+		}
+		if As := pk.Key(); !bytes.Equal(As[:], testKey) {
+			t.Errorf("parseEcPoint(%x) = %x", buf, pk.Key())
+		}
+	}
+}
+
+var parseEcPointErrorTests = [][]byte{
+	nil,
+	testKey[:31],
+	append([]byte{0x04, 31}, testKey...),
+	append([]byte{0x03, 32}, testKey...),
+	append([]byte{0x04, 33}, append(testKey, 0)...),
+
+	// not a point, and y = p, a non-canonical encoding of y = 0
+	append([]byte{0x04, 32, 2}, make([]byte, 31)...),
+	append(append([]byte{0xed}, bytes.Repeat([]byte{0xff}, 30)...), 0x7f),
+}
+
+func TestParseEcPointErrors(t *testing.T) {
+	for _, buf := range parseEcPointErrorTests {
+		if _, err := parseEcPoint(buf); err == nil {
+			t.Errorf("parseEcPoint accepted %x", buf)
+		}
+	}
+}
+
+func TestOpenMissingModule(t *testing.T) {
+	if _, err := Open("/nonexistent/libpkcs11.so", "token", "1234", "key"); err == nil {
+		t.Error("Open succeeded without a module")
+	}
+}
+
+func TestVrfEval(t *testing.T) {
+	var s = &Signer{}
+	if _, _, err := s.VrfEval([]byte("x")); err != zed.ErrRemoteUnsupported {
+		t.Errorf("VrfEval = %v, want zed.ErrRemoteUnsupported", err)
+	}
+}
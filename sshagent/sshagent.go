@@ -0,0 +1,184 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package sshagent
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+	"net"
+	"os"
+
+	"github.com/zoobc/zed25519/zed"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+//
+//  An ssh-agent holds private keys on behalf of other programs, and signs
+//  with them on request, over the SSH agent protocol, without ever revealing
+//  them. The keys may also live on a hardware token which the agent talks to
+//  (for example a smart card, or ssh-agent's PKCS#11 support). This package
+//  lets a zed application sign with Ed25519 keys held by an agent, so that
+//  the private keys never need to be on the signing host at all.
+//
+//  An agent signs with an "ssh-ed25519" key by making a plain Ed25519
+//  signature on the exact bytes it is given, so the signatures it returns are
+//  ordinary zed Signatures, which verify with Public.Verify like any other.
+//  Each signature is checked against the public key before it is returned,
+//  so that a faulty or malicious agent cannot pass off an invalid one.
+//
+//  REFERENCES:
+//    [1] Damien Miller, "SSH Agent Protocol"
+//        https://tools.ietf.org/html/draft-miller-ssh-agent
+//
+
+// Client is a connection to a running ssh-agent, made by Dial.
+type Client struct {
+	agent.ExtendedAgent
+	conn net.Conn
+}
+
+// Dial connects to the ssh-agent listening on the socket named by the
+// SSH_AUTH_SOCK environment variable, as ssh and ssh-add do.
+func Dial() (*Client, error) {
+	var sock = os.Getenv("SSH_AUTH_SOCK")
+	if sock == "" {
+		return nil, errors.New("Dial: SSH_AUTH_SOCK is not set")
+	}
+	var conn, err = net.Dial("unix", sock)
+	if err != nil {
+		return nil, fmt.Errorf("Dial: %w", err)
+	}
+	return &Client{ExtendedAgent: agent.NewClient(conn), conn: conn}, nil
+}
+
+// Close closes the connection to the agent.
+func (c *Client) Close() error {
+	return c.conn.Close()
+}
+
+// Keys lists the public keys of the Ed25519 keys held by the agent, in the
+// order the agent lists them. Keys of other types are skipped.
+func Keys(a agent.Agent) ([]*zed.Public, error) {
+	var keys, err = a.List()
+	if err != nil {
+		return nil, fmt.Errorf("Keys: %w", err)
+	}
+
+	var pks []*zed.Public
+	for _, key := range keys {
+		if pk, ok := publicFromAgentKey(key); ok {
+			pks = append(pks, pk)
+		}
+	}
+	return pks, nil
+}
+
+// Signer signs with one Ed25519 key held by an ssh-agent.
+type Signer struct {
+	agent agent.Agent
+	key   ssh.PublicKey
+	pk    *zed.Public
+}
+
+// NewSigner returns a Signer for the key of the agent whose public key is pk,
+// or an error if the agent does not hold it.
+func NewSigner(a agent.Agent, pk *zed.Public) (*Signer, error) {
+	var keys, err = a.List()
+	if err != nil {
+		return nil, fmt.Errorf("NewSigner: %w", err)
+	}
+
+	var want = pk.Key()
+	for _, key := range keys {
+		if got, ok := publicFromAgentKey(key); ok && got.Key() == want {
+			return &Signer{agent: a, key: key, pk: pk}, nil
+		}
+	}
+	return nil, errors.New("NewSigner: key not held by agent")
+}
+
+// Public returns the public key of the signer.
+func (s *Signer) Public() *zed.Public {
+	return s.pk
+}
+
+// Sign asks the agent to sign msg, and returns the signature, which is a
+// standard Ed25519 signature by the public key of the signer. An error is
+// returned if the agent refuses or fails to sign (for example, if it asks
+// for confirmation and the user declines), or if the signature it returns is
+// invalid.
+func (s *Signer) Sign(msg []byte) (zed.Signature, error) {
+	var sig zed.Signature
+
+	var ssig, err = s.agent.Sign(s.key, msg)
+	if err != nil {
+		return sig, fmt.Errorf("Signer.Sign: %w", err)
+	}
+	if ssig.Format != ssh.KeyAlgoED25519 || len(ssig.Blob) != len(sig) {
+		return sig, errors.New("Signer.Sign: unexpected signature format: " + ssig.Format)
+	}
+	copy(sig[:], ssig.Blob)
+
+	// check the signature, rather than trusting the agent
+	if !s.pk.Verify(msg, sig[:]) {
+		return zed.Signature{}, errors.New("Signer.Sign: agent returned an invalid signature")
+	}
+
+	return sig, nil
+}
+
+// VrfEval returns zed.ErrRemoteUnsupported, since an agent can only make
+// plain signatures. It makes Signer a zed.RemoteSigner.
+func (s *Signer) VrfEval(x []byte) (zed.VrfResult, zed.VrfProof, error) {
+	return zed.VrfResult{}, zed.VrfProof{}, zed.ErrRemoteUnsupported
+}
+
+// publicFromAgentKey converts an "ssh-ed25519" key listed by an agent into a
+// Public, reporting false for keys of any other type, or invalid keys.
+func publicFromAgentKey(key *agent.Key) (*zed.Public, bool) {
+	if key.Format != ssh.KeyAlgoED25519 {
+		return nil, false
+	}
+	var pub, err = ssh.ParsePublicKey(key.Blob)
+	if err != nil {
+		return nil, false
+	}
+	var cpk, ok = pub.(ssh.CryptoPublicKey)
+	if !ok {
+		return nil, false
+	}
+	var edpk, isEd = cpk.CryptoPublicKey().(ed25519.PublicKey)
+	if !isEd || len(edpk) != ed25519.PublicKeySize {
+		return nil, false
+	}
+	var pk, perr = zed.PublicFromKeyStrict(edpk)
+	if perr != nil {
+		return nil, false
+	}
+	return pk, true
+}
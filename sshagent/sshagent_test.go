@@ -0,0 +1,181 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package sshagent
+
+import (
+	"bytes"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"net"
+	"testing"
+
+	"github.com/zoobc/zed25519/zed"
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+)
+
+func testSecret(n byte) *zed.Secret {
+	return zed.SecretFromSeed(bytes.Repeat([]byte{n}, 32))
+}
+
+// testAgent serves an in-memory keyring holding an ECDSA key and the given
+// Ed25519 keys over a pipe, and returns a client for it, so that requests
+// and replies go through the encoding of the agent protocol.
+func testAgent(t *testing.T, sks ...*zed.Secret) agent.ExtendedAgent {
+	var keyring = agent.NewKeyring()
+
+	var ec, _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err := keyring.Add(agent.AddedKey{PrivateKey: ec}); err != nil {
+		t.Fatal(err)
+	}
+	for _, sk := range sks {
+		var key, _ = sk.StdPrivateKey()
+		if err := keyring.Add(agent.AddedKey{PrivateKey: key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var client, server = net.Pipe()
+	go agent.ServeAgent(keyring, server)
+	t.Cleanup(func() { client.Close() })
+	return agent.NewClient(client)
+}
+
+func TestKeys(t *testing.T) {
+	var a = testAgent(t, testSecret(1), testSecret(2))
+
+	// the ECDSA key is skipped
+	var pks, err = Keys(a)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pks) != 2 || pks[0].Key() != testSecret(1).Public().Key() || pks[1].Key() != testSecret(2).Public().Key() {
+		t.Errorf("Keys returned %d keys, not those of the agent", len(pks))
+	}
+}
+
+func TestSigner(t *testing.T) {
+	var sk = testSecret(2)
+	var a = testAgent(t, testSecret(1), sk)
+
+	var signer, err = NewSigner(a, sk.Public())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signer.Public().Key() != sk.Public().Key() {
+		t.Error("Signer has another public key")
+	}
+
+	var msg = []byte("agent message")
+	sig, err := signer.Sign(msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the agent makes the same deterministic signature as zed
+	if sig != sk.Sign(msg) {
+		t.Error("agent signature differs from zed's")
+	}
+	if !sk.Public().Verify(msg, sig[:]) {
+		t.Error("agent signature does not verify")
+	}
+
+	if _, _, err := signer.VrfEval(msg); err != zed.ErrRemoteUnsupported {
+		t.Errorf("VrfEval = %v, want zed.ErrRemoteUnsupported", err)
+	}
+	if _, err := NewSigner(a, testSecret(3).Public()); err == nil {
+		t.Error("NewSigner accepted a key the agent does not hold")
+	}
+}
+
+// badAgent is an agent which returns the wrong signature.
+type badAgent struct {
+	agent.Agent
+	format string
+}
+
+func (a badAgent) Sign(key ssh.PublicKey, data []byte) (*ssh.Signature, error) {
+	var sig, err = a.Agent.Sign(key, data)
+	if err != nil {
+		return nil, err
+	}
+	if a.format != "" {
+		sig.Format = a.format
+	} else {
+		sig.Blob[0] ^= 1
+	}
+	return sig, nil
+}
+
+func TestSignerBadAgent(t *testing.T) {
+	var sk = testSecret(2)
+	for _, format := range []string{"", "ssh-rsa"} {
+		var a = badAgent{testAgent(t, sk), format}
+		var signer, err = NewSigner(a, sk.Public())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := signer.Sign([]byte("agent message")); err == nil {
+			t.Errorf("format %q: Sign accepted a bad signature from the agent", format)
+		}
+	}
+
+	// a locked agent refuses to sign
+	var a = testAgent(t, sk)
+	var signer, _ = NewSigner(a, sk.Public())
+	a.Lock([]byte("passphrase"))
+	if _, err := signer.Sign([]byte("agent message")); err == nil {
+		t.Error("a locked agent signed")
+	}
+}
+
+func TestPublicFromAgentKey(t *testing.T) {
+	var As = testSecret(2).Public().Key()
+	var pub, _ = ssh.NewPublicKey(ed25519.PublicKey(As[:]))
+	var key = &agent.Key{Format: pub.Type(), Blob: pub.Marshal()}
+	if pk, ok := publicFromAgentKey(key); !ok || pk.Key() != testSecret(2).Public().Key() {
+		t.Error("publicFromAgentKey did not recover the key")
+	}
+
+	for _, bad := range []*agent.Key{
+		{Format: "ssh-rsa", Blob: key.Blob},
+		{Format: ssh.KeyAlgoED25519, Blob: key.Blob[:len(key.Blob)-1]},
+		{Format: ssh.KeyAlgoED25519, Blob: nil},
+	} {
+		if _, ok := publicFromAgentKey(bad); ok {
+			t.Errorf("publicFromAgentKey accepted %s %x", bad.Format, bad.Blob)
+		}
+	}
+}
+
+func TestDial(t *testing.T) {
+	t.Setenv("SSH_AUTH_SOCK", "")
+	if _, err := Dial(); err == nil {
+		t.Error("Dial succeeded without SSH_AUTH_SOCK")
+	}
+}
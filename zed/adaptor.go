@@ -0,0 +1,181 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"errors"
+)
+
+//
+//  An adaptor signature is a "pre-signature" on a message, locked to a point
+//  T. Anyone can check that it is correct, but it only becomes a valid
+//  Ed25519 signature once it is completed with the secret t = log(T), and
+//  once the completed signature is published, anyone holding the
+//  pre-signature can extract t from it. This is the basis of scriptless
+//  atomic swaps: publishing the signature which claims one side of a swap
+//  reveals the secret which unlocks the other side.
+//
+//    sign:     r = sha512(adaptor_str || p || Ts || m) % q
+//              R' = r * G,  R = R' + T
+//              h = sha512(Rs || As || m) % q
+//              s' = (r + h * a) % q,  pre = Rs || s'
+//    verify:   s' * G == (R - T) + h * A
+//    complete: s = (s' + t) % q,  sig = Rs || s
+//    extract:  t = (s - s') % q
+//
+//  The challenge h is computed over the final R, so the completed signature
+//  is an ordinary Ed25519 signature which Verify accepts. The nonce r also
+//  depends on T, so pre-signing the same message for two different points
+//  never re-uses a nonce.
+//
+
+// AdaptorSignature is a pre-signature made by SignAdaptor, holding the final
+// nonce point R and the adapted scalar s', in the layout of a signature.
+type AdaptorSignature [64]byte
+
+// SignAdaptor produces a pre-signature by sk on msg, locked to the point T,
+// which becomes a valid signature when completed with t = log(T). It panics
+// in the same cases as Sign.
+func (sk *Secret) SignAdaptor(msg []byte, T *Point) AdaptorSignature {
+
+	// if prefix is all zeroes, panic
+	if sk.hasZeroPrefix() {
+		panic("SignAdaptor: secret key has an all-zero prefix")
+	}
+
+	// sha512 instance, result buffer
+	var hash = sha512.New()
+	var res Buffer512
+
+	// Take private scalar "a", prefix "p", and encodings As and Ts
+	var a = sk.Scalar()
+	var p = sk.Prefix()
+	var As = sk.Public().Key()
+	var Ts = PointToKey(T)
+
+	// r = sha512(adaptor_str || p || Ts || m) % q
+	var r Scalar
+	hash.Write([]byte("zed25519_adaptor"))
+	hash.Write(p[:])
+	hash.Write(Ts[:])
+	hash.Write(msg)
+	hash.Sum(res[:0])
+	ScalarReduce512(&r, &res)
+
+	// R = r * G + T
+	var rG, R Point
+	ScalarMultBase(&rG, &r)
+	PointAdd(&R, &rG, T)
+	var Rs = PointToKey(&R)
+
+	// h = sha512(Rs || As || m) % q
+	var h Scalar
+	hash.Reset()
+	hash.Write(Rs[:])
+	hash.Write(As[:])
+	hash.Write(msg)
+	hash.Sum(res[:0])
+	ScalarReduce512(&h, &res)
+
+	// s' = (r + ha) % q
+	var s Scalar
+	ScalarMultScalarAddScalar(&s, &h, &a, &r)
+
+	// pre = Rs || s'
+	var pre AdaptorSignature
+	copy(pre[:32], Rs[:])
+	copy(pre[32:], s[:])
+
+	// forget the secrets
+	wipe(a[:])
+	wipe(p[:])
+	wipe(r[:])
+	wipe(res[:])
+
+	return pre
+}
+
+// VerifyAdaptor checks whether pre is a valid pre-signature by pk on msg,
+// locked to T, so that completing it with t = log(T) gives a signature which
+// Verify accepts.
+func (pk *Public) VerifyAdaptor(msg []byte, T *Point, pre AdaptorSignature) bool {
+	var ps, err = ParseSignature(pre[:])
+	if err != nil {
+		return false
+	}
+
+	// h = sha512(Rs || As || m) % q
+	var hash = sha512.New()
+	var res Buffer512
+	var As = pk.Key()
+	var h Scalar
+	hash.Write(ps.rs[:])
+	hash.Write(As[:])
+	hash.Write(msg)
+	hash.Sum(res[:0])
+	ScalarReduce512(&h, &res)
+
+	// valid if: s' * G == (R - T) + hA, checked with R - T in place of R
+	var adapted = *ps
+	PointSub(&adapted.r, &ps.r, T)
+	return pk.verifyChallenge(&adapted, &h)
+}
+
+// CompleteAdaptor completes the pre-signature pre with the secret t, giving
+// the signature Rs || (s' + t) % q. The result is only valid if t = log(T)
+// for the point T the pre-signature was locked to.
+func CompleteAdaptor(pre AdaptorSignature, t *Scalar) Signature {
+	var s, sum Scalar
+	copy(s[:], pre[32:])
+
+	// s = (s' + t) % q
+	ScalarMultScalarAddScalar(&sum, &scalarOne, &s, t)
+
+	var sig Signature
+	copy(sig[:32], pre[:32])
+	copy(sig[32:], sum[:])
+	return sig
+}
+
+// ExtractSecret recovers the secret t = (s - s') % q from a pre-signature and
+// the signature it was completed into. An error is returned if the two do
+// not share the same R, since then sig was not completed from pre.
+func ExtractSecret(pre AdaptorSignature, sig Signature) (Scalar, error) {
+	var t Scalar
+	if !bytes.Equal(pre[:32], sig[:32]) {
+		return t, errors.New("ExtractSecret: signature does not match pre-signature")
+	}
+
+	// t = (s - s') % q
+	var s, sPre Scalar
+	copy(s[:], sig[32:])
+	copy(sPre[:], pre[32:])
+	ScalarSub(&t, &s, &sPre)
+
+	return t, nil
+}
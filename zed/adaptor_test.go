@@ -0,0 +1,111 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"bytes"
+	"testing"
+)
+
+// testAdaptorPoint returns a fixed adaptor secret t and its point T = t * G.
+func testAdaptorPoint(n byte) (Scalar, Point) {
+	var t Scalar
+	var buf = Buffer512{n, 0x5a, n}
+	ScalarReduce512(&t, &buf)
+	var T Point
+	ScalarMultBase(&T, &t)
+	return t, T
+}
+
+func TestAdaptor(t *testing.T) {
+	var sk = testSecret(2)
+	var pk = sk.Public()
+	var msg = []byte("swap leg 1")
+	var secret, T = testAdaptorPoint(1)
+
+	var pre = sk.SignAdaptor(msg, &T)
+	if !pk.VerifyAdaptor(msg, &T, pre) {
+		t.Fatal("pre-signature does not verify")
+	}
+
+	// a pre-signature is not a signature, until completed
+	if pk.Verify(msg, pre[:]) {
+		t.Error("pre-signature verifies as a signature")
+	}
+	var sig = CompleteAdaptor(pre, &secret)
+	if !pk.Verify(msg, sig[:]) {
+		t.Fatal("completed signature does not verify")
+	}
+
+	var extracted, err = ExtractSecret(pre, sig)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !ScalarEqual(&extracted, &secret) {
+		t.Error("ExtractSecret did not recover t")
+	}
+
+	// signing is deterministic in (sk, T, msg), and T changes the nonce
+	if sk.SignAdaptor(msg, &T) != pre {
+		t.Error("SignAdaptor is not deterministic")
+	}
+	var _, T2 = testAdaptorPoint(2)
+	var pre2 = sk.SignAdaptor(msg, &T2)
+	if bytes.Equal(pre2[:32], pre[:32]) {
+		t.Error("another T re-uses the nonce")
+	}
+}
+
+func TestAdaptorWrongT(t *testing.T) {
+	var sk = testSecret(2)
+	var pk = sk.Public()
+	var msg = []byte("swap leg 1")
+	var _, T = testAdaptorPoint(1)
+	var wrong, W = testAdaptorPoint(2)
+	var pre = sk.SignAdaptor(msg, &T)
+
+	if pk.VerifyAdaptor(msg, &W, pre) {
+		t.Error("pre-signature verifies under another T")
+	}
+	if pk.VerifyAdaptor([]byte("swap leg 2"), &T, pre) {
+		t.Error("pre-signature verifies another message")
+	}
+	if testSecret(3).Public().VerifyAdaptor(msg, &T, pre) {
+		t.Error("pre-signature verifies under another key")
+	}
+
+	// completing with the wrong secret gives an invalid signature
+	var sig = CompleteAdaptor(pre, &wrong)
+	if pk.Verify(msg, sig[:]) {
+		t.Error("completing with the wrong t gives a valid signature")
+	}
+
+	// a signature which was not completed from pre
+	var other = sk.Sign(msg)
+	if _, err := ExtractSecret(pre, other); err == nil {
+		t.Error("ExtractSecret accepted an unrelated signature")
+	}
+}
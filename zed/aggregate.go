@@ -0,0 +1,141 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha512"
+	"fmt"
+)
+
+//
+//  An aggregated VRF combines the VRF evaluations of every member of a fixed
+//  committee on the same input x into a single output, with a single proof
+//  object that binds all of them together:
+//
+//    y_i   = VRF output of member i on x, with proof pi_i
+//    y     = sha512(agg_str || As_1 || ... || As_n || y_1 || ... || y_n)[:32]
+//    proof = pi_1 || ... || pi_n
+//
+//  The proof is only valid for the committee in exactly the given order, and
+//  verifying it checks every member's proof and recomputes y.
+//
+//  Security model: every y_i is unpredictable without the corresponding
+//  secret key and unique for (key, x), so no member can choose or bias its
+//  own contribution, and y is unpredictable as long as at least one member is
+//  honest. However, a member can withhold its evaluation after seeing the
+//  others, which prevents y from being produced at all. The committee must
+//  therefore be fixed before x is known, and the protocol around it must
+//  tolerate or punish withholding, typically by assuming an honest majority.
+//
+
+// AggregateR computes the sum of the R points of a list of signatures, as a
+// building block for batch commitment schemes. It returns an error naming the
+// first signature which has the wrong length, or whose R is not a valid
+// curve point. The sum of an empty list is the identity point.
+func AggregateR(sigs [][]byte) (Point, error) {
+	var sum Point
+	PointIdentity(&sum)
+
+	var Rs Buffer256
+	var R Point
+	for i, sig := range sigs {
+		if len(sig) != 64 {
+			return sum, fmt.Errorf("AggregateR: signature %d: bad signature length: %d", i, len(sig))
+		}
+
+		// R = decompress(sig[:32]), or fail
+		copy(Rs[:], sig[:32])
+		if !DecompressPoint(&R, &Rs) {
+			return sum, fmt.Errorf("AggregateR: signature %d: invalid point", i)
+		}
+
+		// sum = sum + R
+		PointAdd(&sum, &sum, &R)
+	}
+
+	return sum, nil
+}
+
+// AggregateVrfProve evaluates the VRF on x for every member of a committee,
+// and combines the results into one output and one aggregate proof, as
+// described above. In practice each member evaluates the VRF on its own
+// machine; this is the equivalent for a single party holding every key.
+func AggregateVrfProve(secrets []*Secret, x []byte) (VrfResult, []byte) {
+	var pubs = make([]*Public, len(secrets))
+	var outputs = make([]VrfResult, len(secrets))
+	var aggProof = make([]byte, 0, 96*len(secrets))
+
+	for i, sk := range secrets {
+		var proof VrfProof
+		pubs[i] = sk.Public()
+		outputs[i], proof = sk.VrfEval(x)
+		aggProof = append(aggProof, proof[:]...)
+	}
+
+	return aggregateVrfResult(pubs, outputs), aggProof
+}
+
+// AggregateVrfVerify checks an aggregate VRF proof for the committee pubs on
+// input x, and that it yields aggResult.
+func AggregateVrfVerify(pubs []*Public, x []byte, aggResult VrfResult, aggProof []byte) bool {
+	if len(pubs) == 0 || len(aggProof) != 96*len(pubs) {
+		return false
+	}
+
+	// verify each member's proof
+	var outputs = make([]VrfResult, len(pubs))
+	for i, pk := range pubs {
+		var ok bool
+		outputs[i], ok = pk.VrfVerify(x, aggProof[96*i:96*(i+1)])
+		if !ok {
+			return false
+		}
+	}
+
+	// valid if the combined output matches
+	var y = aggregateVrfResult(pubs, outputs)
+	return y == aggResult
+}
+
+// aggregateVrfResult computes
+// y = sha512(agg_str || As_1 || ... || As_n || y_1 || ... || y_n)[:32].
+func aggregateVrfResult(pubs []*Public, outputs []VrfResult) VrfResult {
+	var hash = sha512.New()
+	var res Buffer512
+	hash.Write([]byte("zed25519_vrf_aggregate"))
+	for _, pk := range pubs {
+		var As = pk.Key()
+		hash.Write(As[:])
+	}
+	for i := range outputs {
+		hash.Write(outputs[i][:])
+	}
+	hash.Sum(res[:0])
+
+	var y VrfResult
+	copy(y[:], res[:32])
+	return y
+}
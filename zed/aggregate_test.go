@@ -0,0 +1,120 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"testing"
+)
+
+func TestAggregateR(t *testing.T) {
+	var sigs [][]byte
+	var want Point
+	PointIdentity(&want)
+	for n := byte(0); n < 4; n++ {
+		var sig = testSecret(n).Sign([]byte{n})
+		sigs = append(sigs, sig[:])
+
+		var Rs Buffer256
+		var R Point
+		copy(Rs[:], sig[:32])
+		DecompressPoint(&R, &Rs)
+		PointAdd(&want, &want, &R)
+	}
+
+	var sum, err = AggregateR(sigs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !PointEqual(&sum, &want) {
+		t.Error("AggregateR is not the sum of the R points")
+	}
+
+	var I Point
+	PointIdentity(&I)
+	if empty, err := AggregateR(nil); err != nil || !PointEqual(&empty, &I) {
+		t.Error("AggregateR of no signatures is not the identity")
+	}
+
+	if _, err := AggregateR(append(sigs, sigs[0][:63])); err == nil {
+		t.Error("AggregateR accepted a short signature")
+	}
+
+	// y = 2 is not on the curve
+	var bad = make([]byte, 64)
+	bad[0] = 2
+	if _, err := AggregateR(append(sigs, bad)); err == nil {
+		t.Error("AggregateR accepted an invalid R")
+	}
+}
+
+func TestAggregateVrf(t *testing.T) {
+	var committee = []*Secret{testSecret(17), testSecret(18), testSecret(19)}
+	var pubs = []*Public{committee[0].Public(), committee[1].Public(), committee[2].Public()}
+	var x = []byte("epoch 12")
+
+	var y, proof = AggregateVrfProve(committee, x)
+	if len(proof) != 3*96 {
+		t.Fatalf("aggregate proof length = %d, want %d", len(proof), 3*96)
+	}
+	if !AggregateVrfVerify(pubs, x, y, proof) {
+		t.Fatal("valid aggregate proof rejected")
+	}
+	if again, _ := AggregateVrfProve(committee, x); again != y {
+		t.Error("AggregateVrfProve is not deterministic")
+	}
+
+	// the output depends on every member
+	for i := range committee {
+		var y1, _ = committee[i].VrfEval(x)
+		if y1 == y {
+			t.Errorf("aggregate output equals member %d's output", i)
+		}
+	}
+
+	if AggregateVrfVerify(pubs, []byte("epoch 13"), y, proof) {
+		t.Error("aggregate proof verified on another input")
+	}
+	var wrong = y
+	wrong[0] ^= 1
+	if AggregateVrfVerify(pubs, x, wrong, proof) {
+		t.Error("aggregate proof verified for another output")
+	}
+	var reordered = []*Public{pubs[1], pubs[0], pubs[2]}
+	if AggregateVrfVerify(reordered, x, y, proof) {
+		t.Error("aggregate proof verified for a reordered committee")
+	}
+	if AggregateVrfVerify(pubs[:2], x, y, proof[:2*96]) {
+		t.Error("aggregate proof verified without a member")
+	}
+	var corrupt = append([]byte(nil), proof...)
+	corrupt[96+40] ^= 1
+	if AggregateVrfVerify(pubs, x, y, corrupt) {
+		t.Error("aggregate proof with a corrupted member proof verified")
+	}
+	if AggregateVrfVerify(nil, x, y, nil) {
+		t.Error("empty aggregate proof verified")
+	}
+}
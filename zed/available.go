@@ -0,0 +1,104 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"encoding/hex"
+	"errors"
+	"hash"
+
+	"golang.org/x/crypto/sha3"
+)
+
+//
+//  The package depends on SHA-512 (signatures, VRF) and SHA3-512 (key
+//  derivation). In some restricted builds (for example FIPS-only toolchains,
+//  or builds with a replaced crypto implementation) a hash function may be
+//  unavailable or behave differently. Rather than silently producing wrong
+//  keys and signatures, the package checks both hash functions against known
+//  digests when it is initialized, and reports the result with Available.
+//
+
+// hashSelfTests are the known-answer tests run at initialization, as the
+// digest of "abc" for each hash function.
+var hashSelfTests = []struct {
+	name   string
+	hash   func() hash.Hash
+	digest string
+}{
+	{
+		name:   "SHA-512",
+		hash:   sha512.New,
+		digest: "ddaf35a193617abacc417349ae20413112e6fa4e89a97ea20a9eeee64b55d39a2192992a274fc1a836ba3c23a3feebbd454d4423643ce80e2a9ac94fa54ca49f",
+	},
+	{
+		name:   "SHA3-512",
+		hash:   sha3.New512,
+		digest: "b751850b1a57168a5693cd924b6b096e08f621827444f70d884f5d0240d2712e10e116e9192af3c91a7ec57647e3934057340b4cf408d5a56592f8274eec53f0",
+	},
+}
+
+// availableErr holds the result of the initialization self-tests.
+var availableErr = hashSelfTest()
+
+// hashSelfTest runs the known-answer test for each hash function, returning
+// an error describing the first one which fails.
+func hashSelfTest() error {
+	for _, test := range hashSelfTests {
+		if err := hashKnownAnswer(test.name, test.hash, test.digest); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// hashKnownAnswer checks that the hash of "abc" is digest.
+func hashKnownAnswer(name string, newHash func() hash.Hash, digest string) (err error) {
+
+	// a missing implementation may panic rather than misbehave
+	defer func() {
+		if r := recover(); r != nil {
+			err = errors.New("zed: " + name + " is unavailable")
+		}
+	}()
+
+	var hash = newHash()
+	hash.Write([]byte("abc"))
+	var want, _ = hex.DecodeString(digest)
+	if !bytes.Equal(hash.Sum(nil), want) {
+		return errors.New("zed: " + name + " self-test failed")
+	}
+	return nil
+}
+
+// Available reports whether the hash functions the package depends on work
+// correctly in this build. It returns nil on any normal build. If it returns
+// an error, none of the package's keys, signatures or proofs can be trusted.
+func Available() error {
+	return availableErr
+}
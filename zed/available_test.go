@@ -0,0 +1,52 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha256"
+	"hash"
+	"testing"
+)
+
+func TestAvailable(t *testing.T) {
+	if err := Available(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestHashKnownAnswer(t *testing.T) {
+
+	// a hash which gives the wrong digest
+	if hashKnownAnswer("SHA-512", sha256.New, hashSelfTests[0].digest) == nil {
+		t.Error("hashKnownAnswer accepted the wrong hash function")
+	}
+
+	// a hash which is missing altogether
+	var missing = func() hash.Hash { panic("missing") }
+	if hashKnownAnswer("SHA-512", missing, hashSelfTests[0].digest) == nil {
+		t.Error("hashKnownAnswer accepted a missing hash function")
+	}
+}
@@ -0,0 +1,189 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+)
+
+//
+//  Batch verification checks many signatures at once, faster than checking
+//  each one with Verify. Each signature (R_i, s_i) by A_i on m_i satisfies
+//  s_i * G = R_i + h_i * A_i, where h_i = sha512(R_i || A_i || m_i). A random
+//  128-bit scalar z_i is chosen for each signature, and all the equations
+//  are checked at once as a single random linear combination:
+//
+//    8 * (-(z_1 * s_1 + ... + z_n * s_n) * G
+//         + z_1 * R_1 + ... + z_n * R_n
+//         + (z_1 * h_1) * A_1 + ... + (z_n * h_n) * A_n) == I
+//
+//  using one multi-scalar multiplication. If any signature is invalid, the
+//  combination fails except with probability about 2^-128, and since the z_i
+//  are secret and random, an attacker cannot choose invalid signatures which
+//  cancel each other out.
+//
+//  The check is multiplied by the cofactor (8), as random linear combinations
+//  of the cofactorless equation used by Verify would accept or reject
+//  signatures with small-order components depending on the random z_i. As a
+//  result, a batch of honestly generated signatures always gives the same
+//  result as Verify, but a deliberately crafted signature with a small-order
+//  component can pass VerifyBatch even though Verify rejects it.
+//
+//  VerifyBatch instead agrees exactly with the cofactored single-signature
+//  check, VerifyWithOpts with VerifyOpts{ZIP215: true}: a batch passes
+//  exactly when every signature in it passes that check (except with
+//  probability about 2^-128). Systems where every node must reach the same verdict on every
+//  signature, such as blockchains, must therefore use that check, and not
+//  Verify, wherever they verify signatures one at a time alongside
+//  VerifyBatch; otherwise a crafted signature is valid for the nodes which
+//  check it in a batch, and invalid for the nodes which check it alone.
+//
+
+// VerifyBatchCtx checks, for each i, whether sigs[i] is a valid signature on
+// msgs[i] for pks[i], as Verify would. The context is checked before each
+// signature is verified, and if it has been cancelled, no results are returned
+// along with ctx.Err(), so that a server can abandon a large batch when the
+// request behind it goes away. An error is also returned if the three slices
+// have different lengths.
+func VerifyBatchCtx(ctx context.Context, pks []*Public, msgs, sigs [][]byte) ([]bool, error) {
+	if len(pks) != len(msgs) || len(pks) != len(sigs) {
+		return nil, errors.New("VerifyBatchCtx: mismatched lengths")
+	}
+
+	var res = make([]bool, len(pks))
+	for i, pk := range pks {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		res[i] = pk.Verify(msgs[i], sigs[i])
+	}
+
+	return res, nil
+}
+
+// VerifyBatch checks whether, for every i, sigs[i] is a valid signature on
+// msgs[i] for pks[i], using a random linear combination of all the
+// verification equations. It is several times faster than calling Verify for
+// every signature, but only reports whether the whole batch is valid; use
+// VerifyBatchFailures to find out which signatures are not. An empty batch is
+// valid. It panics if the three slices have different lengths, or if the
+// system's secure random number generator fails.
+func VerifyBatch(pks []*Public, msgs [][]byte, sigs []Signature) bool {
+	if len(pks) != len(msgs) || len(pks) != len(sigs) {
+		panic("VerifyBatch: mismatched lengths")
+	}
+	var n = len(pks)
+	if n == 0 {
+		return true
+	}
+
+	// random 128-bit coefficients z_i
+	var zs = make([]byte, 16*n)
+	if _, err := rand.Read(zs); err != nil {
+		panic("VerifyBatch: " + err.Error())
+	}
+
+	// scalars = (b, z_1, z_1 * h_1, ..., z_n, z_n * h_n)
+	// points  = (G, R_1, A_1, ..., R_n, A_n)
+	var scalars = make([]Scalar, 1+2*n)
+	var points = make([]Point, 1+2*n)
+	BasePoint(&points[0])
+	var scalarPtrs = make([]*Scalar, 1+2*n)
+	var pointPtrs = make([]*Point, 1+2*n)
+	for i := range scalars {
+		scalarPtrs[i] = &scalars[i]
+		pointPtrs[i] = &points[i]
+	}
+
+	var hash = sha512.New()
+	var res Buffer512
+	var b Scalar
+	for i := range pks {
+		var ps, err = ParseSignature(sigs[i][:])
+		if err != nil {
+			return false
+		}
+
+		var z Scalar
+		copy(z[:16], zs[16*i:16*(i+1)])
+
+		// h = sha512(Rs || As || m) % q
+		var As = pks[i].Key()
+		var h Scalar
+		hash.Reset()
+		hash.Write(ps.rs[:])
+		hash.Write(As[:])
+		hash.Write(msgs[i])
+		hash.Sum(res[:0])
+		ScalarReduce512(&h, &res)
+
+		// b = b + z * s
+		ScalarMultScalarAddScalar(&b, &z, &ps.s, &b)
+
+		scalars[1+2*i] = z
+		points[1+2*i] = ps.r
+		ScalarMultScalar(&scalars[2+2*i], &z, &h)
+		points[2+2*i] = pks[i].point
+	}
+
+	// b = -(z_1 * s_1 + ... + z_n * s_n)
+	ScalarNeg(&scalars[0], &b)
+
+	// valid if: 8 * sum == I
+	var sum, cSum, I Point
+	MultiScalarMult(&sum, scalarPtrs, pointPtrs)
+	PointClearCofactor(&cSum, &sum)
+	PointIdentity(&I)
+
+	return PointEqual(&cSum, &I)
+}
+
+// VerifyBatchFailures checks the same batch as VerifyBatch, and returns the
+// indexes of the signatures which are invalid, or nil if they are all valid.
+// When the whole batch is valid, this costs the same as VerifyBatch; when it
+// is not, every signature is then checked separately with the cofactored
+// check that VerifyBatch agrees with (see above), so the reported indexes
+// are exactly those which VerifyWithOpts with VerifyOpts{ZIP215: true}
+// rejects. For honestly generated signatures, these are also exactly those
+// which Verify rejects. It panics in the same cases as VerifyBatch.
+func VerifyBatchFailures(pks []*Public, msgs [][]byte, sigs []Signature) []int {
+	if VerifyBatch(pks, msgs, sigs) {
+		return nil
+	}
+
+	var opts = VerifyOpts{ZIP215: true}
+	var failures []int
+	for i, pk := range pks {
+		if !pk.VerifyWithOpts(msgs[i], sigs[i][:], opts) {
+			failures = append(failures, i)
+		}
+	}
+
+	return failures
+}
@@ -0,0 +1,144 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"context"
+	"crypto/sha512"
+	"testing"
+)
+
+// testBatch returns n signatures by different keys on different messages.
+func testBatch(n int) ([]*Public, [][]byte, []Signature) {
+	var pks = make([]*Public, n)
+	var msgs = make([][]byte, n)
+	var sigs = make([]Signature, n)
+	for i := range pks {
+		var sk = testSecret(byte(i + 1))
+		pks[i] = sk.Public()
+		msgs[i] = []byte{byte(i), 'm'}
+		sigs[i] = sk.Sign(msgs[i])
+	}
+	return pks, msgs, sigs
+}
+
+// sigSlices converts signatures to the byte slices VerifyBatchCtx takes.
+func sigSlices(sigs []Signature) [][]byte {
+	var res = make([][]byte, len(sigs))
+	for i := range sigs {
+		res[i] = sigs[i][:]
+	}
+	return res
+}
+
+func TestVerifyBatchCtx(t *testing.T) {
+	var pks, msgs, sigs = testBatch(4)
+	sigs[2][40] ^= 1
+
+	var res, err = VerifyBatchCtx(context.Background(), pks, msgs, sigSlices(sigs))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, ok := range res {
+		if ok != (i != 2) {
+			t.Errorf("signature %d: got %v", i, ok)
+		}
+	}
+
+	var ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+	res, err = VerifyBatchCtx(ctx, pks, msgs, sigSlices(sigs))
+	if err != context.Canceled || res != nil {
+		t.Fatal("cancelled batch:", res, err)
+	}
+
+	if _, err = VerifyBatchCtx(context.Background(), pks, msgs[:3], sigSlices(sigs)); err == nil {
+		t.Fatal("mismatched lengths accepted")
+	}
+}
+
+// torsionSignature returns a signature by sk on msg whose R has a
+// small-order component, which the cofactored check accepts but Verify
+// rejects.
+func torsionSignature(sk *Secret, msg []byte) Signature {
+	var T Point
+	DecompressPoint(&T, &SmallOrderPoints[1])
+
+	// R' = r * G + T
+	var r = Scalar{7}
+	var R Point
+	ScalarMultBase(&R, &r)
+	PointAdd(&R, &R, &T)
+
+	// h = sha512(R's || As || m) % q, s = r + h * a
+	var Rs = PointToKey(&R)
+	var As = sk.Public().Key()
+	var hash = sha512.New()
+	hash.Write(Rs[:])
+	hash.Write(As[:])
+	hash.Write(msg)
+	var res Buffer512
+	hash.Sum(res[:0])
+	var h, s Scalar
+	ScalarReduce512(&h, &res)
+	ScalarMultScalarAddScalar(&s, &h, &sk.scalar, &r)
+
+	var sig Signature
+	copy(sig[:32], Rs[:])
+	copy(sig[32:], s[:])
+	return sig
+}
+
+func TestVerifyBatchTorsion(t *testing.T) {
+	var pks, msgs, sigs = testBatch(4)
+	sigs[1] = torsionSignature(testSecret(2), msgs[1])
+
+	var cofactored = VerifyOpts{ZIP215: true}
+	if pks[1].Verify(msgs[1], sigs[1][:]) {
+		t.Fatal("Verify accepted a signature with a small-order component")
+	}
+	if !pks[1].VerifyWithOpts(msgs[1], sigs[1][:], cofactored) {
+		t.Fatal("cofactored check rejected a signature with a small-order component")
+	}
+
+	// VerifyBatch agrees with the cofactored check, and so does
+	// VerifyBatchFailures
+	if !VerifyBatch(pks, msgs, sigs) {
+		t.Fatal("VerifyBatch disagrees with the cofactored check")
+	}
+	if f := VerifyBatchFailures(pks, msgs, sigs); f != nil {
+		t.Fatal("VerifyBatchFailures reported", f)
+	}
+
+	// with an invalid signature as well, only that one is reported
+	sigs[3][40] ^= 1
+	if VerifyBatch(pks, msgs, sigs) {
+		t.Fatal("VerifyBatch accepted an invalid signature")
+	}
+	if f := VerifyBatchFailures(pks, msgs, sigs); len(f) != 1 || f[0] != 3 {
+		t.Fatal("VerifyBatchFailures reported", f)
+	}
+}
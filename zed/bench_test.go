@@ -0,0 +1,217 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/ed25519"
+	"sync"
+	"testing"
+)
+
+//
+//  Benchmarks of zed side by side with the standard library's crypto/ed25519
+//  on identical inputs, as sub-benchmarks named zed and std, so that
+//
+//    go test -bench . -run XXX ./zed
+//
+//  reports ns/op and allocs/op for both. Operations which have no standard
+//  library equivalent, such as the VRF, are benchmarked for zed only.
+//
+
+var benchSeed = []byte("zed25519 benchmark seed 32 bytes")
+var benchMessage = []byte("zed is pretty cool!")
+
+func BenchmarkKeyFromSeed(b *testing.B) {
+	b.Run("zed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			SecretFromSeed(benchSeed).Public()
+		}
+	})
+	b.Run("std", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ed25519.NewKeyFromSeed(benchSeed)
+		}
+	})
+}
+
+func BenchmarkSign(b *testing.B) {
+	var sk = SecretFromSeed(benchSeed)
+	var stdSk = ed25519.NewKeyFromSeed(benchSeed)
+
+	b.Run("zed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sk.Sign(benchMessage)
+		}
+	})
+	b.Run("std", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ed25519.Sign(stdSk, benchMessage)
+		}
+	})
+}
+
+func BenchmarkVerify(b *testing.B) {
+	var sk = SecretFromSeed(benchSeed)
+	var pk = sk.Public()
+	var sig = sk.Sign(benchMessage)
+	var stdSk = ed25519.NewKeyFromSeed(benchSeed)
+	var stdPk = stdSk.Public().(ed25519.PublicKey)
+	var stdSig = ed25519.Sign(stdSk, benchMessage)
+
+	b.Run("zed", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			pk.Verify(benchMessage, sig[:])
+		}
+	})
+	b.Run("zed-precomputed", func(b *testing.B) {
+		var pk = sk.Public()
+		pk.Precompute()
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			pk.Verify(benchMessage, sig[:])
+		}
+	})
+	b.Run("std", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			ed25519.Verify(stdPk, benchMessage, stdSig)
+		}
+	})
+}
+
+func BenchmarkVrfEval(b *testing.B) {
+	var sk = SecretFromSeed(benchSeed)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		sk.VrfEval(benchMessage)
+	}
+}
+
+func BenchmarkVrfVerify(b *testing.B) {
+	var sk = SecretFromSeed(benchSeed)
+	var pk = sk.Public()
+	var _, proof = sk.VrfEval(benchMessage)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		pk.VrfVerify(benchMessage, proof[:])
+	}
+}
+
+// Sinks for benchmark results, so that the compiler cannot optimise the
+// allocation of results away.
+var (
+	benchSecretSink *Secret
+	benchPublicSink *Public
+)
+
+// BenchmarkDerive compares Derive, which allocates each child, with
+// DeriveInto, which reuses one.
+func BenchmarkDerive(b *testing.B) {
+	var sk = SecretFromSeed(benchSeed)
+	var pk = sk.Public()
+	var index = []byte("index")
+
+	b.Run("Secret.Derive", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchSecretSink = sk.Derive(index, nil)
+		}
+	})
+	b.Run("Secret.DeriveInto", func(b *testing.B) {
+		var child Secret
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			sk.DeriveInto(&child, index, nil)
+		}
+	})
+	b.Run("Public.Derive", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			benchPublicSink = pk.Derive(index)
+		}
+	})
+	b.Run("Public.DeriveInto", func(b *testing.B) {
+		var child Public
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			pk.DeriveInto(&child, index)
+		}
+	})
+}
+
+// BenchmarkBasePoint compares the first call of BasePoint, which computes the
+// base point, with later calls, which copy it.
+func BenchmarkBasePoint(b *testing.B) {
+	var P Point
+	b.Run("first", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			basePointOnce = sync.Once{}
+			BasePoint(&P)
+		}
+	})
+	b.Run("cached", func(b *testing.B) {
+		BasePoint(&P)
+		b.ReportAllocs()
+		b.ResetTimer()
+		for i := 0; i < b.N; i++ {
+			BasePoint(&P)
+		}
+	})
+}
+
+// BenchmarkVerifyChildren compares checking 32 children one at a time with
+// checking them with VerifyChildrenBatch.
+func BenchmarkVerifyChildren(b *testing.B) {
+	var pk = SecretFromSeed(benchSeed).Public()
+	var indices = make([][]byte, 32)
+	var children = make([]*Public, 32)
+	for i := range indices {
+		indices[i] = []byte{'c', byte(i)}
+		children[i] = pk.Derive(indices[i])
+	}
+
+	b.Run("single", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			for j := range indices {
+				ProveDerivation(pk, children[j], indices[j])
+			}
+		}
+	})
+	b.Run("batch", func(b *testing.B) {
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			VerifyChildrenBatch(pk, indices, children)
+		}
+	})
+}
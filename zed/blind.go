@@ -0,0 +1,236 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+)
+
+//
+//  Blind Schnorr signatures let a requester get a signature on a message
+//  from a signer, without the signer learning the message, or being able to
+//  link the signature it later sees to the session which produced it. The
+//  result is an ordinary Ed25519 signature, which Verify accepts.
+//
+//    signer:     r random, R = r * G                   -> R     (Commit)
+//    requester:  alpha, beta random
+//                R' = R + alpha * G + beta * A
+//                c' = sha512(Rs' || As || m) % q
+//                c = (c' + beta) % q                   -> c     (Challenge)
+//    signer:     s = (r + c * a) % q                   -> s     (Sign)
+//    requester:  check s * G == R + c * A
+//                sig = Rs' || (s + alpha) % q                   (Unblind)
+//
+//  Then s' * G = R + c * A + alpha * G = R' + c' * A, which is the Ed25519
+//  verification equation for (R', s') on m. Since alpha and beta are uniform,
+//  (R', c') is independent of everything the signer saw.
+//
+//  Each side of a session is a state machine which only moves forward, and
+//  a BlindSigner refuses to answer more than one challenge for its nonce:
+//  two answers s1, s2 for challenges c1, c2 would reveal the secret scalar
+//  a = (s1 - s2) / (c1 - c2). Nonces are drawn from the system's random
+//  number generator (hedged with the secret prefix), never derived from
+//  anything the requester controls.
+//
+//  WARNING: a signer which runs many sessions concurrently (about 256 or
+//  more, with the challenges chosen after all commitments are out) can be
+//  made to produce one more signature than the sessions it completed, by
+//  the ROS attack. Signers should bound the number of open sessions, or
+//  complete them one at a time, where one-more unforgeability matters.
+//
+//  REFERENCES:
+//    [1] David Chaum, "Blind Signatures for Untraceable Payments"
+//    [2] Fabrice Benhamouda, Tancrede Lepoint, Julian Loss, Michele Orru,
+//        Mariana Raykova, "On the (in)security of ROS"
+//        https://eprint.iacr.org/2020/945
+//
+
+// BlindSigner is the signer's state in a blind signing session, which moves
+// through Commit and Sign, in that order. A session produces one signature;
+// start a new session for every signature.
+type BlindSigner struct {
+	sk    *Secret
+	r     Scalar
+	round int
+}
+
+// NewBlindSigner starts a blind signing session for the signer sk.
+func NewBlindSigner(sk *Secret) *BlindSigner {
+	return &BlindSigner{sk: sk}
+}
+
+// Commit picks the signer's nonce, and returns the nonce point R which must
+// be sent to the requester.
+func (bs *BlindSigner) Commit() (Buffer256, error) {
+	if bs.round != 0 {
+		return Buffer256{}, errors.New("BlindSigner.Commit: wrong round")
+	}
+
+	// r = sha512(nonce_str || p || random) % q
+	var random Buffer256
+	if _, err := rand.Read(random[:]); err != nil {
+		return Buffer256{}, err
+	}
+	var hash = sha512.New()
+	var res Buffer512
+	hash.Write([]byte("zed25519_blind_nonce"))
+	hash.Write(bs.sk.prefix[:])
+	hash.Write(random[:])
+	hash.Sum(res[:0])
+	ScalarReduce512(&bs.r, &res)
+	wipe(res[:])
+
+	// Rs = compress(r * G)
+	var R Point
+	ScalarMultBase(&R, &bs.r)
+
+	bs.round = 1
+	return PointToKey(&R), nil
+}
+
+// Sign answers the requester's blinded challenge c with s = (r + c * a) % q,
+// which must be sent back to the requester. It can only be called once per
+// session, since a second answer would reveal the secret key.
+func (bs *BlindSigner) Sign(challenge Scalar) (Scalar, error) {
+	if bs.round != 1 {
+		return Scalar{}, errors.New("BlindSigner.Sign: wrong round")
+	}
+	if !ValidScalar(&challenge) {
+		return Scalar{}, errors.New("BlindSigner.Sign: invalid challenge")
+	}
+	bs.round = 2
+
+	// s = (r + ca) % q
+	var s Scalar
+	ScalarMultScalarAddScalar(&s, &challenge, &bs.sk.scalar, &bs.r)
+
+	// forget the nonce, it must never be used again
+	wipe(bs.r[:])
+
+	return s, nil
+}
+
+// BlindRequester is the requester's state in a blind signing session, which
+// moves through Challenge and Unblind, in that order.
+type BlindRequester struct {
+	pk  *Public
+	msg []byte
+
+	alpha Scalar
+	r     Point
+	rs    Buffer256
+	c     Scalar
+	round int
+}
+
+// NewBlindRequester starts a blind signing session for a signature by pk on
+// msg. The message is never sent to the signer.
+func NewBlindRequester(pk *Public, msg []byte) *BlindRequester {
+	return &BlindRequester{pk: pk, msg: msg}
+}
+
+// Challenge blinds the signer's nonce point and the message, and returns the
+// blinded challenge c which must be sent to the signer.
+func (br *BlindRequester) Challenge(commitment Buffer256) (Scalar, error) {
+	if br.round != 0 {
+		return Scalar{}, errors.New("BlindRequester.Challenge: wrong round")
+	}
+
+	// R = decompress(commitment), or fail
+	if !DecompressPoint(&br.r, &commitment) || !br.r.IsTorsionFree() {
+		return Scalar{}, errors.New("BlindRequester.Challenge: invalid commitment")
+	}
+
+	// alpha, beta random
+	var buf Buffer512
+	var beta Scalar
+	if _, err := rand.Read(buf[:]); err != nil {
+		return Scalar{}, err
+	}
+	ScalarReduce512(&br.alpha, &buf)
+	if _, err := rand.Read(buf[:]); err != nil {
+		return Scalar{}, err
+	}
+	ScalarReduce512(&beta, &buf)
+
+	// R' = R + alpha * G + beta * A
+	var aG, bA, Rp Point
+	var A = br.pk.Point()
+	ScalarMultBase(&aG, &br.alpha)
+	ScalarMultPoint(&bA, &beta, &A)
+	PointAdd(&Rp, &br.r, &aG)
+	PointAdd(&Rp, &Rp, &bA)
+	CompressPoint(&br.rs, &Rp)
+
+	// c' = sha512(Rs' || As || m) % q
+	var hash = sha512.New()
+	var res Buffer512
+	var As = br.pk.Key()
+	var cp Scalar
+	hash.Write(br.rs[:])
+	hash.Write(As[:])
+	hash.Write(br.msg)
+	hash.Sum(res[:0])
+	ScalarReduce512(&cp, &res)
+
+	// c = (c' + beta) % q
+	ScalarMultScalarAddScalar(&br.c, &scalarOne, &cp, &beta)
+	wipe(beta[:])
+
+	br.round = 1
+	return br.c, nil
+}
+
+// Unblind checks the signer's answer s against the blinded challenge, and
+// turns it into an Ed25519 signature by pk on the message, which Verify
+// accepts. An error is returned if the signer's answer is not valid.
+func (br *BlindRequester) Unblind(s Scalar) (Signature, error) {
+	if br.round != 1 {
+		return Signature{}, errors.New("BlindRequester.Unblind: wrong round")
+	}
+	if !ValidScalar(&s) {
+		return Signature{}, errors.New("BlindRequester.Unblind: invalid response")
+	}
+	br.round = 2
+
+	// valid if: s * G == R + c * A
+	var ps = &ParsedSignature{r: br.r, s: s}
+	if !br.pk.verifyChallenge(ps, &br.c) {
+		return Signature{}, errors.New("BlindRequester.Unblind: invalid response")
+	}
+
+	// sig = Rs' || (s + alpha) % q
+	var sp Scalar
+	ScalarMultScalarAddScalar(&sp, &scalarOne, &s, &br.alpha)
+	wipe(br.alpha[:])
+
+	var sig Signature
+	copy(sig[:32], br.rs[:])
+	copy(sig[32:], sp[:])
+	return sig, nil
+}
@@ -0,0 +1,143 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"bytes"
+	"testing"
+)
+
+// testBlindSession runs a blind signing session between sk and a requester
+// for msg, returning the unblinded signature and the values the signer saw.
+func testBlindSession(t *testing.T, sk *Secret, msg []byte) (Signature, Buffer256, Scalar) {
+	var bs = NewBlindSigner(sk)
+	var br = NewBlindRequester(sk.Public(), msg)
+
+	var R, err = bs.Commit()
+	if err != nil {
+		t.Fatal(err)
+	}
+	c, err := br.Challenge(R)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s, err := bs.Sign(c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sig, err := br.Unblind(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sig, R, c
+}
+
+func TestBlind(t *testing.T) {
+	var sk = testSecret(2)
+	var msg = []byte("blind message")
+	var sig, R, _ = testBlindSession(t, sk, msg)
+
+	if !sk.Public().Verify(msg, sig[:]) {
+		t.Fatal("unblinded signature does not verify")
+	}
+	if sk.Public().Verify([]byte("another message"), sig[:]) {
+		t.Error("unblinded signature verifies another message")
+	}
+
+	// the signer never sees the final nonce point
+	if bytes.Equal(sig[:32], R[:]) {
+		t.Error("signature nonce is the signer's commitment")
+	}
+
+	// two sessions on the same message give unrelated signatures
+	var sig2, R2, _ = testBlindSession(t, sk, msg)
+	if R2 == R || bytes.Equal(sig2[:32], sig[:32]) {
+		t.Error("two sessions re-use a nonce")
+	}
+}
+
+func TestBlindDoubleAnswer(t *testing.T) {
+	var sk = testSecret(2)
+	var bs = NewBlindSigner(sk)
+	var R, _ = bs.Commit()
+
+	var c1, _ = NewBlindRequester(sk.Public(), []byte("one")).Challenge(R)
+	var c2, _ = NewBlindRequester(sk.Public(), []byte("two")).Challenge(R)
+	if _, err := bs.Sign(c1); err != nil {
+		t.Fatal(err)
+	}
+
+	// a second answer for the same nonce would reveal the secret key
+	if _, err := bs.Sign(c2); err == nil {
+		t.Error("BlindSigner answered a second challenge")
+	}
+	if _, err := bs.Commit(); err == nil {
+		t.Error("BlindSigner committed again after signing")
+	}
+
+	var fresh = NewBlindSigner(sk)
+	if _, err := fresh.Sign(c1); err == nil {
+		t.Error("BlindSigner answered before committing")
+	}
+	fresh.Commit()
+	var invalid Scalar
+	for i := range invalid {
+		invalid[i] = 0xff
+	}
+	if _, err := fresh.Sign(invalid); err == nil {
+		t.Error("BlindSigner answered a non-canonical challenge")
+	}
+}
+
+func TestBlindBadResponse(t *testing.T) {
+	var sk = testSecret(2)
+	var bs = NewBlindSigner(sk)
+	var br = NewBlindRequester(sk.Public(), []byte("blind message"))
+
+	var R, _ = bs.Commit()
+	var c, _ = br.Challenge(R)
+	var s, _ = bs.Sign(c)
+	s[0] ^= 1
+	if _, err := br.Unblind(s); err == nil {
+		t.Error("Unblind accepted a wrong response")
+	}
+	if _, err := br.Unblind(s); err == nil {
+		t.Error("Unblind ran twice")
+	}
+
+	// commitments must be valid points of the prime-order subgroup
+	var T Point
+	DecompressPoint(&T, &SmallOrderPoints[1])
+	var Rpt, mixed Point
+	DecompressPoint(&Rpt, &R)
+	PointAdd(&mixed, &Rpt, &T)
+	for _, commitment := range []Buffer256{{2}, PointToKey(&mixed)} {
+		var br = NewBlindRequester(sk.Public(), []byte("blind message"))
+		if _, err := br.Challenge(commitment); err == nil {
+			t.Errorf("Challenge accepted commitment %x", commitment)
+		}
+	}
+}
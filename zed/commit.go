@@ -0,0 +1,106 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha512"
+)
+
+//
+//  Commit-reveal signing splits Sign into its two halves, for multi-party and
+//  commit-reveal protocols in which the challenge is not simply the hash of
+//  the signer's own R, public key and message:
+//
+//    commit:   r = sha512(commit_str || p || m) % q,  R = r * G
+//    respond:  s = (r + c * a) % q
+//
+//  When the challenge c is computed as sha512(Rs || As || m) % q, the pair
+//  (R, s) is an ordinary Ed25519 signature. The nonce r is derived with a
+//  different domain from the one used by Sign, so that committing to a
+//  message never re-uses the nonce of a normal signature on it.
+//
+//  WARNING: the nonce is deterministic, so two commitments to the same message
+//  use the same r. Completing them with two different challenges reveals the
+//  secret scalar (a = (s1 - s2) / (c1 - c2)). Protocols in which the challenge
+//  depends on other parties' input must never complete a commitment to the
+//  same message twice; each state can only be completed once.
+//
+
+// signState holds the secret nonce of a commitment made by NonceCommitment,
+// until it is completed.
+type signState struct {
+	r    Scalar
+	a    Scalar
+	used bool
+}
+
+// NonceCommitment makes the commitment R = r * G for a signature on msg,
+// returning R in compressed form, and the secret state needed to complete the
+// signature once the challenge is known.
+func (sk *Secret) NonceCommitment(msg []byte) (commitment Buffer256, state *signState) {
+
+	// sha512 instance, result buffer
+	var hash = sha512.New()
+	var res Buffer512
+
+	// Take private scalar "a" and prefix "p" from Secret
+	var p = sk.Prefix()
+	state = &signState{a: sk.Scalar()}
+
+	// r = sha512(commit_str || p || m) % q
+	hash.Write([]byte("zed25519_nonce_commitment"))
+	hash.Write(p[:])
+	hash.Write(msg)
+	hash.Sum(res[:0])
+	ScalarReduce512(&state.r, &res)
+
+	// R = r * G
+	var R Point
+	ScalarMultBase(&R, &state.r)
+	CompressPoint(&commitment, &R)
+
+	return commitment, state
+}
+
+// Complete computes the response s = (r + challenge * a) % q for the
+// commitment the state was created with. It panics if the state has already
+// been completed once, since a second response would reveal the secret key.
+func (st *signState) Complete(challenge *Scalar) Scalar {
+	if st.used {
+		panic("Complete: signing state already used")
+	}
+	st.used = true
+
+	// s = (r + ca) % q
+	var s Scalar
+	ScalarMultScalarAddScalar(&s, challenge, &st.a, &st.r)
+
+	// forget the secrets, they are not needed again
+	st.r = Scalar{}
+	st.a = Scalar{}
+
+	return s
+}
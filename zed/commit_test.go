@@ -0,0 +1,75 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha512"
+	"testing"
+)
+
+func TestNonceCommitment(t *testing.T) {
+	var sk = testSecret(15)
+	var pk = sk.Public()
+	var msg = []byte("commit, then reveal")
+
+	// commit
+	var Rs, state = sk.NonceCommitment(msg)
+	var again, _ = sk.NonceCommitment(msg)
+	if again != Rs {
+		t.Error("NonceCommitment is not deterministic")
+	}
+	var plain = sk.Sign(msg)
+	if string(plain[:32]) == string(Rs[:]) {
+		t.Error("NonceCommitment reuses the nonce of Sign")
+	}
+
+	// c = sha512(Rs || As || m) % q
+	var As = pk.Key()
+	var hash = sha512.New()
+	var res Buffer512
+	var c Scalar
+	hash.Write(Rs[:])
+	hash.Write(As[:])
+	hash.Write(msg)
+	hash.Sum(res[:0])
+	ScalarReduce512(&c, &res)
+
+	// respond, and the result is an ordinary signature
+	var s = state.Complete(&c)
+	var sig Signature
+	copy(sig[:32], Rs[:])
+	copy(sig[32:], s[:])
+	if !pk.Verify(msg, sig[:]) {
+		t.Error("completed commitment does not verify")
+	}
+	if pk.Verify([]byte("other"), sig[:]) {
+		t.Error("completed commitment verifies on another message")
+	}
+
+	expectPanic(t, "second Complete", func() {
+		state.Complete(&c)
+	})
+}
@@ -26,7 +26,14 @@
 package zed
 
 import (
+	"context"
 	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"hash"
+	"strconv"
+	"sync"
 
 	"golang.org/x/crypto/sha3"
 )
@@ -39,6 +46,70 @@ import (
 //
 //  NOTE: Uses SHA3 functions instead of SHA256/SHA512
 //
+//  The blind each child is derived with is computed by a versioned scheme, so
+//  that new schemes can be added while keys derived by the old ones remain
+//  reachable. Each entry point uses one scheme, or takes it as a parameter:
+//
+//    DerivationV1: Derive, DeriveInto, VerifyChild, ProveDerivation,
+//                  ProveDerivationBatch and VerifyChildrenBatch, which all
+//                  keep the scheme of the first release of this package
+//    DerivationV3: DeriveIterator, DeriveRangeCtx, DerivePath, the extended
+//                  keys, SuiteSigner.Derive, SuiteVerifier.Derive and VrfTag
+//    any scheme:   DeriveVersion, VerifyChildVersion, ProveDerivationVersion,
+//                  ProveDerivationBatchVersion and VerifyChildrenBatchVersion
+//
+//  The schemes are:
+//
+//    DerivationV1: key   = sha3_512(public_str || pubkey), or
+//                          sha3_512(private_str || scalar || skey)
+//                  blind = sha3_512(key || index) % q
+//
+//    DerivationV2: key   = KMAC256(pubkey, "", 512, public_str), or
+//                          KMAC256(scalar, skey, 512, private_str)
+//                  blind = KMAC256(key, index, 512, blind_str) % q
+//
+//    DerivationV3: as DerivationV2, with its own blind_str
+//
+//  DerivationV2 uses the real KMAC256 of NIST SP 800-185 (see KMAC256) where
+//  DerivationV1 uses a plain keyed hash in its place. Each scheme derives
+//  different, unrelated children for the same parent and index.
+//
+//  The child keys are then the parent keys multiplied by the blind:
+//
+//    a' = blind * a % q
+//    A' = blind * A
+//
+//  and the prefix p' of a child secret key, from which its signature nonces
+//  are computed, is derived from the prefix p of the parent:
+//
+//    DerivationV1, DerivationV2: p' = sha512(p || p)[:32]
+//    DerivationV3:               p' = sha512(prefix_str || p || blind)[:32]
+//
+//  WARNING: with DerivationV1 and DerivationV2, every child of a parent has
+//  the same prefix, whatever its index, so two children which sign the same
+//  message use the same nonce r, and their signatures have the same R. For
+//  children derived without a skey, the blinds b1 and b2 are public, and the
+//  two signatures (R, s1) and (R, s2) then reveal the parent scalar:
+//
+//    a = (s1 - s2) / (h1 * b1 - h2 * b2) % q
+//
+//  and with it every child key. These schemes are kept unchanged only so
+//  that existing keys stay reachable: keys derived with them must never sign
+//  the same message twice across siblings. DerivationV3 binds the prefix to
+//  the blind, and so to the index, and does not have this flaw. The
+//  verification functions above which use DerivationV1 can only recognise
+//  children derived with it, so a hierarchy which still relies on them has
+//  this hazard; their Version variants check children of the other schemes.
+//
+//  DerivationV1 and DerivationV2 first clamp the blind like a secret scalar,
+//  which fixes 5 of its bits, and so discards 5 bits of the blind's entropy
+//  at every level of a hierarchy. This gains nothing: A is already in the
+//  prime-order subgroup, and a' is reduced mod q, so it is not clamped
+//  anyway. DerivationV3 uses the reduced blind as it is, keeping its full
+//  entropy at every level, and is the scheme new hierarchies should use. The
+//  older schemes remain available, through Derive and DeriveVersion, so that
+//  keys derived with them stay reachable.
+//
 //  REFERENCES:
 //    [1] Nicholas Hopper
 //        "Proving Security of Tor’s Hidden Service Identity Blinding Protocol"
@@ -48,7 +119,9 @@ import (
 //        "Next-Generation Hidden Services in Tor"
 //        https://gitweb.torproject.org/torspec.git/tree/proposals/224-rend-spec-ng.txt#n2135
 //
-//    [3] KMAC stuff
+//    [3] NIST SP 800-185, "SHA-3 Derived Functions: cSHAKE, KMAC, TupleHash
+//        and ParallelHash"
+//        https://doi.org/10.6028/NIST.SP.800-185
 //
 
 // Derive generates child public key from this public key for a given "index"
@@ -57,22 +130,63 @@ import (
 // index.
 func (pk *Public) Derive(index []byte) *Public {
 	var npk = &Public{}
+	pk.DeriveInto(npk, index)
+	return npk
+}
+
+// DeriveInto is the same as Derive, but writes the child public key into dst
+// instead of allocating a new one, for loops which derive many keys. dst may
+// be pk itself.
+func (pk *Public) DeriveInto(dst *Public, index []byte) {
+	pk.deriveInto(dst, DerivationV1, index)
+}
+
+// deriveInto implements DeriveInto with the derivation scheme v, which must
+// be known.
+func (pk *Public) deriveInto(dst *Public, v DerivationVersion, index []byte) {
 
 	// compute public derivation blind for (pk, index)
 	var pubkey = pk.Key()
-	var blind = derivationBlind(pubkey[:], nil, index, nil)
+	var blind = derivationBlindVersion(v, pubkey[:], nil, index, nil)
 
-	// clamp blind, as per Ed25519 spec
-	blind[0] &= 248
-	blind[31] &= 63
-	blind[31] |= 64
-
-	// TODO: Carefully consider effect of repeatedly applying clamp on each
-	//       multiply (are we losing 3 bits each derivation level?)
+	// clamp blind, as per Ed25519 spec, except for DerivationV3
+	if v != DerivationV3 {
+		ClampScalar(&blind)
+	}
 
 	// A' = h * A
-	ScalarMultPointVartime(&npk.point, &blind, &pk.point)
-	return npk
+	ScalarMultPointVartime(&dst.point, &blind, &pk.point)
+
+	// a precomputed table for the old point no longer applies
+	dst.table.Store((*publicTable)(nil))
+}
+
+// VerifyChild checks whether sig is a valid signature on msg by the child key
+// derived from pk with the given index, without the caller having to derive
+// the child public key first. It is equivalent to pk.Derive(index).Verify.
+//
+// Note that this only works because "public" derivation is linkable: anyone
+// who knows the parent public key and the index can tell that a signature was
+// made by the child. Children derived with a secret skey cannot be verified
+// this way.
+//
+// VerifyChild uses DerivationV1, as Derive does, whose siblings share a
+// prefix (see the warning above). Use VerifyChildVersion for children derived
+// with another scheme.
+func (pk *Public) VerifyChild(index, msg, sig []byte) bool {
+	return pk.VerifyChildVersion(DerivationV1, index, msg, sig)
+}
+
+// VerifyChildVersion is the same as VerifyChild, for the child derived with
+// the scheme v, i.e. it is equivalent to pk.DeriveVersion(v, index) followed
+// by Verify. It returns false if v is unknown.
+func (pk *Public) VerifyChildVersion(v DerivationVersion, index, msg, sig []byte) bool {
+	if !v.known() {
+		return false
+	}
+	var child Public
+	pk.deriveInto(&child, v, index)
+	return child.Verify(msg, sig)
 }
 
 // Derive has two modes for Secret Keys, which we can call "public" derivation
@@ -84,74 +198,549 @@ func (pk *Public) Derive(index []byte) *Public {
 // identified with a parent public key.
 func (sk *Secret) Derive(index, skey []byte) *Secret {
 	var nsk = &Secret{}
+	sk.DeriveInto(nsk, index, skey)
+	return nsk
+}
+
+// DeriveInto is the same as Derive, but writes the child secret key into dst
+// instead of allocating a new one, for loops which derive many keys. dst may
+// be sk itself.
+func (sk *Secret) DeriveInto(dst *Secret, index, skey []byte) {
+	sk.deriveInto(dst, DerivationV1, index, skey)
+}
+
+// deriveInto implements DeriveInto with the derivation scheme v, which must
+// be known.
+func (sk *Secret) deriveInto(dst *Secret, v DerivationVersion, index, skey []byte) {
 
 	// compute derivation blind
 	var blind Scalar
 	if skey == nil {
-		var pubkey = sk.Public().Key()
-		blind = derivationBlind(pubkey[:], nil, index, nil)
+		// As = compress(a * G), without allocating a Public
+		var A Point
+		var As Buffer256
+		ScalarMultBase(&A, &sk.scalar)
+		CompressPoint(&As, &A)
+		blind = derivationBlindVersion(v, As[:], nil, index, nil)
 	} else {
-		var scalar = sk.Scalar()
-		blind = derivationBlind(nil, scalar[:], index, skey)
+		blind = derivationBlindVersion(v, nil, sk.scalar[:], index, skey)
 	}
 
-	// clamp blind, as per Ed25519 spec
-	blind[0] &= 248
-	blind[31] &= 63
-	blind[31] |= 64
+	// clamp blind, as per Ed25519 spec, except for DerivationV3
+	if v != DerivationV3 {
+		ClampScalar(&blind)
+	}
 
-	// TODO: Carefully consider effect of repeatedly applying clamp on each
-	//       multiply (are we losing 3 bits each derivation level?)
+	// p' = prefix of the child, computed before dst.prefix is overwritten,
+	// since dst may be sk
+	var prefix = derivationPrefixVersion(v, &sk.prefix, &blind)
 
 	// a' = h * a
-	ScalarMultScalar(&nsk.scalar, &blind, &sk.scalar)
+	ScalarMultScalar(&dst.scalar, &blind, &sk.scalar)
+	wipe(blind[:])
+
+	dst.prefix = prefix
+	wipe(prefix[:])
+
+	// a derived key has no seed
+	dst.seed = Buffer256{}
+	dst.hasSeed = false
+	dst.derived = true
+}
+
+// DerivationVersion identifies a scheme for computing derivation blinds, as
+// described above.
+type DerivationVersion byte
+
+// Derivation schemes. DerivationV1 is the scheme of Derive, and DerivationV3
+// the recommended scheme for new hierarchies.
+const (
+	DerivationV1 DerivationVersion = 1
+	DerivationV2 DerivationVersion = 2
+	DerivationV3 DerivationVersion = 3
+)
+
+// known reports whether v is a known derivation scheme.
+func (v DerivationVersion) known() bool {
+	return v == DerivationV1 || v == DerivationV2 || v == DerivationV3
+}
+
+// DeriveVersion is the same as Derive, but derives the child public key with
+// the derivation scheme v. It returns an error if v is unknown.
+func (pk *Public) DeriveVersion(v DerivationVersion, index []byte) (*Public, error) {
+	if !v.known() {
+		return nil, errors.New("DeriveVersion: unknown version: " + strconv.Itoa(int(v)))
+	}
+	var npk = &Public{}
+	pk.deriveInto(npk, v, index)
+	return npk, nil
+}
+
+// DeriveVersion is the same as Derive, but derives the child secret key with
+// the derivation scheme v. It returns an error if v is unknown.
+func (sk *Secret) DeriveVersion(v DerivationVersion, index, skey []byte) (*Secret, error) {
+	if !v.known() {
+		return nil, errors.New("DeriveVersion: unknown version: " + strconv.Itoa(int(v)))
+	}
+	var nsk = &Secret{}
+	sk.deriveInto(nsk, v, index, skey)
+	return nsk, nil
+}
+
+// derivationPrefix computes the prefix of a child secret key from the prefix
+// of its parent.
+func derivationPrefix(prefix *Buffer256) Buffer256 {
 
-	// TODO: considering removing "prefix" entirely for simplicity, if secure.
 	// (prefix' || _) = sha512(prefix || p)
 	var hash = sha512.New()
 	var res Buffer512
-	var prefix = sk.Prefix()
-	hash.Reset()
-	hash.Write(sk.prefix[:])
 	hash.Write(prefix[:])
+	hash.Write(prefix[:])
+	hash.Sum(res[:0])
+
+	var nprefix Buffer256
+	copy(nprefix[:], res[:32])
+	return nprefix
+}
+
+// derivationPrefixVersion computes the prefix of a child secret key derived
+// with the scheme v and the blind, from the prefix of its parent. Only
+// DerivationV3 uses the blind; see the warning above.
+func derivationPrefixVersion(v DerivationVersion, prefix *Buffer256, blind *Scalar) Buffer256 {
+	if v != DerivationV3 {
+		return derivationPrefix(prefix)
+	}
+
+	// (prefix' || _) = sha512(prefix_str || prefix || blind)
+	var hash = sha512.New()
+	var res Buffer512
+	hash.Write([]byte("zed25519_derive_prefix"))
+	hash.Write(prefix[:])
+	hash.Write(blind[:])
 	hash.Sum(res[:0])
-	copy(nsk.prefix[:], res[:32])
+
+	var nprefix Buffer256
+	copy(nprefix[:], res[:32])
+	wipe(res[:])
+	return nprefix
+}
+
+// DeriveIterator returns a function which, on each call, returns the next
+// child of sk by public derivation, for the indexes prefix || 0, prefix || 1,
+// prefix || 2 and so on, where the counter is an 8-byte big-endian integer.
+// The parent's public key and derivation key are only computed once, and no
+// keys are generated until they are asked for, so an unbounded sequence of
+// keys can be enumerated cheaply.
+//
+// The keys returned are NOT those of Derive: each one is the same as calling
+// DeriveVersion(DerivationV3, index, nil) with the corresponding index,
+// whereas Derive uses DerivationV1. A wallet scan may hand out many siblings,
+// and with DerivationV1 any two of them signing the same message would reveal
+// the parent key (see the warning above), so the iterator does not offer it.
+// Their public keys are found with Public.DeriveVersion(DerivationV3, index),
+// not Public.Derive.
+func (sk *Secret) DeriveIterator(prefix []byte) func() *Secret {
+
+	// key = derivation key of parent, shared by every index
+	var pubkey = sk.Public().Key()
+	var key = derivationKeyVersion(DerivationV3, pubkey[:], nil, nil)
+
+	var counter uint64
+	return func() *Secret {
+		var nsk = sk.deriveFromKey(&key, counterIndex(prefix, counter))
+		counter++
+		return nsk
+	}
+}
+
+// deriveFromKey derives the child of sk by public derivation with
+// DerivationV3 for index, given the derivation key of sk.
+func (sk *Secret) deriveFromKey(key *Buffer512, index []byte) *Secret {
+	var nsk = &Secret{derived: true}
+
+	// blind = blind(key, index), unclamped as in DerivationV3
+	var blind = derivationBlindFromKeyVersion(DerivationV3, key, index)
+
+	// a' = h * a, p' = prefix(p, blind)
+	ScalarMultScalar(&nsk.scalar, &blind, &sk.scalar)
+	nsk.prefix = derivationPrefixVersion(DerivationV3, &sk.prefix, &blind)
+	wipe(blind[:])
 
 	return nsk
 }
 
+// DeriveRangeCtx derives count children of sk by public derivation, for the
+// indexes prefix || start up to prefix || (start + count - 1), exactly as
+// DeriveIterator would enumerate them: with DerivationV3, not the
+// DerivationV1 of Derive, so that each child has its own prefix. The context is checked before each key is derived, and
+// if it has been cancelled, no keys are returned along with ctx.Err(), so
+// that a server can abandon a large derivation when the request behind it
+// goes away.
+func (sk *Secret) DeriveRangeCtx(ctx context.Context, prefix []byte, start, count uint64) ([]*Secret, error) {
+
+	// key = derivation key of parent, shared by every index
+	var pubkey = sk.Public().Key()
+	var key = derivationKeyVersion(DerivationV3, pubkey[:], nil, nil)
+
+	// don't trust count for the initial allocation, since the context may
+	// well be cancelled long before that many keys are derived
+	var n = count
+	if n > 1024 {
+		n = 1024
+	}
+
+	var res = make([]*Secret, 0, n)
+	for i := uint64(0); i < count; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		res = append(res, sk.deriveFromKey(&key, counterIndex(prefix, start+i)))
+	}
+
+	return res, nil
+}
+
+// DeriveSeed derives an independent 32-byte seed for the subsystem named by
+// label from a master seed, suitable for SecretFromSeed. Unlike Derive, which
+// produces child keys that have no seed, the keypairs created from derived
+// seeds are ordinary seed-based keys, which can be backed up and exported in
+// every format which needs a seed. Seeds derived for different labels are
+// independent: none of them reveals anything about the others, or about the
+// master seed.
+//
+//   seed = sha3_256(seed_str || EncodeIndex(masterSeed, label))
+func DeriveSeed(masterSeed []byte, label string) []byte {
+	var hash = sha3.New256()
+	hash.Write([]byte("zed25519_derivation_seed"))
+	hash.Write(EncodeIndex(masterSeed, []byte(label)))
+	return hash.Sum(nil)
+}
+
+// counterIndex builds the derivation index prefix || counter, with counter
+// encoded as an 8-byte big-endian integer.
+func counterIndex(prefix []byte, counter uint64) []byte {
+	var index = make([]byte, len(prefix)+8)
+	copy(index, prefix)
+	binary.BigEndian.PutUint64(index[len(prefix):], counter)
+	return index
+}
+
+// VerifyChildrenBatch checks, for each i, whether children[i] is the public
+// key derived from parent with indices[i], i.e. whether it equals
+// parent.Derive(indices[i]). This is what a watch-only wallet needs when
+// scanning for its addresses. The derivation key of the parent is computed
+// only once for the whole batch, rather than once per child as it would be
+// with repeated calls to Derive, and for batches of more than a few
+// children, a table of multiples of the parent point is built once (as
+// Precompute does, unless the parent already has one) and shared by every
+// child, which makes each h * A many times cheaper. A nil child is reported
+// as not derived, and if the two slices have different lengths, every child
+// is.
+//
+// VerifyChildrenBatch uses DerivationV1, as Derive does, whose siblings share
+// a prefix (see the warning above). Use VerifyChildrenBatchVersion for
+// children derived with another scheme, such as those of DeriveIterator.
+func VerifyChildrenBatch(parent *Public, indices [][]byte, children []*Public) []bool {
+	return VerifyChildrenBatchVersion(DerivationV1, parent, indices, children)
+}
+
+// VerifyChildrenBatchVersion is the same as VerifyChildrenBatch, for children
+// derived with the scheme v, i.e. it checks whether each children[i] equals
+// parent.DeriveVersion(v, indices[i]). Every child is reported as not
+// derived if v is unknown.
+func VerifyChildrenBatchVersion(v DerivationVersion, parent *Public, indices [][]byte, children []*Public) []bool {
+	if len(indices) != len(children) || !v.known() {
+		return make([]bool, len(children))
+	}
+
+	// A = parent, with a table of its multiples shared by the batch
+	var A = parent
+	if len(indices) >= verifyChildrenTableMin && parent.loadTable() == nil {
+		A = &Public{point: parent.point}
+		A.Precompute()
+	}
+
+	// key = derivation key of parent, shared by every index
+	var pubkey = parent.Key()
+	var key = derivationKeyVersion(v, pubkey[:], nil, nil)
+
+	var res = make([]bool, len(indices))
+	var child Point
+	for i, index := range indices {
+		if children[i] == nil {
+			continue
+		}
+
+		// compute the blind for this index, clamped except for DerivationV3
+		var blind = derivationBlindFromKeyVersion(v, &key, index)
+		if v != DerivationV3 {
+			ClampScalar(&blind)
+		}
+
+		// A' = h * A, valid if A' == child
+		A.scalarMult(&child, &blind)
+		res[i] = PointEqual(&child, &children[i].point)
+	}
+
+	return res
+}
+
+// verifyChildrenTableMin is the smallest batch for which VerifyChildrenBatch
+// builds a table of multiples of the parent point, which costs about as much
+// as three plain multiplications of it.
+const verifyChildrenTableMin = 4
+
+// ErrDerivationMismatch is returned by ProveDerivation and
+// ProveDerivationBatch when a child key is not derived from the parent key
+// with the claimed index.
+var ErrDerivationMismatch = errors.New("zed: child key is not derived from parent")
+
+// ProveDerivation checks that child is the public key derived from parent
+// with index, i.e. that it equals parent.Derive(index), and returns
+// ErrDerivationMismatch if it is not. It needs no secret, so a third party
+// given only the parent public key and the index can independently confirm
+// that a child key was issued under the parent, for example when auditing
+// keys issued from a hierarchy. Children derived with a secret skey cannot
+// be confirmed this way.
+//
+// ProveDerivation uses DerivationV1, as Derive does, whose siblings share a
+// prefix (see the warning above). Use ProveDerivationVersion for children
+// derived with another scheme.
+func ProveDerivation(parent, child *Public, index []byte) error {
+	return ProveDerivationVersion(DerivationV1, parent, child, index)
+}
+
+// ProveDerivationVersion is the same as ProveDerivation, for a child derived
+// with the scheme v, i.e. it checks that child equals
+// parent.DeriveVersion(v, index). It returns an error if v is unknown.
+func ProveDerivationVersion(v DerivationVersion, parent, child *Public, index []byte) error {
+	if !v.known() {
+		return errors.New("ProveDerivation: unknown version: " + strconv.Itoa(int(v)))
+	}
+	var derived Public
+	parent.deriveInto(&derived, v, index)
+	if !PointEqual(&derived.point, &child.point) {
+		return ErrDerivationMismatch
+	}
+	return nil
+}
+
+// ProveDerivationBatch checks, as ProveDerivation does, that each children[i]
+// is derived from parent with indices[i], computing the derivation key of the
+// parent only once as VerifyChildrenBatch does. It returns an error wrapping
+// ErrDerivationMismatch and naming the first child which is not, or an error
+// if the two slices have different lengths. It uses DerivationV1, as
+// ProveDerivation does.
+func ProveDerivationBatch(parent *Public, indices [][]byte, children []*Public) error {
+	return ProveDerivationBatchVersion(DerivationV1, parent, indices, children)
+}
+
+// ProveDerivationBatchVersion is the same as ProveDerivationBatch, for
+// children derived with the scheme v. It returns an error if v is unknown.
+func ProveDerivationBatchVersion(v DerivationVersion, parent *Public, indices [][]byte, children []*Public) error {
+	if !v.known() {
+		return errors.New("ProveDerivationBatch: unknown version: " + strconv.Itoa(int(v)))
+	}
+	if len(indices) != len(children) {
+		return errors.New("ProveDerivationBatch: mismatched lengths")
+	}
+	for i, ok := range VerifyChildrenBatchVersion(v, parent, indices, children) {
+		if !ok {
+			return fmt.Errorf("ProveDerivationBatch: child %d: %w", i, ErrDerivationMismatch)
+		}
+	}
+	return nil
+}
+
+// EncodeIndex builds a single derivation index out of several logical parts,
+// such that no two different lists of parts can produce the same index. Each
+// part is written as its length, encoded as a 4-byte big-endian integer,
+// followed by the part itself:
+//
+//   index = len(part_1) || part_1 || ... || len(part_n) || part_n
+//
+// Naively concatenating parts would make ("1", "23") and ("12", "3") both
+// become "123", and therefore derive the same child key. Multi-part indexes
+// should always be built with EncodeIndex instead.
+func EncodeIndex(parts ...[]byte) []byte {
+	var n = 0
+	for _, part := range parts {
+		n += 4 + len(part)
+	}
+
+	var index = make([]byte, 0, n)
+	var l [4]byte
+	for _, part := range parts {
+		binary.BigEndian.PutUint32(l[:], uint32(len(part)))
+		index = append(index, l[:]...)
+		index = append(index, part...)
+	}
+
+	return index
+}
+
 // DerivationBlind is used to compute the "blind" scalar which both a public
 // and private key are multiplied by to generate the new keypair.
 // If hidden=true, key is expected be the private scalar of the parent
 // keypair, otherwise it is expected to be the serialized public key of the
 // parent keypair.
 func derivationBlind(pubkey, scalar, index, skey []byte) Scalar {
-	var hash = sha3.New512()
+	var st = getDerivationState()
+	st.setKey(pubkey, scalar, skey)
+	var blind = st.blind(index)
+	putDerivationState(st)
+	return blind
+}
+
+// derivationKey computes the kmac key used to derive the blind for each index
+// of a parent keypair. It only depends on the parent, so it can be computed
+// once and shared across many indexes.
+func derivationKey(pubkey, scalar, skey []byte) Buffer512 {
+	var st = getDerivationState()
+	st.setKey(pubkey, scalar, skey)
+	var key = st.key
+	putDerivationState(st)
+	return key
+}
+
+// derivationBlindFromKey computes the blind for an index from the parent's
+// kmac key.
+func derivationBlindFromKey(key *Buffer512, index []byte) Scalar {
+	var st = getDerivationState()
+	st.key = *key
+	var blind = st.blind(index)
+	putDerivationState(st)
+	return blind
+}
+
+// derivationState holds the SHA3-512 state and the buffers of a DerivationV1
+// derivation. The buffers are written to the hash, which makes them escape to
+// the heap, so they are kept with it, and reused from call to call through
+// derivationStates, so that DeriveInto need not allocate them every time.
+type derivationState struct {
+	hash   hash.Hash
+	pubkey Buffer256
+	scalar Scalar
+	key    Buffer512
+	kmac   Buffer512
+}
+
+// Domain strings of the DerivationV1 derivation key, converted once.
+var (
+	derivationPublicStr = []byte("zed25519_derivation_index_public")
+	derivationSecretStr = []byte("zed25519_derivation_index_secret")
+)
+
+var derivationStates = sync.Pool{
+	New: func() interface{} {
+		return &derivationState{hash: sha3.New512()}
+	},
+}
+
+// getDerivationState takes a derivationState from the pool.
+func getDerivationState() *derivationState {
+	return derivationStates.Get().(*derivationState)
+}
+
+// putDerivationState wipes st, and returns it to the pool.
+func putDerivationState(st *derivationState) {
+	st.hash.Reset()
+	wipe(st.scalar[:])
+	wipe(st.key[:])
+	wipe(st.kmac[:])
+	derivationStates.Put(st)
+}
+
+// setKey sets st.key to the derivation key of a parent keypair, as
+// derivationKey computes it.
+func (st *derivationState) setKey(pubkey, scalar, skey []byte) {
+	st.hash.Reset()
 
 	// derive kmac key differently depending on secret or public child
-	var key Buffer512
 	if skey == nil {
 		// key = sha3_512(public_str || pubkey)
-		hash.Write([]byte("zed25519_derivation_index_public"))
-		hash.Write(pubkey)
+		copy(st.pubkey[:], pubkey)
+		st.hash.Write(derivationPublicStr)
+		st.hash.Write(st.pubkey[:])
 	} else {
 		// key = sha3_512(private_str || scalar || skey)
-		hash.Write([]byte("zed25519_derivation_index_secret"))
-		hash.Write(scalar)
-		hash.Write(skey)
+		copy(st.scalar[:], scalar)
+		st.hash.Write(derivationSecretStr)
+		st.hash.Write(st.scalar[:])
+		st.hash.Write(skey)
 	}
-	hash.Sum(key[:0])
+	st.hash.Sum(st.key[:0])
+}
+
+// blind computes the blind for an index from st.key, as
+// derivationBlindFromKey does.
+func (st *derivationState) blind(index []byte) Scalar {
+	st.hash.Reset()
 
 	// kmac = sha3_512(key || index)
-	var kmac Buffer512
-	hash.Reset()
-	hash.Write(key[:])
-	hash.Write(index)
-	hash.Sum(kmac[:0])
+	st.hash.Write(st.key[:])
+	st.hash.Write(index)
+	st.hash.Sum(st.kmac[:0])
 
 	// blind = kmac % q
 	var blind Scalar
+	ScalarReduce512(&blind, &st.kmac)
+
+	return blind
+}
+
+// derivationBlindVersion computes the derivation blind with the scheme v, as
+// derivationBlind does for DerivationV1.
+func derivationBlindVersion(v DerivationVersion, pubkey, scalar, index, skey []byte) Scalar {
+	if v == DerivationV1 {
+		return derivationBlind(pubkey, scalar, index, skey)
+	}
+	var key = derivationKeyVersion(v, pubkey, scalar, skey)
+	var blind = derivationBlindFromKeyVersion(v, &key, index)
+	wipe(key[:])
+	return blind
+}
+
+// derivationKeyVersion computes the derivation key of a parent keypair with
+// the scheme v, as derivationKey does for DerivationV1.
+func derivationKeyVersion(v DerivationVersion, pubkey, scalar, skey []byte) Buffer512 {
+	if v == DerivationV1 {
+		return derivationKey(pubkey, scalar, skey)
+	}
+
+	// key = KMAC256(pubkey, "", 512, public_str), or
+	//       KMAC256(scalar, skey, 512, private_str)
+	var kmac []byte
+	if skey == nil {
+		kmac = KMAC256(pubkey, nil, 64, []byte("zed25519_derivation_index_public"))
+	} else {
+		kmac = KMAC256(scalar, skey, 64, []byte("zed25519_derivation_index_secret"))
+	}
+
+	var key Buffer512
+	copy(key[:], kmac)
+	wipe(kmac)
+	return key
+}
+
+// derivationBlindFromKeyVersion computes the blind for an index from the
+// derivation key of the parent with the scheme v, as derivationBlindFromKey
+// does for DerivationV1. The blind is not clamped.
+func derivationBlindFromKeyVersion(v DerivationVersion, key *Buffer512, index []byte) Scalar {
+	if v == DerivationV1 {
+		return derivationBlindFromKey(key, index)
+	}
+
+	// blind = KMAC256(key, index, 512, blind_str) % q
+	var blindStr = "zed25519_derivation_blind"
+	if v == DerivationV3 {
+		blindStr = "zed25519_derivation_blind_v3"
+	}
+	var kmac Buffer512
+	copy(kmac[:], KMAC256(key[:], index, 64, []byte(blindStr)))
+	var blind Scalar
 	ScalarReduce512(&blind, &kmac)
+	wipe(kmac[:])
 
 	return blind
 }
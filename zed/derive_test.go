@@ -0,0 +1,448 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestDeriveV3SiblingNonces(t *testing.T) {
+	var sk = testSecret(1)
+	var msg = []byte("same message")
+
+	var c1, err1 = sk.DeriveVersion(DerivationV3, []byte("1"), nil)
+	var c2, err2 = sk.DeriveVersion(DerivationV3, []byte("2"), nil)
+	if err1 != nil || err2 != nil {
+		t.Fatal(err1, err2)
+	}
+	if c1.prefix == c2.prefix {
+		t.Fatal("siblings have the same prefix")
+	}
+
+	var sig1 = c1.Sign(msg)
+	var sig2 = c2.Sign(msg)
+	if bytes.Equal(sig1[:32], sig2[:32]) {
+		t.Fatal("siblings signing the same message have the same R")
+	}
+	if !c1.Public().Verify(msg, sig1[:]) || !c2.Public().Verify(msg, sig2[:]) {
+		t.Fatal("signature by child does not verify")
+	}
+
+	// the public key of the child is still derivable from the parent
+	var pk, err = sk.Public().DeriveVersion(DerivationV3, []byte("1"))
+	if err != nil || pk.Key() != c1.Public().Key() {
+		t.Fatal("public and secret derivation disagree", err)
+	}
+}
+
+func TestDeriveV3InPlace(t *testing.T) {
+	var sk = testSecret(1)
+	var want, _ = sk.DeriveVersion(DerivationV3, []byte("1"), nil)
+
+	// dst == sk must not use the overwritten prefix
+	sk.deriveInto(sk, DerivationV3, []byte("1"), nil)
+	if sk.scalar != want.scalar || sk.prefix != want.prefix {
+		t.Fatal("deriving in place differs from deriving into a new key")
+	}
+}
+
+func TestDeriveIterator(t *testing.T) {
+	var sk = testSecret(1)
+	var next = sk.DeriveIterator([]byte("wallet"))
+
+	var prefixes = map[Buffer256]bool{}
+	for i := uint64(0); i < 5; i++ {
+		var got = next()
+		var want, _ = sk.DeriveVersion(DerivationV3, counterIndex([]byte("wallet"), i), nil)
+		if got.scalar != want.scalar || got.prefix != want.prefix {
+			t.Fatalf("key %d differs from direct derivation", i)
+		}
+		if v1 := sk.Derive(counterIndex([]byte("wallet"), i), nil); got.scalar == v1.scalar {
+			t.Fatalf("key %d is the DerivationV1 child of Derive", i)
+		}
+		if !got.IsDerived() {
+			t.Fatalf("key %d does not report being derived", i)
+		}
+		if prefixes[got.prefix] {
+			t.Fatalf("key %d has the same prefix as an earlier key", i)
+		}
+		prefixes[got.prefix] = true
+	}
+}
+
+func TestDeriveRangeCtx(t *testing.T) {
+	var sk = testSecret(1)
+	var next = sk.DeriveIterator([]byte("wallet"))
+	next()
+	next()
+
+	var keys, err = sk.DeriveRangeCtx(context.Background(), []byte("wallet"), 2, 5)
+	if err != nil || len(keys) != 5 {
+		t.Fatal(len(keys), err)
+	}
+	var prefixes = map[Buffer256]bool{}
+	for i, got := range keys {
+		var want = next()
+		if got.scalar != want.scalar || got.prefix != want.prefix {
+			t.Fatalf("key %d differs from DeriveIterator", i)
+		}
+		if prefixes[got.prefix] {
+			t.Fatalf("key %d has the same prefix as an earlier key", i)
+		}
+		prefixes[got.prefix] = true
+	}
+}
+
+func TestDeriveRangeCtxCancel(t *testing.T) {
+	var sk = testSecret(1)
+	var ctx, cancel = context.WithCancel(context.Background())
+	cancel()
+
+	var start = time.Now()
+	var keys, err = sk.DeriveRangeCtx(ctx, nil, 0, 1<<40)
+	if err != context.Canceled || keys != nil {
+		t.Fatal(len(keys), err)
+	}
+	if time.Since(start) > time.Second {
+		t.Fatal("cancelled derivation did not stop promptly")
+	}
+}
+
+func TestDeriveRangeCtxDeadline(t *testing.T) {
+	var sk = testSecret(1)
+	var ctx, cancel = context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	var start = time.Now()
+	var keys, err = sk.DeriveRangeCtx(ctx, nil, 0, 1<<40)
+	if err != context.DeadlineExceeded || keys != nil {
+		t.Fatal(len(keys), err)
+	}
+	if time.Since(start) > 5*time.Second {
+		t.Fatal("derivation did not stop promptly at the deadline")
+	}
+}
+
+// Known answers for each derivation scheme, from testSecret(3) with index
+// "i": the public child, and the secret child with skey "s".
+var deriveVersionTests = []struct {
+	v              DerivationVersion
+	public, secret string
+}{
+	{DerivationV1,
+		"a91f7ca6a820a10bf414d172c5288909bcb21afdb24554c5d49771c1eb252dae",
+		"3ff0b5c6d156f909292e478d28c76dfe2d91b5582c66b5b0fe92c150420ab69f"},
+	{DerivationV2,
+		"6a0081b64a5a7760afbe2c62380c60a500076576a9da5b6c1f797e879c4373fb",
+		"9f881654196e5b1ec582004e48ca399615aba53eb360b61117ae742b0e6bf7ac"},
+	{DerivationV3,
+		"a423b34db93810dba7ca0c00fe9553beb094abd8ac802129058f442576892f32",
+		"f0eb0385bd943483c113b8d413abcad88b7d0144293fa2d4594c014ec916c73d"},
+}
+
+func TestDeriveVersion(t *testing.T) {
+	var sk = testSecret(3)
+	for _, test := range deriveVersionTests {
+		var pub, _ = sk.DeriveVersion(test.v, []byte("i"), nil)
+		var sec, _ = sk.DeriveVersion(test.v, []byte("i"), []byte("s"))
+		var pk, _ = sk.Public().DeriveVersion(test.v, []byte("i"))
+
+		var k1, k2, k3 = pub.Public().Key(), sec.Public().Key(), pk.Key()
+		if got := hex.EncodeToString(k1[:]); got != test.public {
+			t.Errorf("v%d: public child %s, want %s", test.v, got, test.public)
+		}
+		if got := hex.EncodeToString(k2[:]); got != test.secret {
+			t.Errorf("v%d: secret child %s, want %s", test.v, got, test.secret)
+		}
+		if k3 != k1 {
+			t.Errorf("v%d: Public.DeriveVersion disagrees with Secret.DeriveVersion", test.v)
+		}
+	}
+
+	if _, err := sk.DeriveVersion(0, []byte("i"), nil); err == nil {
+		t.Error("unknown version accepted")
+	}
+}
+
+func TestDeriveInto(t *testing.T) {
+	var sk = testSecret(1)
+	var pk = sk.Public()
+	var child Secret
+	var childPk Public
+
+	for _, skey := range [][]byte{nil, []byte("skey")} {
+		for i := byte(0); i < 4; i++ {
+			var index = []byte{'i', i}
+			var want = sk.Derive(index, skey)
+			sk.DeriveInto(&child, index, skey)
+			if child.scalar != want.scalar || child.prefix != want.prefix || !child.IsDerived() {
+				t.Fatalf("Secret.DeriveInto(%v, %q) differs from Derive", index, skey)
+			}
+			if skey != nil {
+				continue
+			}
+			pk.DeriveInto(&childPk, index)
+			if childPk.Key() != want.Public().Key() {
+				t.Fatalf("Public.DeriveInto(%v) differs from Derive", index)
+			}
+		}
+	}
+
+	// dst may be the parent itself
+	var want = sk.Derive([]byte("i"), nil)
+	sk.DeriveInto(sk, []byte("i"), nil)
+	if sk.scalar != want.scalar || sk.prefix != want.prefix {
+		t.Fatal("Secret.DeriveInto in place differs from Derive")
+	}
+	var wantPk = pk.Derive([]byte("i"))
+	pk.DeriveInto(pk, []byte("i"))
+	if pk.Key() != wantPk.Key() {
+		t.Fatal("Public.DeriveInto in place differs from Derive")
+	}
+}
+
+// testChildren returns n indexes, and the children of pk for them, with
+// every third child derived for the wrong index.
+func testChildren(pk *Public, n int) ([][]byte, []*Public, []bool) {
+	var indices = make([][]byte, n)
+	var children = make([]*Public, n)
+	var want = make([]bool, n)
+	for i := range indices {
+		indices[i] = []byte{'c', byte(i)}
+		want[i] = i%3 != 2
+		if want[i] {
+			children[i] = pk.Derive(indices[i])
+		} else {
+			children[i] = pk.Derive([]byte("wrong"))
+		}
+	}
+	return indices, children, want
+}
+
+func TestVerifyChildrenBatch(t *testing.T) {
+	var pk = testSecret(1).Public()
+
+	// below and above the size at which a table is built
+	for _, n := range []int{0, 1, 3, verifyChildrenTableMin, 10} {
+		var indices, children, want = testChildren(pk, n)
+		var res = VerifyChildrenBatch(pk, indices, children)
+		for i := range res {
+			var single = pk.Derive(indices[i]).Key() == children[i].Key()
+			if res[i] != want[i] || res[i] != single {
+				t.Errorf("n = %d, child %d: got %v, want %v", n, i, res[i], want[i])
+			}
+		}
+		if pk.loadTable() != nil {
+			t.Fatal("VerifyChildrenBatch kept a table with the parent")
+		}
+
+		var err = ProveDerivationBatch(pk, indices, children)
+		if n > 2 && !errors.Is(err, ErrDerivationMismatch) {
+			t.Errorf("n = %d: ProveDerivationBatch returned %v", n, err)
+		}
+	}
+
+	// a parent with its own table uses it
+	var indices, children, want = testChildren(pk, 6)
+	pk.Precompute()
+	for i, ok := range VerifyChildrenBatch(pk, indices, children) {
+		if ok != want[i] {
+			t.Errorf("precomputed parent, child %d: got %v", i, ok)
+		}
+	}
+
+	// a nil child, or mismatched lengths, are reported as not derived
+	indices, children, _ = testChildren(pk, 2)
+	children[0] = nil
+	if res := VerifyChildrenBatch(pk, indices, children); len(res) != 2 || res[0] || !res[1] {
+		t.Errorf("nil child: got %v", res)
+	}
+	children[0] = pk.Derive(indices[0])
+	if res := VerifyChildrenBatch(pk, indices[:1], children); len(res) != 2 || res[0] || res[1] {
+		t.Errorf("mismatched lengths: got %v", res)
+	}
+}
+
+func TestEncodeIndex(t *testing.T) {
+	var a = EncodeIndex([]byte("1"), []byte("23"))
+	var b = EncodeIndex([]byte("12"), []byte("3"))
+	if bytes.Equal(a, b) {
+		t.Fatal("EncodeIndex is ambiguous")
+	}
+	var want, _ = hex.DecodeString("0000000131000000023233")
+	if !bytes.Equal(a, want) {
+		t.Errorf("EncodeIndex(\"1\", \"23\") = %x, want %x", a, want)
+	}
+	if len(EncodeIndex()) != 0 {
+		t.Error("EncodeIndex() is not empty")
+	}
+
+	var sk = testSecret(1)
+	if sk.Derive(a, nil).Equal(sk.Derive(b, nil)) {
+		t.Error("[\"1\", \"23\"] and [\"12\", \"3\"] derive the same key")
+	}
+}
+
+func TestDeriveSeed(t *testing.T) {
+	var master = bytes.Repeat([]byte{7}, 32)
+
+	var seed = DeriveSeed(master, "signing")
+	var want, _ = hex.DecodeString("ef0ed4282519a852d2b48fe16cf160692c815973b5b9d1a1fd8da82b5d5c92f3")
+	if !bytes.Equal(seed, want) {
+		t.Errorf("DeriveSeed = %x, want %x", seed, want)
+	}
+	if !bytes.Equal(DeriveSeed(master, "signing"), seed) {
+		t.Error("DeriveSeed is not deterministic")
+	}
+
+	// other labels and master seeds give unrelated seeds
+	var seen = map[string]bool{string(seed): true}
+	var others = [][]byte{
+		DeriveSeed(master, "encryption"),
+		DeriveSeed(master, "signing2"),
+		DeriveSeed(master, ""),
+		DeriveSeed(master[1:], "signing"),
+		DeriveSeed(append(master, 's'), "igning"),
+	}
+	for i, other := range others {
+		if seen[string(other)] {
+			t.Errorf("derived seed %d repeats an earlier seed", i)
+		}
+		seen[string(other)] = true
+	}
+
+	// derived seeds give ordinary seed keys
+	var sk = SecretFromSeed(seed)
+	if got, ok := sk.Seed(); !ok || !bytes.Equal(got[:], seed) || sk.IsDerived() {
+		t.Error("key from a derived seed is not an ordinary seed key")
+	}
+}
+
+func TestVerifyChild(t *testing.T) {
+	var sk = testSecret(1)
+	var pk = sk.Public()
+	var msg = []byte("child")
+
+	var sig = sk.Derive([]byte("a"), nil).Sign(msg)
+	var other = sk.Derive([]byte("b"), nil).Sign(msg)
+	var hidden = sk.Derive([]byte("a"), []byte{}).Sign(msg)
+
+	for _, s := range [][]byte{sig[:], other[:], hidden[:], sig[:63]} {
+		var want = pk.Derive([]byte("a")).Verify(msg, s)
+		if got := pk.VerifyChild([]byte("a"), msg, s); got != want {
+			t.Errorf("VerifyChild = %v, Derive+Verify = %v", got, want)
+		}
+	}
+
+	if !pk.VerifyChild([]byte("a"), msg, sig[:]) {
+		t.Error("VerifyChild rejected the child's signature")
+	}
+	if pk.VerifyChild([]byte("a"), msg, other[:]) {
+		t.Error("VerifyChild accepted another child's signature")
+	}
+	if pk.VerifyChild([]byte("a"), msg, hidden[:]) {
+		t.Error("VerifyChild accepted a secretly derived child's signature")
+	}
+	if pk.Verify(msg, sig[:]) {
+		t.Error("the parent key verifies the child's signature")
+	}
+}
+
+func TestVerifyChildVersion(t *testing.T) {
+	var sk = testSecret(2)
+	var pk = sk.Public()
+	var msg = []byte("versioned child")
+
+	// the children of DeriveIterator are found with DerivationV3 only
+	var next = sk.DeriveIterator([]byte("scan"))
+	var indices = make([][]byte, 6)
+	var children = make([]*Public, 6)
+	for i := range indices {
+		indices[i] = counterIndex([]byte("scan"), uint64(i))
+		children[i] = next().Public()
+	}
+	for i, ok := range VerifyChildrenBatchVersion(DerivationV3, pk, indices, children) {
+		if !ok {
+			t.Errorf("DerivationV3 child %d not recognised", i)
+		}
+	}
+	for i, ok := range VerifyChildrenBatch(pk, indices, children) {
+		if ok {
+			t.Errorf("DerivationV3 child %d recognised as DerivationV1", i)
+		}
+	}
+	if err := ProveDerivationBatchVersion(DerivationV3, pk, indices, children); err != nil {
+		t.Errorf("ProveDerivationBatchVersion = %v", err)
+	}
+	if err := ProveDerivationBatch(pk, indices, children); !errors.Is(err, ErrDerivationMismatch) {
+		t.Errorf("ProveDerivationBatch = %v", err)
+	}
+
+	// each scheme recognises its own children only
+	for _, v := range []DerivationVersion{DerivationV1, DerivationV2, DerivationV3} {
+		var child, _ = sk.DeriveVersion(v, []byte("c"), nil)
+		var sig = child.Sign(msg)
+		if !pk.VerifyChildVersion(v, []byte("c"), msg, sig[:]) {
+			t.Errorf("v%d: VerifyChildVersion rejected its own child", v)
+		}
+		if err := ProveDerivationVersion(v, pk, child.Public(), []byte("c")); err != nil {
+			t.Errorf("v%d: ProveDerivationVersion = %v", v, err)
+		}
+		var other = DerivationV1
+		if v == DerivationV1 {
+			other = DerivationV3
+		}
+		if pk.VerifyChildVersion(other, []byte("c"), msg, sig[:]) {
+			t.Errorf("v%d: VerifyChildVersion accepted it as v%d", v, other)
+		}
+		if err := ProveDerivationVersion(other, pk, child.Public(), []byte("c")); err != ErrDerivationMismatch {
+			t.Errorf("v%d: ProveDerivationVersion as v%d = %v", v, other, err)
+		}
+	}
+
+	// an unknown scheme recognises nothing
+	var child, _ = sk.DeriveVersion(DerivationV3, []byte("c"), nil)
+	var sig = child.Sign(msg)
+	if pk.VerifyChildVersion(0, []byte("c"), msg, sig[:]) {
+		t.Error("VerifyChildVersion accepted an unknown version")
+	}
+	if ProveDerivationVersion(9, pk, child.Public(), []byte("c")) == nil {
+		t.Error("ProveDerivationVersion accepted an unknown version")
+	}
+	if ProveDerivationBatchVersion(9, pk, indices, children) == nil {
+		t.Error("ProveDerivationBatchVersion accepted an unknown version")
+	}
+	for i, ok := range VerifyChildrenBatchVersion(9, pk, indices, children) {
+		if ok {
+			t.Errorf("unknown version: child %d recognised", i)
+		}
+	}
+}
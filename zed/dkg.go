@@ -0,0 +1,384 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"strconv"
+)
+
+//
+//  Distributed key generation (DKG) lets n participants jointly generate a
+//  group key for t-of-n FROST signing, without any trusted dealer: no single
+//  participant ever knows the group's secret key. This is Pedersen's DKG, in
+//  which every participant acts as a Feldman VSS dealer for a random secret
+//  of its own, and the group secret is the sum of all of them:
+//
+//    Round 1: participant i picks a random polynomial f_i of degree t - 1,
+//             and broadcasts the VSS commitments C_i,k = c_i,k * G to its
+//             coefficients, with a proof of knowledge of its secret
+//             a_i = f_i(0):
+//
+//               k = random, R = k * G
+//               c = sha512(dkg_str || i || ctx || Cs_i,0 || Rs) % q
+//               proof = Rs || (k + c * a_i) % q
+//
+//    Round 2: once it has every participant's round 1 message, and checked
+//             their proofs, participant i sends f_i(j) privately to every
+//             participant j.
+//
+//    Finish:  participant j checks every share f_i(j) it received against
+//             C_i with VerifyShare, and computes its signing share, the group
+//             public key, and every participant's public share:
+//
+//               s_j = f_1(j) + ... + f_n(j)
+//               A = C_1,0 + ... + C_n,0
+//               A_k = (C_1 + ... + C_n)(k)
+//
+//  The proofs of knowledge stop a participant from choosing its C_i,0 after
+//  seeing the others', to cancel them out (a rogue-key attack). The context
+//  string ctx, which must be unique to the DKG run, stops proofs from being
+//  replayed from another run.
+//
+//  Round 2 messages hold secret shares: they must be sent over authenticated,
+//  encrypted channels, to their recipient only. If any check fails, the DKG
+//  must be aborted; the error names the participant at fault.
+//
+//  REFERENCES:
+//    [1] Torben Pryds Pedersen, "A Threshold Cryptosystem without a Trusted
+//        Party"
+//    [2] Chelsea Komlo, Ian Goldberg
+//        "FROST: Flexible Round-Optimized Schnorr Threshold Signatures"
+//        https://eprint.iacr.org/2020/852
+//
+
+// DkgRound1 is the message every participant broadcasts in round 1 of the
+// DKG: its VSS commitments, and a proof of knowledge of its secret.
+type DkgRound1 struct {
+	Index       uint16
+	Commitments VssCommitments
+	Proof       [64]byte
+}
+
+// DkgRound2 is the message a participant sends privately to each other
+// participant in round 2 of the DKG, holding the recipient's secret share.
+type DkgRound2 struct {
+	From  uint16
+	To    uint16
+	Share Scalar
+}
+
+// DkgParticipant is one participant's state in a DKG run, which moves
+// through Round1, Round2 and Finish, in that order.
+type DkgParticipant struct {
+	index   uint16
+	t, n    int
+	context []byte
+
+	coef   []Scalar
+	round1 []*DkgRound1
+	round  int
+}
+
+// NewDkgParticipant starts a DKG run for participant index (1..n), to
+// generate a t-of-n group key. context must be unique to this run, and the
+// same for every participant.
+func NewDkgParticipant(index uint16, t, n int, context []byte) (*DkgParticipant, error) {
+	if t < 1 || t > n || n > 65535 {
+		return nil, errors.New("NewDkgParticipant: bad threshold " + strconv.Itoa(t) + " of " + strconv.Itoa(n))
+	}
+	if index < 1 || int(index) > n {
+		return nil, errors.New("NewDkgParticipant: bad participant index " + strconv.Itoa(int(index)))
+	}
+	return &DkgParticipant{index: index, t: t, n: n, context: append([]byte{}, context...)}, nil
+}
+
+// Round1 picks the participant's random polynomial, and returns the message
+// to broadcast to every other participant.
+func (dp *DkgParticipant) Round1() (*DkgRound1, error) {
+	if dp.round != 0 {
+		return nil, errors.New("DkgParticipant.Round1: wrong round")
+	}
+
+	// a_i random, and the rest of the polynomial
+	var buf Buffer512
+	var a Scalar
+	if _, err := rand.Read(buf[:]); err != nil {
+		return nil, err
+	}
+	ScalarReduce512(&a, &buf)
+	var coef, err = vssPolynomial(&a, dp.t)
+	if err != nil {
+		return nil, err
+	}
+	dp.coef = coef
+
+	var msg = &DkgRound1{Index: dp.index, Commitments: vssCommit(coef)}
+
+	// k random, R = k * G
+	if _, err = rand.Read(buf[:]); err != nil {
+		return nil, err
+	}
+	var k Scalar
+	ScalarReduce512(&k, &buf)
+	var R Point
+	ScalarMultBase(&R, &k)
+	var Rs = PointToKey(&R)
+
+	// proof = Rs || (k + c * a_i) % q
+	var c = dkgChallenge(dp.index, dp.context, &msg.Commitments[0], &Rs)
+	var mu Scalar
+	ScalarMultScalarAddScalar(&mu, &c, &coef[0], &k)
+	copy(msg.Proof[:32], Rs[:])
+	copy(msg.Proof[32:], mu[:])
+	wipe(k[:])
+	wipe(a[:])
+
+	dp.round = 1
+	return msg, nil
+}
+
+// Round2 checks the round 1 messages of all n participants (including this
+// one's own), and returns the round 2 messages to send to each of the other
+// participants, in order of their index.
+func (dp *DkgParticipant) Round2(msgs []*DkgRound1) ([]*DkgRound2, error) {
+	if dp.round != 1 {
+		return nil, errors.New("DkgParticipant.Round2: wrong round")
+	}
+
+	// sort by index, checking that every participant is there once
+	var sorted = make([]*DkgRound1, dp.n)
+	for _, msg := range msgs {
+		if msg == nil {
+			return nil, errors.New("DkgParticipant.Round2: nil round 1 message")
+		}
+		if msg.Index < 1 || int(msg.Index) > dp.n || sorted[msg.Index-1] != nil {
+			return nil, errors.New("DkgParticipant.Round2: bad or duplicate participant index " + strconv.Itoa(int(msg.Index)))
+		}
+		sorted[msg.Index-1] = msg
+	}
+	for i, msg := range sorted {
+		if msg == nil {
+			return nil, errors.New("DkgParticipant.Round2: missing participant " + strconv.Itoa(i+1))
+		}
+		if len(msg.Commitments) != dp.t || !dkgValidCommitments(msg.Commitments) || !dkgVerifyProof(msg, dp.context) {
+			return nil, errors.New("DkgParticipant.Round2: invalid round 1 message from participant " + strconv.Itoa(i+1))
+		}
+	}
+	if sorted[dp.index-1].Commitments[0] != vssCommit(dp.coef[:1])[0] {
+		return nil, errors.New("DkgParticipant.Round2: own round 1 message does not match")
+	}
+	dp.round1 = sorted
+
+	// share f_i(j) for every other participant j
+	var out = make([]*DkgRound2, 0, dp.n-1)
+	for j := 1; j <= dp.n; j++ {
+		if j == int(dp.index) {
+			continue
+		}
+		out = append(out, &DkgRound2{From: dp.index, To: uint16(j), Share: vssEval(dp.coef, uint16(j))})
+	}
+
+	dp.round = 2
+	return out, nil
+}
+
+// Finish checks the round 2 messages sent to this participant by all the
+// other participants, and returns its share of the group key, ready for
+// FROST signing. The polynomial is destroyed.
+func (dp *DkgParticipant) Finish(msgs []*DkgRound2) (*FrostShare, error) {
+	if dp.round != 2 {
+		return nil, errors.New("DkgParticipant.Finish: wrong round")
+	}
+	if len(msgs) != dp.n-1 {
+		return nil, errors.New("DkgParticipant.Finish: wrong number of round 2 messages")
+	}
+
+	// s_j = f_j(j) + sum(f_i(j)), checking each against C_i
+	var s = vssEval(dp.coef, dp.index)
+	var seen = make([]bool, dp.n)
+	seen[dp.index-1] = true
+	for _, msg := range msgs {
+		if msg == nil {
+			return nil, errors.New("DkgParticipant.Finish: nil round 2 message")
+		}
+		if msg.To != dp.index || msg.From < 1 || int(msg.From) > dp.n || seen[msg.From-1] {
+			return nil, errors.New("DkgParticipant.Finish: bad round 2 message from participant " + strconv.Itoa(int(msg.From)))
+		}
+		seen[msg.From-1] = true
+
+		var share = VssShare{Index: dp.index, Value: msg.Share}
+		if !VerifyShare(share, dp.round1[msg.From-1].Commitments) {
+			return nil, errors.New("DkgParticipant.Finish: invalid share from participant " + strconv.Itoa(int(msg.From)))
+		}
+		ScalarMultScalarAddScalar(&s, &scalarOne, &s, &msg.Share)
+	}
+
+	// C = C_1 + ... + C_n, coefficient-wise
+	var sum = make([]Point, dp.t)
+	for k := range sum {
+		PointIdentity(&sum[k])
+	}
+	for _, msg := range dp.round1 {
+		for k := range sum {
+			var Ck Point
+			if !DecompressPoint(&Ck, &msg.Commitments[k]) {
+				return nil, errors.New("DkgParticipant.Finish: invalid commitment from participant " + strconv.Itoa(int(msg.Index)))
+			}
+			PointAdd(&sum[k], &sum[k], &Ck)
+		}
+	}
+	var commitments = make(VssCommitments, dp.t)
+	for k := range sum {
+		CompressPoint(&commitments[k], &sum[k])
+	}
+
+	// A = C(0), A_k = C(k)
+	var group = &FrostGroup{Threshold: dp.t}
+	group.Public, _ = commitments.Public()
+	for k := 1; k <= dp.n; k++ {
+		var Ak, _ = commitments.ShareKey(uint16(k))
+		group.Shares = append(group.Shares, Ak)
+	}
+
+	for k := range dp.coef {
+		wipe(dp.coef[k][:])
+	}
+	dp.round = 3
+
+	return &FrostShare{Index: dp.index, Group: group, share: s}, nil
+}
+
+// dkgValidCommitments checks that every commitment is a valid point encoding.
+func dkgValidCommitments(commitments VssCommitments) bool {
+	var C Point
+	for k := range commitments {
+		if !DecompressPoint(&C, &commitments[k]) {
+			return false
+		}
+	}
+	return true
+}
+
+// dkgChallenge computes c = sha512(dkg_str || i || ctx || Cs_i,0 || Rs) % q.
+func dkgChallenge(index uint16, context []byte, C0, Rs *Buffer256) Scalar {
+	var hash = sha512.New()
+	var res Buffer512
+	var c Scalar
+	var i [2]byte
+	binary.BigEndian.PutUint16(i[:], index)
+	hash.Write([]byte("zed25519_dkg"))
+	hash.Write(i[:])
+	hash.Write(context)
+	hash.Write(C0[:])
+	hash.Write(Rs[:])
+	hash.Sum(res[:0])
+	ScalarReduce512(&c, &res)
+	return c
+}
+
+// dkgVerifyProof checks the proof of knowledge in a round 1 message:
+// mu * G == R + c * C_i,0.
+func dkgVerifyProof(msg *DkgRound1, context []byte) bool {
+	var pk, err = msg.Commitments.Public()
+	if err != nil {
+		return false
+	}
+	var Rs Buffer256
+	copy(Rs[:], msg.Proof[:32])
+	var c = dkgChallenge(msg.Index, context, &msg.Commitments[0], &Rs)
+
+	var ps, perr = ParseSignature(msg.Proof[:])
+	if perr != nil {
+		return false
+	}
+	return pk.verifyChallenge(ps, &c)
+}
+
+// Marshal serializes the round 1 message as index || t || C_0 || ... ||
+// C_t-1 || proof, with index and t as 2-byte big-endian integers.
+func (msg *DkgRound1) Marshal() []byte {
+	var buf = make([]byte, 4, 4+32*len(msg.Commitments)+64)
+	binary.BigEndian.PutUint16(buf[0:], msg.Index)
+	binary.BigEndian.PutUint16(buf[2:], uint16(len(msg.Commitments)))
+	for k := range msg.Commitments {
+		buf = append(buf, msg.Commitments[k][:]...)
+	}
+	return append(buf, msg.Proof[:]...)
+}
+
+// ParseDkgRound1 decodes a round 1 message serialized by Marshal. The
+// commitments and proof are not checked until Round2.
+func ParseDkgRound1(buf []byte) (*DkgRound1, error) {
+	if len(buf) < 4 {
+		return nil, errors.New("ParseDkgRound1: message too short")
+	}
+	var t = int(binary.BigEndian.Uint16(buf[2:]))
+	if l := len(buf); t < 1 || l != 4+32*t+64 {
+		return nil, errors.New("ParseDkgRound1: bad message length: " + strconv.Itoa(l))
+	}
+
+	var msg = &DkgRound1{
+		Index:       binary.BigEndian.Uint16(buf),
+		Commitments: make(VssCommitments, t),
+	}
+	for k := range msg.Commitments {
+		copy(msg.Commitments[k][:], buf[4+32*k:])
+	}
+	copy(msg.Proof[:], buf[4+32*t:])
+
+	return msg, nil
+}
+
+// Marshal serializes the round 2 message as from || to || share, with from
+// and to as 2-byte big-endian integers. The result holds a secret share.
+func (msg *DkgRound2) Marshal() []byte {
+	var buf = make([]byte, 4, 36)
+	binary.BigEndian.PutUint16(buf[0:], msg.From)
+	binary.BigEndian.PutUint16(buf[2:], msg.To)
+	return append(buf, msg.Share[:]...)
+}
+
+// ParseDkgRound2 decodes a round 2 message serialized by Marshal.
+func ParseDkgRound2(buf []byte) (*DkgRound2, error) {
+	if l := len(buf); l != 36 {
+		return nil, errors.New("ParseDkgRound2: bad message length: " + strconv.Itoa(l))
+	}
+
+	var msg = &DkgRound2{
+		From: binary.BigEndian.Uint16(buf[0:]),
+		To:   binary.BigEndian.Uint16(buf[2:]),
+	}
+	copy(msg.Share[:], buf[4:])
+	if !ValidScalar(&msg.Share) {
+		return nil, errors.New("ParseDkgRound2: invalid share")
+	}
+
+	return msg, nil
+}
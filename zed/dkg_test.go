@@ -0,0 +1,214 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"strings"
+	"testing"
+)
+
+// testDkg starts a t-of-n DKG run, and returns every participant, and their
+// round 1 messages.
+func testDkg(t *testing.T, threshold, n int) ([]*DkgParticipant, []*DkgRound1) {
+	var participants = make([]*DkgParticipant, n)
+	var round1 = make([]*DkgRound1, n)
+	for i := range participants {
+		var err error
+		participants[i], err = NewDkgParticipant(uint16(i+1), threshold, n, []byte("test run"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		round1[i], err = participants[i].Round1()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	return participants, round1
+}
+
+// dkgInbox collects the round 2 messages sent to participant to.
+func dkgInbox(round2 [][]*DkgRound2, to uint16) []*DkgRound2 {
+	var inbox []*DkgRound2
+	for _, out := range round2 {
+		for _, msg := range out {
+			if msg.To == to {
+				inbox = append(inbox, msg)
+			}
+		}
+	}
+	return inbox
+}
+
+func TestDkg(t *testing.T) {
+	var participants, round1 = testDkg(t, 2, 3)
+
+	// every round 1 message survives serialization
+	for i, msg := range round1 {
+		var parsed, err = ParseDkgRound1(msg.Marshal())
+		if err != nil {
+			t.Fatal(err)
+		}
+		round1[i] = parsed
+	}
+
+	var round2 = make([][]*DkgRound2, len(participants))
+	for i, dp := range participants {
+		var err error
+		if round2[i], err = dp.Round2(round1); err != nil {
+			t.Fatalf("participant %d: Round2 = %v", i+1, err)
+		}
+		for j, msg := range round2[i] {
+			var parsed, err = ParseDkgRound2(msg.Marshal())
+			if err != nil || *parsed != *msg {
+				t.Fatalf("participant %d: round 2 message %d does not round-trip: %v", i+1, j, err)
+			}
+		}
+	}
+
+	var shares = make([]*FrostShare, len(participants))
+	for i, dp := range participants {
+		var err error
+		if shares[i], err = dp.Finish(dkgInbox(round2, uint16(i+1))); err != nil {
+			t.Fatalf("participant %d: Finish = %v", i+1, err)
+		}
+	}
+
+	// every participant agrees on the group
+	var group = shares[0].Group
+	for i, share := range shares {
+		if share.Group.Public.Key() != group.Public.Key() {
+			t.Fatalf("participant %d has another group key", i+1)
+		}
+		for k := range group.Shares {
+			if share.Group.Shares[k].Key() != group.Shares[k].Key() {
+				t.Fatalf("participant %d has another public share %d", i+1, k+1)
+			}
+		}
+	}
+
+	// any 2 of them sign with FROST under the group key
+	var msg = []byte("dkg message")
+	for _, signers := range [][]int{{1, 2}, {1, 3}, {2, 3}} {
+		var commitments, partials = frostRound(t, shares, signers, msg)
+		var sig, err = AggregateShares(group, msg, commitments, partials)
+		if err != nil {
+			t.Fatalf("%v: AggregateShares = %v", signers, err)
+		}
+		if !group.Public.Verify(msg, sig[:]) {
+			t.Errorf("%v: signature does not verify", signers)
+		}
+	}
+}
+
+func TestDkgBadRound1(t *testing.T) {
+	var participants, round1 = testDkg(t, 2, 3)
+
+	// expectRound2Error runs Round2 on a copy of participant 1 with msgs
+	var expectRound2Error = func(name string, msgs []*DkgRound1, want string) {
+		var dp = *participants[0]
+		if _, err := dp.Round2(msgs); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: Round2 = %v, want %q", name, err, want)
+		}
+	}
+
+	var badProof = *round1[1]
+	badProof.Proof[40] ^= 1
+	expectRound2Error("bad proof", []*DkgRound1{round1[0], &badProof, round1[2]}, "participant 2")
+
+	var otherRun = *round1[2]
+	otherRun.Index = 2
+	expectRound2Error("replayed proof", []*DkgRound1{round1[0], &otherRun, round1[2]}, "participant 2")
+
+	var badPoint = *round1[2]
+	badPoint.Commitments = append(VssCommitments{}, round1[2].Commitments...)
+	badPoint.Commitments[1] = Buffer256{2}
+	expectRound2Error("invalid commitment", []*DkgRound1{round1[0], round1[1], &badPoint}, "participant 3")
+
+	var short = *round1[1]
+	short.Commitments = short.Commitments[:1]
+	expectRound2Error("wrong threshold", []*DkgRound1{round1[0], &short, round1[2]}, "participant 2")
+
+	expectRound2Error("duplicate index", []*DkgRound1{round1[0], round1[1], round1[1]}, "duplicate participant index 2")
+	expectRound2Error("missing participant", round1[:2], "missing participant 3")
+	expectRound2Error("nil message", []*DkgRound1{round1[0], nil, round1[2]}, "nil round 1 message")
+	expectRound2Error("another's message as own", []*DkgRound1{round1[1], round1[1], round1[2]}, "duplicate")
+}
+
+func TestDkgBadRound2(t *testing.T) {
+	var participants, round1 = testDkg(t, 2, 3)
+	var round2 = make([][]*DkgRound2, len(participants))
+	for i, dp := range participants {
+		round2[i], _ = dp.Round2(round1)
+	}
+	var inbox = dkgInbox(round2, 1)
+
+	// expectFinishError runs Finish on a copy of participant 1 with msgs
+	var expectFinishError = func(name string, msgs []*DkgRound2, want string) {
+		var dp = *participants[0]
+		dp.coef = append([]Scalar{}, participants[0].coef...)
+		if _, err := dp.Finish(msgs); err == nil || !strings.Contains(err.Error(), want) {
+			t.Errorf("%s: Finish = %v, want %q", name, err, want)
+		}
+	}
+
+	var badShare = *inbox[1]
+	badShare.Share[0] ^= 1
+	expectFinishError("bad share", []*DkgRound2{inbox[0], &badShare}, "invalid share from participant 3")
+
+	var wrongRecipient = *inbox[0]
+	wrongRecipient.To = 3
+	expectFinishError("wrong recipient", []*DkgRound2{&wrongRecipient, inbox[1]}, "participant 2")
+
+	expectFinishError("duplicate sender", []*DkgRound2{inbox[0], inbox[0]}, "participant 2")
+	expectFinishError("missing message", inbox[:1], "wrong number")
+	expectFinishError("nil message", []*DkgRound2{inbox[0], nil}, "nil round 2 message")
+
+	// the honest messages still finish
+	if _, err := participants[0].Finish(inbox); err != nil {
+		t.Errorf("Finish = %v", err)
+	}
+}
+
+func TestDkgRounds(t *testing.T) {
+	if _, err := NewDkgParticipant(0, 2, 3, nil); err == nil {
+		t.Error("NewDkgParticipant accepted index 0")
+	}
+	if _, err := NewDkgParticipant(1, 4, 3, nil); err == nil {
+		t.Error("NewDkgParticipant accepted 4 of 3")
+	}
+
+	var dp, _ = NewDkgParticipant(1, 2, 3, nil)
+	if _, err := dp.Round2(nil); err == nil {
+		t.Error("Round2 ran before Round1")
+	}
+	if _, err := dp.Finish(nil); err == nil {
+		t.Error("Finish ran before Round2")
+	}
+	dp.Round1()
+	if _, err := dp.Round1(); err == nil {
+		t.Error("Round1 ran twice")
+	}
+}
@@ -0,0 +1,156 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha512"
+	"errors"
+)
+
+//
+//  A DLEQ (discrete log equality) proof shows that two pairs of points share
+//  the same discrete log: that Q = a * P for the same secret scalar a as in
+//  the public key A = a * G, without revealing a. This is the Chaum-Pedersen
+//  proof, made non-interactive with the Fiat-Shamir transform, and is the
+//  same proof that VrfEval builds for V = a * Bv:
+//
+//    k = sha512(nonce_str || p || Ps || Qs) % q
+//    U = k * G, V = k * P
+//    c = sha512(dleq_str || As || Ps || Qs || Us || Vs) % q
+//    s = (k + c * a) % q
+//    proof = c || s
+//
+//  A verifier recomputes U = s * G - c * A and V = s * P - c * Q, and accepts
+//  if they hash to the same c. P must be in the prime-order subgroup (as the
+//  output of HashToPointVartime always is).
+//
+
+// DleqProof is a 64-byte non-interactive proof of discrete log equality.
+type DleqProof [64]byte
+
+// DleqProve proves that Q = a * P, for the secret scalar a of sk. The nonce
+// is derived deterministically from the secret prefix and the points, as in
+// Sign. An error is returned if Q is not in fact a * P.
+func DleqProve(sk *Secret, P, Q *Point) (DleqProof, error) {
+
+	// check Q == a * P
+	var aP Point
+	ScalarMultPoint(&aP, &sk.scalar, P)
+	if !PointEqual(&aP, Q) {
+		return DleqProof{}, errors.New("DleqProve: Q is not a * P")
+	}
+
+	var As = sk.Public().Key()
+	var Ps, Qs Buffer256
+	CompressPoint(&Ps, P)
+	CompressPoint(&Qs, Q)
+
+	// k = sha512(nonce_str || p || Ps || Qs) % q
+	var hash = sha512.New()
+	var res Buffer512
+	var k Scalar
+	hash.Write([]byte("zed25519_dleq_nonce"))
+	hash.Write(sk.prefix[:])
+	hash.Write(Ps[:])
+	hash.Write(Qs[:])
+	hash.Sum(res[:0])
+	ScalarReduce512(&k, &res)
+
+	// U = k * G, V = k * P
+	var U, V Point
+	ScalarMultBase(&U, &k)
+	ScalarMultPoint(&V, &k, P)
+
+	// c = sha512(dleq_str || As || Ps || Qs || Us || Vs) % q
+	var c = dleqChallenge(&As, &Ps, &Qs, &U, &V)
+
+	// s = (k + c * a) % q
+	var s Scalar
+	ScalarMultScalarAddScalar(&s, &c, &sk.scalar, &k)
+	wipe(k[:])
+	wipe(res[:])
+
+	// proof = c || s
+	var proof DleqProof
+	copy(proof[:32], c[:])
+	copy(proof[32:], s[:])
+
+	return proof, nil
+}
+
+// DleqVerify checks a proof made by DleqProve that Q = a * P, for the secret
+// scalar a of the public key pk.
+func DleqVerify(pk *Public, P, Q *Point, proof DleqProof) bool {
+
+	// (c || s) = proof, or fail
+	var c, s Scalar
+	copy(c[:], proof[:32])
+	copy(s[:], proof[32:])
+	if !ValidScalar(&c) || !ValidScalar(&s) {
+		return false
+	}
+
+	var As = pk.Key()
+	var Ps, Qs Buffer256
+	CompressPoint(&Ps, P)
+	CompressPoint(&Qs, Q)
+
+	// U = s * G - c * A
+	var sG, cA, U Point
+	ScalarMultBase(&sG, &s)
+	pk.scalarMult(&cA, &c)
+	PointSub(&U, &sG, &cA)
+
+	// V = s * P - c * Q
+	var sP, cQ, V Point
+	ScalarMultPointVartime(&sP, &s, P)
+	ScalarMultPointVartime(&cQ, &c, Q)
+	PointSub(&V, &sP, &cQ)
+
+	// valid if c == sha512(dleq_str || As || Ps || Qs || Us || Vs) % q
+	var cCheck = dleqChallenge(&As, &Ps, &Qs, &U, &V)
+	return c == cCheck
+}
+
+// dleqChallenge computes c = sha512(dleq_str || As || Ps || Qs || Us || Vs) % q.
+func dleqChallenge(As, Ps, Qs *Buffer256, U, V *Point) Scalar {
+	var hash = sha512.New()
+	var res Buffer512
+	var Us, Vs Buffer256
+	CompressPoint(&Us, U)
+	CompressPoint(&Vs, V)
+	hash.Write([]byte("zed25519_dleq"))
+	hash.Write(As[:])
+	hash.Write(Ps[:])
+	hash.Write(Qs[:])
+	hash.Write(Us[:])
+	hash.Write(Vs[:])
+	hash.Sum(res[:0])
+
+	var c Scalar
+	ScalarReduce512(&c, &res)
+	return c
+}
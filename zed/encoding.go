@@ -0,0 +1,120 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"strconv"
+)
+
+//
+//  Signatures, VRF proofs and public keys are often transmitted as text. The
+//  FromString functions accept either hex or base64 (standard or URL-safe
+//  alphabet, with or without padding), detecting which was used, and check
+//  the decoded length. Since the hex and base64 encodings of a value of a
+//  given length always have different lengths, the detection is unambiguous.
+//
+
+// base64Encodings are the base64 variants accepted by decodeString.
+var base64Encodings = []*base64.Encoding{
+	base64.StdEncoding,
+	base64.RawStdEncoding,
+	base64.URLEncoding,
+	base64.RawURLEncoding,
+}
+
+// decodeString decodes s from hex or base64, into a value of exactly n bytes.
+func decodeString(s string, n int) ([]byte, error) {
+
+	// hex, if it has exactly the length of n hex-encoded bytes
+	if len(s) == hex.EncodedLen(n) {
+		var b, err = hex.DecodeString(s)
+		if err != nil {
+			return nil, errors.New("invalid hex encoding")
+		}
+		return b, nil
+	}
+
+	// otherwise base64, in any accepted variant
+	for _, enc := range base64Encodings {
+		var b, err = enc.DecodeString(s)
+		if err != nil {
+			continue
+		}
+		if len(b) != n {
+			return nil, errors.New("bad length: " + strconv.Itoa(len(b)))
+		}
+		return b, nil
+	}
+
+	return nil, errors.New("invalid encoding")
+}
+
+// SignatureFromString decodes a 64-byte signature from hex or base64. The
+// signature itself is not checked until it is verified.
+func SignatureFromString(s string) (Signature, error) {
+	var sig Signature
+	var b, err = decodeString(s, len(sig))
+	if err != nil {
+		return sig, errors.New("SignatureFromString: " + err.Error())
+	}
+	copy(sig[:], b)
+	return sig, nil
+}
+
+// VrfProofFromString decodes a 96-byte VRF proof from hex or base64. The proof
+// itself is not checked until it is verified.
+func VrfProofFromString(s string) (VrfProof, error) {
+	var proof VrfProof
+	var b, err = decodeString(s, len(proof))
+	if err != nil {
+		return proof, errors.New("VrfProofFromString: " + err.Error())
+	}
+	copy(proof[:], b)
+	return proof, nil
+}
+
+// PublicFromString decodes a 32-byte public key from hex or base64, and checks
+// that it is a valid curve point.
+func PublicFromString(s string) (*Public, error) {
+	var b, err = decodeString(s, 32)
+	if err != nil {
+		return nil, errors.New("PublicFromString: " + err.Error())
+	}
+
+	var pk = &Public{}
+	var key Buffer256
+	copy(key[:], b)
+
+	// point = decompress(key), or fail
+	if !DecompressPoint(&pk.point, &key) {
+		return nil, errors.New("PublicFromString: invalid point")
+	}
+
+	return pk, nil
+}
@@ -0,0 +1,105 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// encodings are the text encodings accepted by the FromString functions.
+var encodings = []struct {
+	name   string
+	encode func([]byte) string
+}{
+	{"hex", hex.EncodeToString},
+	{"upper-case hex", func(b []byte) string { return strings.ToUpper(hex.EncodeToString(b)) }},
+	{"base64", base64.StdEncoding.EncodeToString},
+	{"raw base64", base64.RawStdEncoding.EncodeToString},
+	{"base64url", base64.URLEncoding.EncodeToString},
+	{"raw base64url", base64.RawURLEncoding.EncodeToString},
+}
+
+func TestFromString(t *testing.T) {
+	var sk = testSecret(16)
+	var pk = sk.Public()
+	var As = pk.Key()
+	var sig = sk.Sign([]byte("text"))
+	var _, proof = sk.VrfEval([]byte("text"))
+
+	for _, enc := range encodings {
+		if got, err := SignatureFromString(enc.encode(sig[:])); err != nil || got != sig {
+			t.Errorf("%s: SignatureFromString = %x, %v", enc.name, got, err)
+		}
+		if got, err := VrfProofFromString(enc.encode(proof[:])); err != nil || got != proof {
+			t.Errorf("%s: VrfProofFromString = %x, %v", enc.name, got, err)
+		}
+		if got, err := PublicFromString(enc.encode(As[:])); err != nil || got.Key() != As {
+			t.Errorf("%s: PublicFromString failed: %v", enc.name, err)
+		}
+
+		// wrong lengths
+		if _, err := SignatureFromString(enc.encode(sig[:63])); err == nil {
+			t.Errorf("%s: SignatureFromString accepted 63 bytes", enc.name)
+		}
+		if _, err := VrfProofFromString(enc.encode(append(proof[:], 0))); err == nil {
+			t.Errorf("%s: VrfProofFromString accepted 97 bytes", enc.name)
+		}
+		if _, err := PublicFromString(enc.encode(sig[:])); err == nil {
+			t.Errorf("%s: PublicFromString accepted 64 bytes", enc.name)
+		}
+	}
+}
+
+var fromStringErrorTests = []string{
+	"",
+	"not an encoding at all!",
+	// 64 characters, so taken as hex, but not hex
+	"zz000000000000000000000000000000000000000000000000000000000000zz",
+	// 44 characters, but not base64
+	"%%%%AAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA=",
+}
+
+func TestFromStringErrors(t *testing.T) {
+	for _, s := range fromStringErrorTests {
+		if _, err := PublicFromString(s); err == nil {
+			t.Errorf("PublicFromString(%q) did not fail", s)
+		}
+		if _, err := SignatureFromString(s); err == nil {
+			t.Errorf("SignatureFromString(%q) did not fail", s)
+		}
+		if _, err := VrfProofFromString(s); err == nil {
+			t.Errorf("VrfProofFromString(%q) did not fail", s)
+		}
+	}
+
+	// y = 2 is not on the curve
+	if _, err := PublicFromString("02" + hex.EncodeToString(make([]byte, 31))); err == nil {
+		t.Error("PublicFromString accepted an invalid point")
+	}
+}
@@ -0,0 +1,161 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"errors"
+	"strconv"
+
+	"golang.org/x/crypto/sha3"
+)
+
+//
+//  Extended keys bundle a keypair with a 32-byte "chain code", similar to the
+//  extended keys (xpub/xprv) of BIP32. The chain code is mixed into the index
+//  of every derivation, so an ExtendedPublic carries everything needed to
+//  derive its whole subtree of public keys, and can be handed out as a
+//  single 64-byte blob for watch-only wallets, while the public key alone is
+//  not enough to derive any of the children.
+//
+//  An extended child is derived for an index as follows:
+//
+//    child   = parent.DeriveVersion(DerivationV3, EncodeIndex(chain, index))
+//    chain'  = sha3_256(chain_str || chain || index)
+//
+//  DerivationV3 gives each child secret key its own prefix, so that sibling
+//  keys never share signing nonces.
+//
+//  Only public ("non-hardened") derivation is supported, since secret
+//  derivation children cannot be derived from a public key anyway. Note that,
+//  as with BIP32 non-hardened derivation, anyone holding an ExtendedPublic and
+//  any one child secret key of it can compute the parent secret key.
+//
+
+// ExtendedPublic is a public key bundled with a chain code, from which the
+// extended public keys of its children can be derived.
+type ExtendedPublic struct {
+	public    *Public
+	chainCode Buffer256
+}
+
+// ExtendedSecret is a secret key bundled with a chain code, from which the
+// extended secret keys of its children can be derived.
+type ExtendedSecret struct {
+	secret    *Secret
+	chainCode Buffer256
+}
+
+// NewExtendedPublic bundles the public key pk with a chain code.
+func NewExtendedPublic(pk *Public, chainCode Buffer256) *ExtendedPublic {
+	return &ExtendedPublic{public: pk, chainCode: chainCode}
+}
+
+// NewExtendedSecret bundles the secret key sk with a chain code. The chain
+// code should be a secret random value, or derived from the same seed as sk.
+func NewExtendedSecret(sk *Secret, chainCode Buffer256) *ExtendedSecret {
+	return &ExtendedSecret{secret: sk, chainCode: chainCode}
+}
+
+// Public gets the public key of the extended public key.
+func (xpk *ExtendedPublic) Public() *Public {
+	return xpk.public
+}
+
+// ChainCode gets the chain code of the extended public key.
+func (xpk *ExtendedPublic) ChainCode() Buffer256 {
+	return xpk.chainCode
+}
+
+// Secret gets the secret key of the extended secret key.
+func (xsk *ExtendedSecret) Secret() *Secret {
+	return xsk.secret
+}
+
+// ChainCode gets the chain code of the extended secret key.
+func (xsk *ExtendedSecret) ChainCode() Buffer256 {
+	return xsk.chainCode
+}
+
+// Public creates the corresponding extended public key, which shares the same
+// chain code.
+func (xsk *ExtendedSecret) Public() *ExtendedPublic {
+	return &ExtendedPublic{public: xsk.secret.Public(), chainCode: xsk.chainCode}
+}
+
+// Derive generates the extended child public key for index. It matches the
+// public key of the child derived from the corresponding ExtendedSecret.
+func (xpk *ExtendedPublic) Derive(index []byte) *ExtendedPublic {
+	var child = &Public{}
+	xpk.public.deriveInto(child, DerivationV3, EncodeIndex(xpk.chainCode[:], index))
+	return &ExtendedPublic{public: child, chainCode: childChainCode(&xpk.chainCode, index)}
+}
+
+// Derive generates the extended child secret key for index.
+func (xsk *ExtendedSecret) Derive(index []byte) *ExtendedSecret {
+	var child = &Secret{}
+	xsk.secret.deriveInto(child, DerivationV3, EncodeIndex(xsk.chainCode[:], index), nil)
+	return &ExtendedSecret{secret: child, chainCode: childChainCode(&xsk.chainCode, index)}
+}
+
+// childChainCode computes chain' = sha3_256(chain_str || chain || index).
+func childChainCode(chainCode *Buffer256, index []byte) Buffer256 {
+	var hash = sha3.New256()
+	var res Buffer256
+	hash.Write([]byte("zed25519_chain_code"))
+	hash.Write(chainCode[:])
+	hash.Write(index)
+	hash.Sum(res[:0])
+	return res
+}
+
+// Marshal serializes the extended public key into 64 bytes, as the
+// compressed public key followed by the chain code.
+func (xpk *ExtendedPublic) Marshal() []byte {
+	var buf = make([]byte, 64)
+	var key = xpk.public.Key()
+	copy(buf[:32], key[:])
+	copy(buf[32:], xpk.chainCode[:])
+	return buf
+}
+
+// ParseExtendedPublic decodes an extended public key serialized by Marshal.
+func ParseExtendedPublic(buf []byte) (*ExtendedPublic, error) {
+	if l := len(buf); l != 64 {
+		return nil, errors.New("ParseExtendedPublic: bad extended public key length: " + strconv.Itoa(l))
+	}
+
+	var xpk = &ExtendedPublic{public: &Public{}}
+
+	// point = decompress(buf[:32]), or fail
+	var key Buffer256
+	copy(key[:], buf[:32])
+	if !DecompressPoint(&xpk.public.point, &key) {
+		return nil, errors.New("ParseExtendedPublic: invalid point")
+	}
+
+	copy(xpk.chainCode[:], buf[32:])
+	return xpk, nil
+}
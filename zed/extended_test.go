@@ -0,0 +1,106 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"bytes"
+	"testing"
+)
+
+// testExtended returns an extended secret key for tests.
+func testExtended() *ExtendedSecret {
+	return NewExtendedSecret(testSecret(12), Buffer256{1, 2, 3})
+}
+
+func TestExtendedPublicMarshal(t *testing.T) {
+	var xpk = testExtended().Public()
+	var buf = xpk.Marshal()
+	if len(buf) != 64 {
+		t.Fatalf("Marshal length = %d, want 64", len(buf))
+	}
+
+	var parsed, err = ParseExtendedPublic(buf)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if parsed.Public().Key() != xpk.Public().Key() || parsed.ChainCode() != xpk.ChainCode() {
+		t.Error("ParseExtendedPublic does not round-trip Marshal")
+	}
+	if !bytes.Equal(parsed.Marshal(), buf) {
+		t.Error("Marshal of a parsed key differs")
+	}
+
+	// children of the parsed key are the same
+	if parsed.Derive([]byte("0")).Public().Key() != xpk.Derive([]byte("0")).Public().Key() {
+		t.Error("parsed key derives different children")
+	}
+
+	if _, err := ParseExtendedPublic(buf[:63]); err == nil {
+		t.Error("ParseExtendedPublic accepted a short buffer")
+	}
+
+	// y = 2 is not on the curve
+	var bad = append(make([]byte, 32), buf[32:]...)
+	bad[0] = 2
+	if _, err := ParseExtendedPublic(bad); err == nil {
+		t.Error("ParseExtendedPublic accepted an invalid point")
+	}
+}
+
+func TestExtendedDerive(t *testing.T) {
+	var xsk = testExtended()
+	var xpk = xsk.Public()
+	var msg = []byte("watch-only")
+
+	// walk the same path on both sides
+	var csk, cpk = xsk, xpk
+	for _, index := range []string{"0", "1", "42"} {
+		csk = csk.Derive([]byte(index))
+		cpk = cpk.Derive([]byte(index))
+		if csk.Public().Public().Key() != cpk.Public().Key() {
+			t.Fatalf("child %q: secret and public children differ", index)
+		}
+		if csk.ChainCode() != cpk.ChainCode() {
+			t.Fatalf("child %q: chain codes differ", index)
+		}
+		var sig = csk.Secret().Sign(msg)
+		if !cpk.Public().Verify(msg, sig[:]) {
+			t.Fatalf("child %q: signature does not verify", index)
+		}
+	}
+
+	// the chain code changes the children
+	var other = NewExtendedPublic(xpk.Public(), Buffer256{4, 5, 6})
+	if other.Derive([]byte("0")).Public().Key() == xpk.Derive([]byte("0")).Public().Key() {
+		t.Error("children do not depend on the chain code")
+	}
+
+	// siblings have their own prefixes
+	var a, b = xsk.Derive([]byte("0")).Secret(), xsk.Derive([]byte("1")).Secret()
+	if a.Prefix() == b.Prefix() {
+		t.Error("sibling extended keys share a prefix")
+	}
+}
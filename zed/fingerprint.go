@@ -0,0 +1,82 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha256"
+	"encoding/base32"
+	"encoding/hex"
+	"strings"
+)
+
+//
+//  A fingerprint is a digest of a public key, for humans to compare keys
+//  by, rather than comparing the raw key in hex:
+//
+//    fingerprint = sha256(As)
+//
+//  It can be rendered as a short key ID (its first 8 bytes in hex), which is
+//  enough to refer to a key among a few, or as a safety number (the whole
+//  fingerprint in Base32, in groups of 4), which is what should be compared
+//  to check that two parties hold the same key. Base32 only uses upper-case
+//  letters and the digits 2 to 7, so no two of its characters are easily
+//  confused. The mnemonic package renders fingerprints as words.
+//
+
+// Fingerprint is the SHA-256 digest of a public key.
+type Fingerprint [32]byte
+
+// Fingerprint computes the fingerprint of the public key.
+func (pk *Public) Fingerprint() Fingerprint {
+	var As = pk.Key()
+	return sha256.Sum256(As[:])
+}
+
+// KeyID returns the short key ID of the fingerprint: its first 8 bytes in
+// lower-case hex, 16 characters long. Key IDs are convenient for referring to
+// a key, but are too short to check a key against.
+func (fp Fingerprint) KeyID() string {
+	return hex.EncodeToString(fp[:8])
+}
+
+// SafetyNumber returns the whole fingerprint as an upper-case Base32 string
+// without padding, in 13 groups of 4 characters separated by spaces, for
+// comparing keys by eye or over the phone.
+func (fp Fingerprint) SafetyNumber() string {
+	var s = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString(fp[:])
+	var groups = make([]string, 0, (len(s)+3)/4)
+	for len(s) > 4 {
+		groups = append(groups, s[:4])
+		s = s[4:]
+	}
+	groups = append(groups, s)
+	return strings.Join(groups, " ")
+}
+
+// String returns the whole fingerprint in lower-case hex.
+func (fp Fingerprint) String() string {
+	return hex.EncodeToString(fp[:])
+}
@@ -0,0 +1,409 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"strconv"
+)
+
+//
+//  FROST (Flexible Round-Optimized Schnorr Threshold signatures) lets any t
+//  of n participants, each holding a share of a group secret key, jointly
+//  produce an ordinary Ed25519 signature under the group public key, which
+//  verifies with plain Verify. Fewer than t participants learn nothing about
+//  the group key, and cannot sign.
+//
+//  Key generation here uses a trusted dealer, who splits an existing secret
+//  scalar a with Shamir's secret sharing, using a random polynomial of
+//  degree t - 1 with f(0) = a:
+//
+//    f(x) = a + c_1 * x + ... + c_t-1 * x^(t-1)
+//    a_i = f(i), A_i = a_i * G, for i = 1..n
+//
+//  Signing takes two rounds among any set S of at least t participants:
+//
+//    1. Commit: each participant i picks two fresh random nonces d_i and
+//       e_i, and publishes D_i = d_i * G and E_i = e_i * G.
+//
+//    2. Sign: given the list of commitments of S, each participant computes
+//
+//         rho_j = sha512(binding_str || As || sha512(m) || commitments || j) % q
+//         R = sum(D_j + rho_j * E_j), for j in S
+//         c = sha512(Rs || As || m) % q
+//         z_i = d_i + e_i * rho_i + l_i * a_i * c
+//
+//       where l_i is i's Lagrange coefficient for interpolating f(0) from S.
+//
+//  The coordinator checks each z_i with VerifyPartialSig, and the signature
+//  is (R, z), where z is the sum of all z_i. The binding factor rho ties each
+//  participant's nonce to the message and to every other participant's
+//  commitment, which makes it safe to run many signing sessions at once.
+//
+//  REFERENCES:
+//    [1] Chelsea Komlo, Ian Goldberg
+//        "FROST: Flexible Round-Optimized Schnorr Threshold Signatures"
+//        https://eprint.iacr.org/2020/852
+//
+
+// FrostGroup holds the public information of a FROST signing group: the
+// threshold t, the group public key, and the public share A_i of every
+// participant (Shares[i-1] for participant i).
+type FrostGroup struct {
+	Threshold int
+	Public    *Public
+	Shares    []*Public
+}
+
+// FrostShare is one participant's secret share of a FROST group key.
+type FrostShare struct {
+	Index uint16
+	Group *FrostGroup
+	share Scalar
+}
+
+// FrostCommitment is a participant's published nonce commitment for one
+// signing session.
+type FrostCommitment struct {
+	Index uint16
+	D     Buffer256
+	E     Buffer256
+}
+
+// FrostNonces holds a participant's secret nonces for one signing session.
+// They are destroyed by SignShare, and must never be reused.
+type FrostNonces struct {
+	d, e Scalar
+	used bool
+}
+
+// FrostSplit splits the secret key sk into n shares, any t of which can sign
+// together with FROST under sk's public key, as a trusted dealer. The dealer
+// learns every share, so should delete sk and the shares once they have been
+// distributed. It returns an error if t or n are out of range.
+func FrostSplit(sk *Secret, t, n int) (*FrostGroup, []*FrostShare, error) {
+	var group, shares, _, err = FrostSplitVerifiable(sk, t, n)
+	return group, shares, err
+}
+
+// FrostSplitVerifiable is the same as FrostSplit, but also returns the
+// Feldman VSS commitments to the sharing polynomial, which the dealer
+// publishes so that every participant can check its share with
+// FrostShare.Verify, without trusting the dealer.
+func FrostSplitVerifiable(sk *Secret, t, n int) (*FrostGroup, []*FrostShare, VssCommitments, error) {
+	if t < 1 || t > n || n > 65535 {
+		return nil, nil, nil, errors.New("FrostSplit: bad threshold " + strconv.Itoa(t) + " of " + strconv.Itoa(n))
+	}
+
+	// coef = (a % q, c_1, ..., c_t-1), with random c_k
+	var coef, err = vssPolynomial(&sk.scalar, t)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	var group = &FrostGroup{Threshold: t, Public: sk.Public()}
+	var shares = make([]*FrostShare, n)
+	for i := 1; i <= n; i++ {
+
+		// a_i = f(i)
+		var ai = vssEval(coef, uint16(i))
+
+		var Ai = &Public{}
+		ScalarMultBase(&Ai.point, &ai)
+		group.Shares = append(group.Shares, Ai)
+		shares[i-1] = &FrostShare{Index: uint16(i), Group: group, share: ai}
+	}
+	var commitments = vssCommit(coef)
+
+	for k := range coef {
+		wipe(coef[k][:])
+	}
+
+	return group, shares, commitments, nil
+}
+
+// Verify checks the share against the dealer's Feldman VSS commitments from
+// FrostSplitVerifiable, and that the commitments match the group's public
+// key and threshold.
+func (fs *FrostShare) Verify(commitments VssCommitments) bool {
+	if len(commitments) != fs.Group.Threshold || commitments[0] != fs.Group.Public.Key() {
+		return false
+	}
+	return VerifyShare(VssShare{Index: fs.Index, Value: fs.share}, commitments)
+}
+
+// Commit runs the first signing round for the share, returning the secret
+// nonces to keep for SignShare, and the commitment to send to the
+// coordinator. Nonces are drawn from the system's random number generator.
+func (fs *FrostShare) Commit() (*FrostNonces, FrostCommitment, error) {
+	var nonces = &FrostNonces{}
+	var com = FrostCommitment{Index: fs.Index}
+
+	for _, nonce := range []*Scalar{&nonces.d, &nonces.e} {
+
+		// nonce = sha512(nonce_str || random || a_i) % q
+		var random Buffer256
+		if _, err := rand.Read(random[:]); err != nil {
+			return nil, FrostCommitment{}, err
+		}
+		var hash = sha512.New()
+		var res Buffer512
+		hash.Write([]byte("zed25519_frost_nonce"))
+		hash.Write(random[:])
+		hash.Write(fs.share[:])
+		hash.Sum(res[:0])
+		ScalarReduce512(nonce, &res)
+		wipe(res[:])
+	}
+
+	// D = d * G, E = e * G
+	var D, E Point
+	ScalarMultBase(&D, &nonces.d)
+	ScalarMultBase(&E, &nonces.e)
+	CompressPoint(&com.D, &D)
+	CompressPoint(&com.E, &E)
+
+	return nonces, com, nil
+}
+
+// SignShare runs the second signing round for the share, producing its
+// partial signature z_i on msg, given the nonces from Commit and the
+// commitments of all the signing participants (including this one), sorted
+// by index. The nonces are destroyed, and cannot be used again.
+func (fs *FrostShare) SignShare(nonces *FrostNonces, msg []byte, commitments []FrostCommitment) (Scalar, error) {
+	if nonces.used {
+		return Scalar{}, errors.New("SignShare: nonces already used")
+	}
+
+	var sess, err = newFrostSession(fs.Group, msg, commitments)
+	if err != nil {
+		return Scalar{}, err
+	}
+	var pos = sess.position(fs.Index)
+	if pos < 0 {
+		return Scalar{}, errors.New("SignShare: participant " + strconv.Itoa(int(fs.Index)) + " is not in the commitments")
+	}
+
+	// check that the commitment is ours: D == d * G, E == e * G
+	var D, E Point
+	var Ds, Es Buffer256
+	ScalarMultBase(&D, &nonces.d)
+	ScalarMultBase(&E, &nonces.e)
+	CompressPoint(&Ds, &D)
+	CompressPoint(&Es, &E)
+	if Ds != commitments[pos].D || Es != commitments[pos].E {
+		return Scalar{}, errors.New("SignShare: commitment does not match nonces")
+	}
+
+	// z_i = d_i + e_i * rho_i + l_i * a_i * c
+	var lc, z Scalar
+	ScalarMultScalar(&lc, &sess.lambdas[pos], &sess.c)
+	ScalarMultScalarAddScalar(&z, &nonces.e, &sess.rhos[pos], &nonces.d)
+	ScalarMultScalarAddScalar(&z, &lc, &fs.share, &z)
+
+	// destroy the nonces
+	wipe(nonces.d[:])
+	wipe(nonces.e[:])
+	nonces.used = true
+
+	return z, nil
+}
+
+// AggregateShares checks every participant's partial signature with
+// VerifyPartialSig, and combines them into a signature on msg which is valid
+// for the group public key with plain Verify. commitments and shares must be
+// in the same order, sorted by participant index, with at least the group's
+// threshold of participants. If a partial signature is invalid, the error
+// names the participant who produced it.
+func AggregateShares(group *FrostGroup, msg []byte, commitments []FrostCommitment, shares []Scalar) (Signature, error) {
+	if len(shares) != len(commitments) {
+		return Signature{}, errors.New("AggregateShares: mismatched lengths")
+	}
+
+	var sess, err = newFrostSession(group, msg, commitments)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	var As = group.Public.Key()
+	var z Scalar
+	for i, com := range commitments {
+
+		// weighted share l_i * A_i
+		var share = &Public{}
+		ScalarMultPointVartime(&share.point, &sess.lambdas[i], &group.Shares[com.Index-1].point)
+
+		// commitment = Ri || R || A
+		var commitment = make([]byte, 0, 96)
+		commitment = append(commitment, sess.rs[i][:]...)
+		commitment = append(commitment, sess.groupRs[:]...)
+		commitment = append(commitment, As[:]...)
+
+		if !VerifyPartialSig(share, commitment, msg, shares[i]) {
+			return Signature{}, errors.New("AggregateShares: invalid partial signature from participant " + strconv.Itoa(int(com.Index)))
+		}
+
+		// z = z + z_i
+		ScalarMultScalarAddScalar(&z, &scalarOne, &z, &shares[i])
+	}
+
+	// sig = Rs || z
+	var sig Signature
+	copy(sig[:32], sess.groupRs[:])
+	copy(sig[32:], z[:])
+
+	return sig, nil
+}
+
+// frostSession holds the values derived from a list of commitments, which
+// every participant and the coordinator compute in the same way.
+type frostSession struct {
+	commitments []FrostCommitment
+	rhos        []Scalar
+	lambdas     []Scalar
+	rs          []Buffer256
+	groupRs     Buffer256
+	c           Scalar
+}
+
+// newFrostSession checks the commitments, and computes the binding factors,
+// Lagrange coefficients, nonce points and challenge for a signing session.
+func newFrostSession(group *FrostGroup, msg []byte, commitments []FrostCommitment) (*frostSession, error) {
+	if len(commitments) < group.Threshold {
+		return nil, errors.New("FROST: fewer commitments than the threshold")
+	}
+	for i, com := range commitments {
+		if com.Index < 1 || int(com.Index) > len(group.Shares) {
+			return nil, errors.New("FROST: bad participant index " + strconv.Itoa(int(com.Index)))
+		}
+		if i > 0 && com.Index <= commitments[i-1].Index {
+			return nil, errors.New("FROST: commitments not sorted by index")
+		}
+	}
+
+	var sess = &frostSession{
+		commitments: commitments,
+		rhos:        make([]Scalar, len(commitments)),
+		lambdas:     make([]Scalar, len(commitments)),
+		rs:          make([]Buffer256, len(commitments)),
+	}
+
+	// encoded = i || D_i || E_i, for every commitment
+	var encoded = make([]byte, 0, 66*len(commitments))
+	for _, com := range commitments {
+		encoded = append(encoded, byte(com.Index>>8), byte(com.Index))
+		encoded = append(encoded, com.D[:]...)
+		encoded = append(encoded, com.E[:]...)
+	}
+	var msgHash = sha512.Sum512(msg)
+	var As = group.Public.Key()
+
+	// R = sum(D_i + rho_i * E_i)
+	var R Point
+	PointIdentity(&R)
+	for i, com := range commitments {
+
+		// rho_i = sha512(binding_str || As || sha512(m) || encoded || i) % q
+		var hash = sha512.New()
+		var res Buffer512
+		var index [2]byte
+		binary.BigEndian.PutUint16(index[:], com.Index)
+		hash.Write([]byte("zed25519_frost_binding"))
+		hash.Write(As[:])
+		hash.Write(msgHash[:])
+		hash.Write(encoded)
+		hash.Write(index[:])
+		hash.Sum(res[:0])
+		ScalarReduce512(&sess.rhos[i], &res)
+
+		// R_i = D_i + rho_i * E_i
+		var D, E, rhoE, Ri Point
+		if !DecompressPoint(&D, &com.D) || !DecompressPoint(&E, &com.E) {
+			return nil, errors.New("FROST: invalid commitment from participant " + strconv.Itoa(int(com.Index)))
+		}
+		ScalarMultPointVartime(&rhoE, &sess.rhos[i], &E)
+		PointAdd(&Ri, &D, &rhoE)
+		CompressPoint(&sess.rs[i], &Ri)
+		PointAdd(&R, &R, &Ri)
+
+		sess.lambdas[i] = frostLagrange(commitments, i)
+	}
+	CompressPoint(&sess.groupRs, &R)
+
+	// c = sha512(Rs || As || m) % q
+	var hash = sha512.New()
+	var res Buffer512
+	hash.Write(sess.groupRs[:])
+	hash.Write(As[:])
+	hash.Write(msg)
+	hash.Sum(res[:0])
+	ScalarReduce512(&sess.c, &res)
+
+	return sess, nil
+}
+
+// position finds the position of a participant in the session's commitments,
+// or -1.
+func (sess *frostSession) position(index uint16) int {
+	for i, com := range sess.commitments {
+		if com.Index == index {
+			return i
+		}
+	}
+	return -1
+}
+
+// frostLagrange computes the Lagrange coefficient at 0 of the participant at
+// position pos among the signers:
+//
+//   l_i = prod(j / (j - i)), for j in S, j != i
+func frostLagrange(commitments []FrostCommitment, pos int) Scalar {
+	var num, den = scalarOne, scalarOne
+	var xi = frostIndexScalar(commitments[pos].Index)
+	for j, com := range commitments {
+		if j == pos {
+			continue
+		}
+		var xj = frostIndexScalar(com.Index)
+		var diff Scalar
+		ScalarSub(&diff, &xj, &xi)
+		ScalarMultScalar(&num, &num, &xj)
+		ScalarMultScalar(&den, &den, &diff)
+	}
+
+	var l Scalar
+	ScalarInvert(&den, &den)
+	ScalarMultScalar(&l, &num, &den)
+	return l
+}
+
+// frostIndexScalar encodes a participant index as a scalar.
+func frostIndexScalar(i uint16) Scalar {
+	return Scalar{byte(i), byte(i >> 8)}
+}
@@ -0,0 +1,151 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"strings"
+	"testing"
+)
+
+// frostRound runs both signing rounds for the shares of the given
+// participants, in order, returning their commitments and partial signatures.
+func frostRound(t *testing.T, shares []*FrostShare, signers []int, msg []byte) ([]FrostCommitment, []Scalar) {
+	var nonces = make([]*FrostNonces, len(signers))
+	var commitments = make([]FrostCommitment, len(signers))
+	for i, p := range signers {
+		var err error
+		nonces[i], commitments[i], err = shares[p-1].Commit()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	var partials = make([]Scalar, len(signers))
+	for i, p := range signers {
+		var err error
+		partials[i], err = shares[p-1].SignShare(nonces[i], msg, commitments)
+		if err != nil {
+			t.Fatalf("participant %d: SignShare = %v", p, err)
+		}
+	}
+	return commitments, partials
+}
+
+func TestFrost(t *testing.T) {
+	var sk = testSecret(20)
+	var group, shares, err = FrostSplit(sk, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var msg = []byte("frost message")
+
+	// any t or more participants sign under the group key
+	for _, signers := range [][]int{{1, 2, 3}, {1, 3, 5}, {2, 3, 4, 5}, {1, 2, 3, 4, 5}} {
+		var commitments, partials = frostRound(t, shares, signers, msg)
+		var sig, err = AggregateShares(group, msg, commitments, partials)
+		if err != nil {
+			t.Fatalf("%v: AggregateShares = %v", signers, err)
+		}
+		if !sk.Public().Verify(msg, sig[:]) {
+			t.Errorf("%v: signature does not verify", signers)
+		}
+		if sk.Public().Verify([]byte("other"), sig[:]) {
+			t.Errorf("%v: signature verifies on another message", signers)
+		}
+	}
+}
+
+func TestFrostCorruptShare(t *testing.T) {
+	var group, shares, _ = FrostSplit(testSecret(21), 2, 3)
+	var msg = []byte("frost message")
+	var commitments, partials = frostRound(t, shares, []int{1, 3}, msg)
+
+	partials[1][0] ^= 1
+	var _, err = AggregateShares(group, msg, commitments, partials)
+	if err == nil || !strings.Contains(err.Error(), "participant 3") {
+		t.Errorf("corrupted share: AggregateShares = %v", err)
+	}
+
+	// a share on another message is rejected too
+	partials[1][0] ^= 1
+	if _, err := AggregateShares(group, []byte("other"), commitments, partials); err == nil {
+		t.Error("AggregateShares accepted shares on another message")
+	}
+	if _, err := AggregateShares(group, msg, commitments, partials[:1]); err == nil {
+		t.Error("AggregateShares accepted mismatched lengths")
+	}
+}
+
+func TestFrostNonceReuse(t *testing.T) {
+	var _, shares, _ = FrostSplit(testSecret(22), 2, 3)
+	var msg = []byte("frost message")
+
+	var n1, c1, _ = shares[0].Commit()
+	var _, c2, _ = shares[1].Commit()
+	var commitments = []FrostCommitment{c1, c2}
+	if _, err := shares[0].SignShare(n1, msg, commitments); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := shares[0].SignShare(n1, []byte("other"), commitments); err == nil {
+		t.Error("SignShare reused nonces")
+	}
+
+	// nonces only sign for their own commitment
+	var n3, _, _ = shares[0].Commit()
+	if _, err := shares[0].SignShare(n3, msg, commitments); err == nil {
+		t.Error("SignShare accepted nonces for another commitment")
+	}
+}
+
+func TestFrostTooFewCommitments(t *testing.T) {
+	var group, shares, _ = FrostSplit(testSecret(23), 3, 5)
+	var msg = []byte("frost message")
+
+	var n1, c1, _ = shares[0].Commit()
+	var _, c2, _ = shares[1].Commit()
+	var commitments = []FrostCommitment{c1, c2}
+	if _, err := shares[0].SignShare(n1, msg, commitments); err == nil {
+		t.Error("SignShare accepted fewer commitments than the threshold")
+	}
+	if _, err := AggregateShares(group, msg, commitments, make([]Scalar, 2)); err == nil {
+		t.Error("AggregateShares accepted fewer commitments than the threshold")
+	}
+
+	// unsorted or duplicate commitments are rejected too
+	var _, c3, _ = shares[2].Commit()
+	for _, bad := range [][]FrostCommitment{{c1, c3, c2}, {c1, c2, c2}} {
+		if _, err := AggregateShares(group, msg, bad, make([]Scalar, 3)); err == nil {
+			t.Errorf("AggregateShares accepted commitments %d, %d, %d", bad[0].Index, bad[1].Index, bad[2].Index)
+		}
+	}
+}
+
+func TestFrostSplitErrors(t *testing.T) {
+	for _, tn := range [][2]int{{0, 3}, {4, 3}, {1, 0}, {2, 70000}} {
+		if _, _, err := FrostSplit(testSecret(24), tn[0], tn[1]); err == nil {
+			t.Errorf("FrostSplit accepted %d of %d", tn[0], tn[1])
+		}
+	}
+}
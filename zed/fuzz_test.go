@@ -0,0 +1,116 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"testing"
+)
+
+// fuzzPublic decodes key as a public key, accepting non-canonical encodings,
+// or returns nil if it is not a valid point.
+func fuzzPublic(key []byte) *Public {
+	if len(key) != 32 {
+		return nil
+	}
+	var pk = &Public{}
+	var kb Buffer256
+	copy(kb[:], key)
+	if !DecompressPoint(&pk.point, &kb) {
+		return nil
+	}
+	return pk
+}
+
+// FuzzVerify checks that Verify and the functions built on it never panic on
+// arbitrary keys, messages and signatures, and agree with each other.
+func FuzzVerify(f *testing.F) {
+	var sk = testSecret(1)
+	var key = sk.Public().Key()
+	var sig = sk.Sign([]byte("message"))
+	f.Add(key[:], []byte("message"), sig[:])
+	f.Add(key[:], []byte("message"), sig[:63])
+	f.Add(key[:], []byte(""), make([]byte, 64))
+	f.Add(SmallOrderPoints[0][:], []byte("message"), append(SmallOrderPoints[0][:], make([]byte, 32)...))
+
+	f.Fuzz(func(t *testing.T, key, msg, sig []byte) {
+		VerifyWithOpts(key, msg, sig, VerifyOpts{})
+		VerifyWithOpts(key, msg, sig, VerifyOpts{ZIP215: true, RejectNonCanonicalR: true})
+
+		var pk = fuzzPublic(key)
+		if pk == nil {
+			return
+		}
+		var ok = pk.Verify(msg, sig)
+		pk.VerifyStrict(msg, sig)
+		pk.VerifyCanonical(msg, sig)
+
+		var ps, err = ParseSignature(sig)
+		if err != nil {
+			if ok {
+				t.Fatal("Verify accepted a signature which does not parse")
+			}
+			return
+		}
+		if pk.VerifyParsed(msg, ps) != ok {
+			t.Fatal("VerifyParsed disagrees with Verify")
+		}
+	})
+}
+
+// FuzzVrfVerify checks that VrfVerify and the functions built on it never
+// panic on arbitrary keys, inputs and proofs, and agree with each other.
+func FuzzVrfVerify(f *testing.F) {
+	var sk = testSecret(1)
+	var key = sk.Public().Key()
+	var _, proof = sk.VrfEval([]byte("input"))
+	f.Add(key[:], []byte("input"), proof[:])
+	f.Add(key[:], []byte("input"), proof[:95])
+	f.Add(key[:], []byte(""), make([]byte, 96))
+	f.Add(key[:], []byte("input"), append(SmallOrderPoints[0][:], make([]byte, 64)...))
+
+	f.Fuzz(func(t *testing.T, key, x, proof []byte) {
+		ParseVrfProof(proof)
+
+		var pk = fuzzPublic(key)
+		if pk == nil {
+			return
+		}
+		var y, ok = pk.VrfVerify(x, proof)
+		var ye, err = pk.VrfVerifyErr(x, proof)
+		if ok != (err == nil) || y != ye {
+			t.Fatal("VrfVerifyErr disagrees with VrfVerify")
+		}
+		if !ok && y != (VrfResult{}) {
+			t.Fatal("VrfVerify returned a non-zero output for an invalid proof")
+		}
+		if ys, okStrict := pk.VrfVerifyStrict(x, proof); okStrict && (!ok || ys != y) {
+			t.Fatal("VrfVerifyStrict accepted a proof which VrfVerify rejects")
+		}
+		if encoded, err := EncodeProof(pk, proof); err == nil {
+			DecodeProof(pk, encoded)
+		}
+	})
+}
@@ -0,0 +1,213 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+//
+//  Half-aggregation combines n signatures (R_i, s_i) by A_i on distinct
+//  messages m_i into one aggregate signature, which keeps every R_i but
+//  replaces the n scalars s_i by a single scalar, so that it takes 32 * (n + 1)
+//  bytes instead of 64 * n:
+//
+//    T   = sha512(halfagg_str || n || R_1 || A_1 || len(m_1) || m_1 || ...
+//                                  || R_n || A_n || len(m_n) || m_n)
+//    z_i = sha512(T || i) % q
+//    s   = z_1 * s_1 + ... + z_n * s_n
+//    agg = R_1 || ... || R_n || s
+//
+//  with n, len(m_i) as 8-byte and i as 4-byte big-endian integers. Anyone can
+//  aggregate signatures, without any secret key or interaction with the
+//  signers. The aggregate is checked like a batch (see VerifyBatch), except
+//  that the coefficients z_i are derived from the whole batch rather than
+//  chosen at random, since the s_i are no longer available separately:
+//
+//    8 * (-s * G + z_1 * R_1 + ... + z_n * R_n
+//                + (z_1 * h_1) * A_1 + ... + (z_n * h_n) * A_n) == I
+//
+//  where h_i = sha512(R_i || A_i || m_i) is the challenge of each signature.
+//  Since every z_i depends on every key, message and R_i, a forger cannot
+//  choose invalid signatures whose errors cancel out. As for VerifyBatch, the
+//  check is cofactored, so an aggregate of signatures which Verify accepts
+//  always verifies, but a crafted signature with a small-order component
+//  which Verify rejects may still verify as part of an aggregate.
+//
+//  The s_i cannot be recovered from the aggregate, so the aggregate cannot be
+//  split back into individual signatures, nor can a signature be removed from
+//  it; it must be verified as a whole, for the same keys and messages in the
+//  same order.
+//
+//  REFERENCES:
+//    [1] Konstantinos Chalkias, François Garillot, Yashvanth Kondi, Valeria
+//        Nikolaenko, "Non-interactive half-aggregation of EdDSA and variants
+//        of Schnorr signatures"
+//        https://eprint.iacr.org/2021/350
+//
+
+// Aggregate half-aggregates the signatures sigs, where sigs[i] is a signature
+// by pks[i] on msgs[i], into one aggregate signature of 32 * (n + 1) bytes,
+// as described above. The signatures are not verified, so the aggregate of
+// any invalid signature is itself invalid. An error is returned if the
+// slices are empty or have different lengths, or naming the first signature
+// which could never be valid (see ParseSignature).
+func Aggregate(pks []*Public, msgs [][]byte, sigs []Signature) ([]byte, error) {
+	if len(pks) != len(msgs) || len(pks) != len(sigs) {
+		return nil, errors.New("Aggregate: mismatched lengths")
+	}
+	if len(pks) == 0 {
+		return nil, errors.New("Aggregate: no signatures")
+	}
+
+	// agg = R_1 || ... || R_n
+	var n = len(pks)
+	var agg = make([]byte, 0, 32*(n+1))
+	var ss = make([]Scalar, n)
+	for i := range sigs {
+		var ps, err = ParseSignature(sigs[i][:])
+		if err != nil {
+			return nil, fmt.Errorf("Aggregate: signature %d: %w", i, err)
+		}
+		agg = append(agg, ps.rs[:]...)
+		ss[i] = ps.s
+	}
+
+	// s = z_1 * s_1 + ... + z_n * s_n
+	var zs = halfAggCoefficients(pks, msgs, agg)
+	var s Scalar
+	for i := range zs {
+		ScalarMultScalarAddScalar(&s, &zs[i], &ss[i], &s)
+	}
+
+	// agg = R_1 || ... || R_n || s
+	return append(agg, s[:]...), nil
+}
+
+// VerifyAggregate checks whether agg is a valid half-aggregate, made by
+// Aggregate, of signatures by pks[i] on msgs[i] for each i. It returns false
+// if the slices are empty or have different lengths, or agg has the wrong
+// length for them.
+func VerifyAggregate(pks []*Public, msgs [][]byte, agg []byte) bool {
+	var n = len(pks)
+	if n == 0 || len(msgs) != n || len(agg) != 32*(n+1) {
+		return false
+	}
+
+	// s = agg[32n:], or fail if not reduced
+	var s Scalar
+	copy(s[:], agg[32*n:])
+	if !ValidScalar(&s) {
+		return false
+	}
+
+	// scalars = (-s, z_1, z_1 * h_1, ..., z_n, z_n * h_n)
+	// points  = (G, R_1, A_1, ..., R_n, A_n)
+	var scalars = make([]Scalar, 1+2*n)
+	var points = make([]Point, 1+2*n)
+	BasePoint(&points[0])
+	ScalarNeg(&scalars[0], &s)
+	var scalarPtrs = make([]*Scalar, 1+2*n)
+	var pointPtrs = make([]*Point, 1+2*n)
+	for i := range scalars {
+		scalarPtrs[i] = &scalars[i]
+		pointPtrs[i] = &points[i]
+	}
+
+	var zs = halfAggCoefficients(pks, msgs, agg[:32*n])
+	var hash = sha512.New()
+	var res Buffer512
+	for i := range pks {
+
+		// R = decompress(Rs), or fail
+		var Rs Buffer256
+		copy(Rs[:], agg[32*i:])
+		if !DecompressPoint(&points[1+2*i], &Rs) {
+			return false
+		}
+
+		// h = sha512(Rs || As || m) % q
+		var As = pks[i].Key()
+		var h Scalar
+		hash.Reset()
+		hash.Write(Rs[:])
+		hash.Write(As[:])
+		hash.Write(msgs[i])
+		hash.Sum(res[:0])
+		ScalarReduce512(&h, &res)
+
+		scalars[1+2*i] = zs[i]
+		ScalarMultScalar(&scalars[2+2*i], &zs[i], &h)
+		points[2+2*i] = pks[i].point
+	}
+
+	// valid if: 8 * sum == I
+	var sum, cSum, I Point
+	MultiScalarMult(&sum, scalarPtrs, pointPtrs)
+	PointClearCofactor(&cSum, &sum)
+	PointIdentity(&I)
+
+	return PointEqual(&cSum, &I)
+}
+
+// halfAggCoefficients computes the coefficients z_i = sha512(T || i) % q,
+// with T the hash of the whole batch, where Rs holds R_1 || ... || R_n.
+func halfAggCoefficients(pks []*Public, msgs [][]byte, Rs []byte) []Scalar {
+	var hash = sha512.New()
+	var l [8]byte
+
+	// T = sha512(halfagg_str || n || R_1 || A_1 || len(m_1) || m_1 || ...)
+	var T Buffer512
+	hash.Write([]byte("zed25519_halfagg"))
+	binary.BigEndian.PutUint64(l[:], uint64(len(pks)))
+	hash.Write(l[:])
+	for i, pk := range pks {
+		var As = pk.Key()
+		hash.Write(Rs[32*i : 32*(i+1)])
+		hash.Write(As[:])
+		binary.BigEndian.PutUint64(l[:], uint64(len(msgs[i])))
+		hash.Write(l[:])
+		hash.Write(msgs[i])
+	}
+	hash.Sum(T[:0])
+
+	// z_i = sha512(T || i) % q
+	var zs = make([]Scalar, len(pks))
+	var res Buffer512
+	for i := range zs {
+		binary.BigEndian.PutUint32(l[:4], uint32(i))
+		hash.Reset()
+		hash.Write(T[:])
+		hash.Write(l[:4])
+		hash.Sum(res[:0])
+		ScalarReduce512(&zs[i], &res)
+	}
+
+	return zs
+}
@@ -0,0 +1,118 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import "testing"
+
+// testHalfAgg returns n keys, messages and their signatures.
+func testHalfAgg(n int) ([]*Public, [][]byte, []Signature) {
+	var pks = make([]*Public, n)
+	var msgs = make([][]byte, n)
+	var sigs = make([]Signature, n)
+	for i := range pks {
+		var sk = testSecret(byte(i + 1))
+		pks[i] = sk.Public()
+		msgs[i] = []byte{'m', byte(i)}
+		sigs[i] = sk.Sign(msgs[i])
+	}
+	return pks, msgs, sigs
+}
+
+func TestHalfAggregate(t *testing.T) {
+	for _, n := range []int{1, 2, 5} {
+		var pks, msgs, sigs = testHalfAgg(n)
+		var agg, err = Aggregate(pks, msgs, sigs)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(agg) != 32*(n+1) {
+			t.Errorf("n = %d: aggregate has %d bytes", n, len(agg))
+		}
+		if !VerifyAggregate(pks, msgs, agg) {
+			t.Errorf("n = %d: aggregate does not verify", n)
+		}
+	}
+}
+
+func TestHalfAggregateInvalid(t *testing.T) {
+	var pks, msgs, sigs = testHalfAgg(3)
+	var agg, _ = Aggregate(pks, msgs, sigs)
+
+	// swapped keys, with their messages in place
+	var swapped = []*Public{pks[1], pks[0], pks[2]}
+	if VerifyAggregate(swapped, msgs, agg) {
+		t.Error("aggregate verifies with swapped keys")
+	}
+
+	// swapped keys and messages, which changes every coefficient
+	var swappedMsgs = [][]byte{msgs[1], msgs[0], msgs[2]}
+	if VerifyAggregate(swapped, swappedMsgs, agg) {
+		t.Error("aggregate verifies in another order")
+	}
+
+	if VerifyAggregate(pks[:2], msgs[:2], agg[:96]) {
+		t.Error("aggregate verifies with a signature removed")
+	}
+	for _, l := range []int{0, 32, len(agg) - 1} {
+		if VerifyAggregate(pks, msgs, agg[:l]) {
+			t.Errorf("aggregate truncated to %d bytes verifies", l)
+		}
+	}
+	if VerifyAggregate(pks, msgs[:2], agg) || VerifyAggregate(nil, nil, nil) {
+		t.Error("VerifyAggregate accepted mismatched lengths")
+	}
+
+	var tampered = append([]byte{}, agg...)
+	tampered[len(tampered)-32] ^= 1
+	if VerifyAggregate(pks, msgs, tampered) {
+		t.Error("aggregate verifies with a tampered s")
+	}
+
+	// one invalid signature makes the whole aggregate invalid
+	var bad = append([]Signature{}, sigs...)
+	bad[1] = testSecret(9).Sign(msgs[1])
+	agg, _ = Aggregate(pks, msgs, bad)
+	if VerifyAggregate(pks, msgs, agg) {
+		t.Error("aggregate of an invalid signature verifies")
+	}
+}
+
+func TestHalfAggregateErrors(t *testing.T) {
+	var pks, msgs, sigs = testHalfAgg(2)
+	if _, err := Aggregate(pks, msgs[:1], sigs); err == nil {
+		t.Error("Aggregate accepted mismatched lengths")
+	}
+	if _, err := Aggregate(nil, nil, nil); err == nil {
+		t.Error("Aggregate accepted no signatures")
+	}
+	var high = append([]Signature{}, sigs...)
+	for i := 32; i < 64; i++ {
+		high[1][i] = 0xff
+	}
+	if _, err := Aggregate(pks, msgs, high); err == nil {
+		t.Error("Aggregate accepted a non-canonical s")
+	}
+}
@@ -0,0 +1,293 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha512"
+)
+
+//
+//  RFC 9380 hash-to-curve for edwards25519, with the suites
+//
+//    edwards25519_XMD:SHA-512_ELL2_RO_   (HashToCurve, random oracle)
+//    edwards25519_XMD:SHA-512_ELL2_NU_   (EncodeToCurve, non-uniform)
+//
+//  Unlike HashToPointVartime, these run in constant time and give the same
+//  points as every other conforming implementation, for the same message and
+//  domain separation tag (DST):
+//
+//    RO: u0, u1 = hash_to_field(msg, 2)
+//        P = 8 * (map_to_curve(u0) + map_to_curve(u1))
+//    NU: u = hash_to_field(msg, 1)
+//        P = 8 * map_to_curve(u)
+//
+//  hash_to_field uses expand_message_xmd with SHA-512, taking 48 bytes per
+//  field element, and map_to_curve is the Elligator 2 map to curve25519
+//  followed by the rational map to edwards25519 (RFC 9380 appendix G.2).
+//
+//  The DST must be unique to the protocol and its use of hash-to-curve, e.g.
+//  "MyApp-V01-CS02-with-" followed by the suite name.
+//
+
+// HashToCurveSuite and EncodeToCurveSuite are the RFC 9380 suite identifiers
+// of HashToCurve and EncodeToCurve, for use in domain separation tags.
+const (
+	HashToCurveSuite   = "edwards25519_XMD:SHA-512_ELL2_RO_"
+	EncodeToCurveSuite = "edwards25519_XMD:SHA-512_ELL2_NU_"
+)
+
+// HashToCurve hashes msg to a point of the prime-order subgroup with the RFC
+// 9380 edwards25519_XMD:SHA-512_ELL2_RO_ suite, under the domain separation
+// tag dst. The output is indistinguishable from a random point. It panics if
+// dst is empty.
+func HashToCurve(r *Point, msg, dst []byte) {
+	if len(dst) == 0 {
+		panic("HashToCurve: empty domain separation tag")
+	}
+	var u [2]FieldElement
+	hashToField(u[:], msg, dst)
+
+	// P = 8 * (map_to_curve(u0) + map_to_curve(u1))
+	var Q0, Q1, R Point
+	mapToCurveEll2(&Q0, &u[0])
+	mapToCurveEll2(&Q1, &u[1])
+	PointAdd(&R, &Q0, &Q1)
+	PointClearCofactor(r, &R)
+}
+
+// EncodeToCurve hashes msg to a point of the prime-order subgroup with the RFC
+// 9380 edwards25519_XMD:SHA-512_ELL2_NU_ suite, under the domain separation
+// tag dst. It is about twice as fast as HashToCurve, but its output is not
+// uniformly distributed, so it must only be used where that is acceptable.
+// It panics if dst is empty.
+func EncodeToCurve(r *Point, msg, dst []byte) {
+	if len(dst) == 0 {
+		panic("EncodeToCurve: empty domain separation tag")
+	}
+	var u [1]FieldElement
+	hashToField(u[:], msg, dst)
+
+	// P = 8 * map_to_curve(u)
+	var Q Point
+	mapToCurveEll2(&Q, &u[0])
+	PointClearCofactor(r, &Q)
+}
+
+// expandMessageXMD is expand_message_xmd from RFC 9380 section 5.3.1, with
+// SHA-512 as the hash function. length must be at most 255 * 64.
+func expandMessageXMD(msg, dst []byte, length int) []byte {
+	var hash = sha512.New()
+
+	// DST_prime = DST || len(DST), hashing oversized tags first
+	if len(dst) > 255 {
+		hash.Write([]byte("H2C-OVERSIZE-DST-"))
+		hash.Write(dst)
+		dst = hash.Sum(nil)
+		hash.Reset()
+	}
+	var dstPrime = append(append([]byte{}, dst...), byte(len(dst)))
+
+	// b0 = H(Z_pad || msg || l_i_b_str || 0 || DST_prime)
+	var b0 Buffer512
+	hash.Write(make([]byte, hash.BlockSize()))
+	hash.Write(msg)
+	hash.Write([]byte{byte(length >> 8), byte(length), 0})
+	hash.Write(dstPrime)
+	hash.Sum(b0[:0])
+
+	// b1 = H(b0 || 1 || DST_prime)
+	// bi = H((b0 xor b(i-1)) || i || DST_prime)
+	var out = make([]byte, 0, length+sha512.Size)
+	var bi, xored Buffer512
+	for i := 1; len(out) < length; i++ {
+		for j := range xored {
+			xored[j] = b0[j] ^ bi[j]
+		}
+		hash.Reset()
+		hash.Write(xored[:])
+		hash.Write([]byte{byte(i)})
+		hash.Write(dstPrime)
+		hash.Sum(bi[:0])
+		out = append(out, bi[:]...)
+	}
+	wipe(b0[:])
+	wipe(xored[:])
+	return out[:length]
+}
+
+// hashToField is hash_to_field from RFC 9380 section 5.2, filling u with
+// field elements each reduced from 48 bytes of expand_message_xmd output.
+func hashToField(u []FieldElement, msg, dst []byte) {
+	var uniform = expandMessageXMD(msg, dst, 48*len(u))
+	for i := range u {
+		feFromBytes48(&u[i], uniform[48*i:48*i+48])
+	}
+}
+
+// feFromBytes48 reduces a 48-byte big-endian integer modulo p. Writing it as
+// hi * 2^256 + lo, and since 2^256 = 38 mod p, it equals hi * 38 + lo, and lo
+// itself is its low 255 bits plus 19 if bit 255 is set.
+func feFromBytes48(r *FieldElement, b []byte) {
+	var lo, hi, c [32]byte
+	for i := 0; i < 32; i++ {
+		lo[i] = b[47-i]
+	}
+	for i := 0; i < 16; i++ {
+		hi[i] = b[15-i]
+	}
+	c[0] = 38
+
+	// r = low255(lo) + 19 * bit255(lo) + 38 * hi
+	var fLo, fHi, f38, fTop FieldElement
+	FeFromBytes(&fLo, &lo)
+	FeFromBytes(&fHi, &hi)
+	FeFromBytes(&f38, &c)
+	FeMul(&fHi, &fHi, &f38)
+	c[0] = 19 * (lo[31] >> 7)
+	FeFromBytes(&fTop, &c)
+	FeAdd(r, &fLo, &fHi)
+	FeAdd(r, r, &fTop)
+}
+
+// feEqual reports whether a == b, in constant time.
+func feEqual(a, b *FieldElement) int32 {
+	var d FieldElement
+	FeSub(&d, a, b)
+	return 1 ^ FeIsNonZero(&d)
+}
+
+// feConstant returns the field element of a small integer.
+func feConstant(n uint32) FieldElement {
+	var b [32]byte
+	b[0], b[1], b[2], b[3] = byte(n), byte(n>>8), byte(n>>16), byte(n>>24)
+	var f FieldElement
+	FeFromBytes(&f, &b)
+	return f
+}
+
+// ell2J is the Montgomery curve25519 constant J = 486662; ell2C2 is
+// 2^((p + 3) / 8); ell2C1 is sqrt(-486664) with sgn0 = 0, as required by
+// RFC 9380 appendix G.2.2.
+var ell2J = feConstant(486662)
+var ell2C2 = ell2Pow2()
+var ell2C1 = ell2SqrtNeg486664()
+
+func ell2Pow2() FieldElement {
+	// 2^((p + 3) / 8) = 2 * 2^((p - 5) / 8)
+	var two = feConstant(2)
+	var r FieldElement
+	fePow22523(&r, &two)
+	FeMul(&r, &r, &two)
+	return r
+}
+
+func ell2SqrtNeg486664() FieldElement {
+	// r = x^((p + 3) / 8), times sqrt(-1) if r^2 != x, negated if odd
+	var x, r, r2 FieldElement
+	var c = feConstant(486664)
+	FeNeg(&x, &c)
+	fePow22523(&r, &x)
+	FeMul(&r, &r, &x)
+	FeSquare(&r2, &r)
+	if feEqual(&r2, &x) == 0 {
+		FeMul(&r, &r, &SqrtM1)
+	}
+	if FeIsNegative(&r) == 1 {
+		FeNeg(&r, &r)
+	}
+	return r
+}
+
+// mapToCurveEll2 is the straight-line Elligator 2 map of RFC 9380 appendix
+// G.2.1 to curve25519, followed by the rational map to edwards25519 of
+// appendix G.2.2. It runs in constant time.
+func mapToCurveEll2(r *Point, u *FieldElement) {
+	var one, tv1, tv2, tv3, xd, x1n, x2n, gxd, gx1, gx2 FieldElement
+	var y11, y12, y21, y22, y1, y2, y, yNeg FieldElement
+	FeOne(&one)
+
+	// curve25519: (xn / xd, y)
+	FeSquare(&tv1, u)
+	FeAdd(&tv1, &tv1, &tv1)
+	FeAdd(&xd, &tv1, &one)
+	FeNeg(&x1n, &ell2J)
+	FeSquare(&tv2, &xd)
+	FeMul(&gxd, &tv2, &xd)
+	FeMul(&gx1, &ell2J, &tv1)
+	FeMul(&gx1, &gx1, &x1n)
+	FeAdd(&gx1, &gx1, &tv2)
+	FeMul(&gx1, &gx1, &x1n)
+	FeSquare(&tv3, &gxd)
+	FeSquare(&tv2, &tv3)
+	FeMul(&tv3, &tv3, &gxd)
+	FeMul(&tv3, &tv3, &gx1)
+	FeMul(&tv2, &tv2, &tv3)
+	fePow22523(&y11, &tv2)
+	FeMul(&y11, &y11, &tv3)
+	FeMul(&y12, &y11, &SqrtM1)
+	FeSquare(&tv2, &y11)
+	FeMul(&tv2, &tv2, &gxd)
+	FeCopy(&y1, &y12)
+	FeCMove(&y1, &y11, feEqual(&tv2, &gx1))
+	FeMul(&x2n, &x1n, &tv1)
+	FeMul(&y21, &y11, u)
+	FeMul(&y21, &y21, &ell2C2)
+	FeMul(&y22, &y21, &SqrtM1)
+	FeMul(&gx2, &gx1, &tv1)
+	FeSquare(&tv2, &y21)
+	FeMul(&tv2, &tv2, &gxd)
+	FeCopy(&y2, &y22)
+	FeCMove(&y2, &y21, feEqual(&tv2, &gx2))
+	FeSquare(&tv2, &y1)
+	FeMul(&tv2, &tv2, &gxd)
+	var e3 = feEqual(&tv2, &gx1)
+	var xn FieldElement
+	FeCopy(&xn, &x2n)
+	FeCMove(&xn, &x1n, e3)
+	FeCopy(&y, &y2)
+	FeCMove(&y, &y1, e3)
+	FeNeg(&yNeg, &y)
+	FeCMove(&y, &yNeg, e3^int32(FeIsNegative(&y)))
+
+	// edwards25519: (en / ed, fn / fd)
+	var en, ed, fn, fd, tv FieldElement
+	FeMul(&en, &xn, &ell2C1)
+	FeMul(&ed, &xd, &y)
+	FeSub(&fn, &xn, &xd)
+	FeAdd(&fd, &xn, &xd)
+	FeMul(&tv, &ed, &fd)
+	var e = 1 ^ FeIsNonZero(&tv)
+	FeCMove(&en, &zero, e)
+	FeCMove(&ed, &one, e)
+	FeCMove(&fn, &one, e)
+	FeCMove(&fd, &one, e)
+
+	// (X : Y : Z : T) = (en * fd : fn * ed : ed * fd : en * fn)
+	FeMul(&r.X, &en, &fd)
+	FeMul(&r.Y, &fn, &ed)
+	FeMul(&r.Z, &ed, &fd)
+	FeMul(&r.T, &en, &fn)
+}
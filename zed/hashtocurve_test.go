@@ -0,0 +1,115 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+// Test vectors of RFC 9380 appendix J.5, with P given by its affine
+// coordinates as big-endian hex.
+var hashToCurveTests = []struct {
+	suite string
+	dst   string
+	hash  func(r *Point, msg, dst []byte)
+	msgs  []struct{ msg, x, y string }
+}{
+	{"RO", "QUUX-V01-CS02-with-" + HashToCurveSuite, HashToCurve, []struct{ msg, x, y string }{
+		{"",
+			"3c3da6925a3c3c268448dcabb47ccde5439559d9599646a8260e47b1e4822fc6",
+			"09a6c8561a0b22bef63124c588ce4c62ea83a3c899763af26d795302e115dc21"},
+		{"abc",
+			"608040b42285cc0d72cbb3985c6b04c935370c7361f4b7fbdb1ae7f8c1a8ecad",
+			"1a8395b88338f22e435bbd301183e7f20a5f9de643f11882fb237f88268a5531"},
+		{"abcdef0123456789",
+			"6d7fabf47a2dc03fe7d47f7dddd21082c5fb8f86743cd020f3fb147d57161472",
+			"53060a3d140e7fbcda641ed3cf42c88a75411e648a1add71217f70ea8ec561a6"},
+		{"q128_" + strings.Repeat("q", 128),
+			"5fb0b92acedd16f3bcb0ef83f5c7b7a9466b5f1e0d8d217421878ea3686f8524",
+			"2eca15e355fcfa39d2982f67ddb0eea138e2994f5956ed37b7f72eea5e89d2f7"},
+		{"a512_" + strings.Repeat("a", 512),
+			"0efcfde5898a839b00997fbe40d2ebe950bc81181afbd5cd6b9618aa336c1e8c",
+			"6dc2fc04f266c5c27f236a80b14f92ccd051ef1ff027f26a07f8c0f327d8f995"},
+	}},
+	{"NU", "QUUX-V01-CS02-with-" + EncodeToCurveSuite, EncodeToCurve, []struct{ msg, x, y string }{
+		{"",
+			"1ff2b70ecf862799e11b7ae744e3489aa058ce805dd323a936375a84695e76da",
+			"222e314d04a4d5725e9f2aff9fb2a6b69ef375a1214eb19021ceab2d687f0f9b"},
+		{"abc",
+			"5f13cc69c891d86927eb37bd4afc6672360007c63f68a33ab423a3aa040fd2a8",
+			"67732d50f9a26f73111dd1ed5dba225614e538599db58ba30aaea1f5c827fa42"},
+		{"abcdef0123456789",
+			"1dd2fefce934ecfd7aae6ec998de088d7dd03316aa1847198aecf699ba6613f1",
+			"2f8a6c24dd1adde73909cada6a4a137577b0f179d336685c4a955a0a8e1a86fb"},
+		{"q128_" + strings.Repeat("q", 128),
+			"35fbdc5143e8a97afd3096f2b843e07df72e15bfca2eaf6879bf97c5d3362f73",
+			"2af6ff6ef5ebba128b0774f4296cb4c2279a074658b083b8dcca91f57a603450"},
+		{"a512_" + strings.Repeat("a", 512),
+			"6e5e1f37e99345887fc12111575fc1c3e36df4b289b8759d23af14d774b66bff",
+			"2c90c3d39eb18ff291d33441b35f3262cdd307162cc97c31bfcc7a4245891a37"},
+	}},
+}
+
+// affineToKey encodes the point with the big-endian affine coordinates x and
+// y as Ed25519 does: y in little-endian, with the sign of x in the top bit.
+func affineToKey(t *testing.T, x, y string) Buffer256 {
+	var xb, err1 = hex.DecodeString(x)
+	var yb, err2 = hex.DecodeString(y)
+	if err1 != nil || err2 != nil || len(xb) != 32 || len(yb) != 32 {
+		t.Fatalf("bad coordinates %s, %s", x, y)
+	}
+	var key Buffer256
+	for i := range yb {
+		key[i] = yb[31-i]
+	}
+	key[31] |= (xb[31] & 1) << 7
+	return key
+}
+
+func TestHashToCurve(t *testing.T) {
+	for _, suite := range hashToCurveTests {
+		for _, test := range suite.msgs {
+			var P Point
+			suite.hash(&P, []byte(test.msg), []byte(suite.dst))
+
+			var want = affineToKey(t, test.x, test.y)
+			if got := PointToKey(&P); got != want {
+				t.Errorf("%s(%.10q) = %x, want %x", suite.suite, test.msg, got, want)
+			}
+			if !pointTorsionFree(&P) {
+				t.Errorf("%s(%.10q) is not in the prime-order subgroup", suite.suite, test.msg)
+			}
+		}
+	}
+}
+
+func TestHashToCurveEmptyDST(t *testing.T) {
+	var P Point
+	expectPanic(t, "HashToCurve", func() { HashToCurve(&P, []byte("msg"), nil) })
+	expectPanic(t, "EncodeToCurve", func() { EncodeToCurve(&P, []byte("msg"), nil) })
+}
@@ -0,0 +1,141 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+//
+//  JOSE support for Ed25519 keys and signatures, following RFC 8037:
+//
+//    JWK:  {"kty":"OKP","crv":"Ed25519","x":base64url(As)}
+//    JWS:  base64url(header) "." base64url(payload) "." base64url(sig)
+//
+//  where header is {"alg":"EdDSA"}, and sig is an ordinary Ed25519 signature
+//  on the ASCII bytes of the first two parts, joined by ".". All base64url
+//  encodings are without padding, as JOSE requires.
+//
+
+// jwk is the JSON form of an Ed25519 JSON Web Key.
+type jwk struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+}
+
+// jwsHeader is the protected header of JWS tokens made by SignJWS.
+var jwsHeader = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"EdDSA"}`))
+
+// MarshalJWK encodes the public key as an RFC 8037 JSON Web Key.
+func (pk *Public) MarshalJWK() []byte {
+	var As = pk.Key()
+
+	// Marshaling a struct of strings cannot fail
+	var data, _ = json.Marshal(jwk{
+		Kty: "OKP",
+		Crv: "Ed25519",
+		X:   base64.RawURLEncoding.EncodeToString(As[:]),
+	})
+
+	return data
+}
+
+// ParseJWK decodes a public key from an RFC 8037 JSON Web Key, which must be
+// an Ed25519 key. Any other members of the key, including a private key
+// "d", are ignored.
+func ParseJWK(data []byte) (*Public, error) {
+	var key jwk
+	if err := json.Unmarshal(data, &key); err != nil {
+		return nil, errors.New("ParseJWK: " + err.Error())
+	}
+	if key.Kty != "OKP" || key.Crv != "Ed25519" {
+		return nil, errors.New("ParseJWK: not an Ed25519 key")
+	}
+
+	var x, err = base64.RawURLEncoding.DecodeString(key.X)
+	if err != nil {
+		return nil, errors.New("ParseJWK: invalid base64url encoding")
+	}
+	var pk, valid = decodePublic(x)
+	if !valid {
+		return nil, errors.New("ParseJWK: invalid public key")
+	}
+
+	return pk, nil
+}
+
+// SignJWS signs payload with sk, returning a JWS in compact serialization with
+// the "EdDSA" algorithm.
+func (sk *Secret) SignJWS(payload []byte) string {
+
+	// input = base64url(header) || "." || base64url(payload)
+	var input = jwsHeader + "." + base64.RawURLEncoding.EncodeToString(payload)
+	var sig = sk.Sign([]byte(input))
+
+	return input + "." + base64.RawURLEncoding.EncodeToString(sig[:])
+}
+
+// VerifyJWS checks a JWS in compact serialization, which must use the "EdDSA"
+// algorithm, and returns its payload if the signature by pk is valid. It
+// returns ErrInvalidSignature if the token is well-formed but its signature
+// is not valid.
+func (pk *Public) VerifyJWS(token string) ([]byte, error) {
+	var parts = strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("VerifyJWS: not a compact JWS")
+	}
+
+	// header must be valid JSON with alg = "EdDSA"
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	var headerJSON, err = base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil || json.Unmarshal(headerJSON, &header) != nil {
+		return nil, errors.New("VerifyJWS: invalid header")
+	}
+	if header.Alg != "EdDSA" {
+		return nil, errors.New("VerifyJWS: unsupported algorithm: " + header.Alg)
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, errors.New("VerifyJWS: invalid payload encoding")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, errors.New("VerifyJWS: invalid signature encoding")
+	}
+
+	if !pk.Verify([]byte(parts[0]+"."+parts[1]), sig) {
+		return nil, ErrInvalidSignature
+	}
+
+	return payload, nil
+}
@@ -0,0 +1,132 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"encoding/base64"
+	"strings"
+	"testing"
+)
+
+// The keys and JWS of RFC 8037 appendix A.
+var (
+	joseTestPrivate = `{"kty":"OKP","crv":"Ed25519",` +
+		`"d":"nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A",` +
+		`"x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+	joseTestPublic = `{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`
+	joseTestJWS    = "eyJhbGciOiJFZERTQSJ9.RXhhbXBsZSBvZiBFZDI1NTE5IHNpZ25pbmc." +
+		"hgyY0il_MGCjP0JzlnLWG1PPOt7-09PGcvMg3AIbQR6dWbhijcNR4ki4iylGjg5BhVsPt9g7sVvpAr_MuM0KAg"
+	joseTestPayload = "Example of Ed25519 signing"
+)
+
+// joseTestSecret returns the secret key of RFC 8037 appendix A.1.
+func joseTestSecret() *Secret {
+	var d, _ = base64.RawURLEncoding.DecodeString("nWGxne_9WmC6hEr0kuwsxERJxWl7MmkZcDusAxyuf2A")
+	return SecretFromSeed(d)
+}
+
+func TestJWK(t *testing.T) {
+	var pk = joseTestSecret().Public()
+	if got := string(pk.MarshalJWK()); got != joseTestPublic {
+		t.Errorf("MarshalJWK = %s, want %s", got, joseTestPublic)
+	}
+
+	// the private key's "d" is ignored
+	for _, data := range []string{joseTestPublic, joseTestPrivate} {
+		var parsed, err = ParseJWK([]byte(data))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if parsed.Key() != pk.Key() {
+			t.Errorf("ParseJWK(%s) did not recover the key", data)
+		}
+	}
+}
+
+var parseJWKErrorTests = []string{
+	`{"kty":"OKP","crv":"X25519","x":"hSDwCYkwp1R0i33ctD73Wg2_Og0mOBr066SpjqqbTmo"}`,
+	`{"kty":"EC","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo"}`,
+	`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcHURo="}`,
+	`{"kty":"OKP","crv":"Ed25519","x":"11qYAYKxCrfVS_7TyWQHOg7hcvPapiMlrwIaaPcH"}`,
+	`{"kty":"OKP","crv":"Ed25519","x":"AgAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAAA"}`,
+	`{"kty":"OKP"`,
+}
+
+func TestParseJWKErrors(t *testing.T) {
+	for _, data := range parseJWKErrorTests {
+		if _, err := ParseJWK([]byte(data)); err == nil {
+			t.Errorf("ParseJWK accepted %s", data)
+		}
+	}
+}
+
+func TestJWS(t *testing.T) {
+	var sk = joseTestSecret()
+
+	// Ed25519 is deterministic, so SignJWS gives exactly the RFC's token
+	if got := sk.SignJWS([]byte(joseTestPayload)); got != joseTestJWS {
+		t.Errorf("SignJWS = %s, want %s", got, joseTestJWS)
+	}
+
+	var payload, err = sk.Public().VerifyJWS(joseTestJWS)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(payload) != joseTestPayload {
+		t.Errorf("VerifyJWS = %q, want %q", payload, joseTestPayload)
+	}
+
+	if _, err := testSecret(2).Public().VerifyJWS(joseTestJWS); err != ErrInvalidSignature {
+		t.Errorf("VerifyJWS under another key = %v, want ErrInvalidSignature", err)
+	}
+}
+
+func TestVerifyJWSErrors(t *testing.T) {
+	var pk = joseTestSecret().Public()
+	var parts = strings.Split(joseTestJWS, ".")
+
+	// {"alg":"HS256"}
+	var hs256 = base64.RawURLEncoding.EncodeToString([]byte(`{"alg":"HS256"}`))
+
+	for _, token := range []string{
+		parts[0] + "." + parts[1],
+		hs256 + "." + parts[1] + "." + parts[2],
+		"!!." + parts[1] + "." + parts[2],
+		parts[0] + ".!!." + parts[2],
+		parts[0] + "." + parts[1] + ".!!",
+		parts[0] + "." + parts[1] + "." + parts[2][:40],
+	} {
+		if _, err := pk.VerifyJWS(token); err == nil {
+			t.Errorf("VerifyJWS accepted %s", token)
+		}
+	}
+
+	// a changed payload is caught by the signature
+	var changed = parts[0] + "." + base64.RawURLEncoding.EncodeToString([]byte("changed")) + "." + parts[2]
+	if _, err := pk.VerifyJWS(changed); err != ErrInvalidSignature {
+		t.Errorf("VerifyJWS of a changed payload = %v, want ErrInvalidSignature", err)
+	}
+}
@@ -0,0 +1,104 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"bytes"
+	"encoding/json"
+)
+
+//
+//  Canonical JSON signing allows structured values to be signed, such that
+//  two values holding the same data always produce the same signature, even
+//  if (for example) their map keys were inserted in a different order, or
+//  they are different Go types which marshal to the same JSON object.
+//
+//  Values are canonicalized with the following rules:
+//
+//  - v is marshalled with encoding/json, so struct tags, json.Marshaler
+//    implementations etc. are all respected.
+//  - The result is decoded again into generic maps and slices, and marshalled
+//    a second time. This sorts the keys of every object by byte order,
+//    including objects which came from structs.
+//  - Numbers are kept exactly as encoding/json first wrote them, and are
+//    never converted through float64.
+//  - No insignificant whitespace is written, and HTML characters (<, >, &)
+//    are not escaped.
+//
+//  The signature is an ordinary Ed25519 signature on the canonical bytes, so
+//  it can be checked by any party which can produce the same canonical JSON.
+//
+
+// CanonicalJSON returns the canonical JSON encoding of v, which is the message
+// actually signed by SignCanonicalJSON.
+func CanonicalJSON(v interface{}) ([]byte, error) {
+
+	// first pass, Go value -> JSON
+	var raw, err = json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+
+	// JSON -> generic value, keeping numbers as written
+	var generic interface{}
+	var dec = json.NewDecoder(bytes.NewReader(raw))
+	dec.UseNumber()
+	if err = dec.Decode(&generic); err != nil {
+		return nil, err
+	}
+
+	// second pass, generic value -> sorted JSON
+	var buf bytes.Buffer
+	var enc = json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	if err = enc.Encode(generic); err != nil {
+		return nil, err
+	}
+
+	// Encode always appends a newline
+	return bytes.TrimSuffix(buf.Bytes(), []byte("\n")), nil
+}
+
+// SignCanonicalJSON signs the canonical JSON encoding of v with the Secret
+// Key sk. An error is only returned if v cannot be marshalled to JSON.
+func (sk *Secret) SignCanonicalJSON(v interface{}) (Signature, error) {
+	var msg, err = CanonicalJSON(v)
+	if err != nil {
+		return Signature{}, err
+	}
+	return sk.Sign(msg), nil
+}
+
+// VerifyCanonicalJSON checks whether sig is a valid signature by pk on the
+// canonical JSON encoding of v. An error is only returned if v cannot be
+// marshalled to JSON, in which case the result is always false.
+func VerifyCanonicalJSON(pk *Public, v interface{}, sig []byte) (bool, error) {
+	var msg, err = CanonicalJSON(v)
+	if err != nil {
+		return false, err
+	}
+	return pk.Verify(msg, sig), nil
+}
@@ -0,0 +1,93 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+// jsonOrder is a struct whose fields are not in key order.
+type jsonOrder struct {
+	Z int               `json:"z"`
+	A string            `json:"a"`
+	M map[string]string `json:"m"`
+}
+
+// canonicalJSONTests holds the same object as a struct, as a map, and as raw
+// JSON in another key order, and one object whose number does not fit in a
+// float64.
+var canonicalJSONTests = []struct {
+	v    interface{}
+	want string
+}{
+	{jsonOrder{Z: 1, A: "<x>", M: map[string]string{"q": "2", "p": "1"}},
+		`{"a":"<x>","m":{"p":"1","q":"2"},"z":1}`},
+	{map[string]interface{}{"z": 1, "m": map[string]string{"p": "1", "q": "2"}, "a": "<x>"},
+		`{"a":"<x>","m":{"p":"1","q":"2"},"z":1}`},
+	{json.RawMessage(`{ "z": 1, "m": {"q": "2", "p": "1"}, "a": "<x>" }`),
+		`{"a":"<x>","m":{"p":"1","q":"2"},"z":1}`},
+	{json.RawMessage(`{"z": 100000000000000000001}`),
+		`{"z":100000000000000000001}`},
+}
+
+func TestCanonicalJSON(t *testing.T) {
+	var sk = testSecret(4)
+	var first = sk.Sign([]byte(canonicalJSONTests[0].want))
+
+	for i, test := range canonicalJSONTests {
+		var b, err = CanonicalJSON(test.v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(b) != test.want {
+			t.Errorf("CanonicalJSON(tests[%d]) = %s, want %s", i, b, test.want)
+		}
+
+		sig, err := sk.SignCanonicalJSON(test.v)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if ok, err := VerifyCanonicalJSON(sk.Public(), test.v, sig[:]); !ok || err != nil {
+			t.Errorf("VerifyCanonicalJSON(tests[%d]) = %v, %v", i, ok, err)
+		}
+
+		// equal objects sign the same, whatever their key order
+		if test.want == canonicalJSONTests[0].want && sig != first {
+			t.Errorf("tests[%d] signed differently from an equal object", i)
+		}
+	}
+
+	// a changed value does not verify
+	var changed = map[string]interface{}{"z": 2, "m": map[string]string{"p": "1", "q": "2"}, "a": "<x>"}
+	if ok, _ := VerifyCanonicalJSON(sk.Public(), changed, first[:]); ok {
+		t.Error("VerifyCanonicalJSON accepted a changed object")
+	}
+
+	if _, err := sk.SignCanonicalJSON(make(chan int)); err == nil {
+		t.Error("SignCanonicalJSON accepted a value which cannot be marshalled")
+	}
+}
@@ -26,13 +26,18 @@
 package zed
 
 import (
+	"crypto/ed25519"
 	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
 	"strconv"
+	"sync/atomic"
 )
 
 // Public is the working form of an Ed25519 public key.
 type Public struct {
 	point Point
+	table atomic.Value // *publicTable, set by Precompute
 }
 
 // Point gets the Ed25519 curve point of the public key.
@@ -49,10 +54,32 @@ func (pk *Public) Key() Buffer256 {
 	return key
 }
 
+// Negate creates a new public key whose point is -A, the negation of this
+// key's point A.
+func (pk *Public) Negate() *Public {
+	var npk = &Public{}
+	PointNeg(&npk.point, &pk.point)
+	return npk
+}
+
+// HasTorsionComponent reports whether the public key point has a small-order
+// component, i.e. whether it lies outside the prime-order subgroup generated
+// by the base point. Keys generated honestly by SecretFromSeed or Derive never
+// do, but a key received from elsewhere may be a valid subgroup point plus one
+// of the 8 small-order points, which breaks assumptions made by key
+// aggregation and similar protocols. This is a stricter check than only
+// rejecting small-order keys, since it also catches mixed-order points.
+func (pk *Public) HasTorsionComponent() bool {
+	return !pointTorsionFree(&pk.point)
+}
+
 // Secret is the workinig form of an Ed25519 priivte key.
 type Secret struct {
-	scalar Scalar
-	prefix Buffer256
+	scalar  Scalar
+	prefix  Buffer256
+	seed    Buffer256
+	hasSeed bool
+	derived bool
 }
 
 // Scalar gets the private "scalar" of the secret key. This is the key piece
@@ -70,6 +97,128 @@ func (sk *Secret) Prefix() Buffer256 {
 	return sk.prefix
 }
 
+// Seed gets the original 32-byte seed the secret key was created from with
+// SecretFromSeed. Keys created any other way have no known seed, in which
+// case ok is false and the returned seed is all zeroes.
+func (sk *Secret) Seed() (seed Buffer256, ok bool) {
+	return sk.seed, sk.hasSeed
+}
+
+// StdPrivateKey gets the secret key in the standard 64-byte Ed25519 private
+// key format, seed || public key, as used by crypto/ed25519 and most other
+// Ed25519 implementations. This format needs the original seed, so it is
+// only available for keys created by SecretFromSeed; for any other key it
+// returns nil and false.
+func (sk *Secret) StdPrivateKey() (ed25519.PrivateKey, bool) {
+	if !sk.hasSeed {
+		return nil, false
+	}
+
+	// key = seed || compress(A)
+	var key = make(ed25519.PrivateKey, ed25519.PrivateKeySize)
+	var As = sk.Public().Key()
+	copy(key[:32], sk.seed[:])
+	copy(key[32:], As[:])
+
+	return key, true
+}
+
+// IsDerived reports whether the secret key was created by Derive. A derived
+// key never has a recoverable seed, so exports which need the seed will fail
+// for it. Note that a key loaded with SecretFromKey is not considered to be
+// derived, even if it was originally produced by Derive, but it has no seed
+// either; check the result of Seed where a seed is actually required.
+func (sk *Secret) IsDerived() bool {
+	return sk.derived
+}
+
+// RotatePrefix creates a copy of the secret key with the same scalar, and so
+// the same public key, but a new prefix derived from the old prefix and some
+// fresh entropy:
+//
+//   (prefix' || _) = sha512(rotate_str || prefix || entropy)
+//
+// This limits the damage if the prefix alone has leaked. Since the prefix
+// determines the signing nonces, the same message signs differently after
+// rotation, but signatures made before and after both verify under the same
+// public key. The rotated key no longer matches its seed, so it has none.
+func (sk *Secret) RotatePrefix(entropy []byte) *Secret {
+	var nsk = &Secret{scalar: sk.scalar, derived: sk.derived}
+
+	// (prefix' || _) = sha512(rotate_str || prefix || entropy)
+	var hash = sha512.New()
+	var res Buffer512
+	hash.Write([]byte("zed25519_prefix_rotation"))
+	hash.Write(sk.prefix[:])
+	hash.Write(entropy)
+	hash.Sum(res[:0])
+	copy(nsk.prefix[:], res[:32])
+
+	return nsk
+}
+
+// SetPrefixFromScalar replaces the prefix of the secret key with one derived
+// from its private scalar, for keys which only have a scalar, such as keys
+// imported as a bare scalar or built with a zero prefix, which Sign refuses
+// to use. The key no longer matches its seed afterwards, so it has none.
+func (sk *Secret) SetPrefixFromScalar() {
+	sk.prefix = prefixFromScalar(&sk.scalar)
+	sk.seed = Buffer256{}
+	sk.hasSeed = false
+}
+
+// hasZeroPrefix reports, in constant time, whether the prefix of the secret
+// key is all zeroes.
+func (sk *Secret) hasZeroPrefix() bool {
+	var zero Buffer256
+	return subtle.ConstantTimeCompare(sk.prefix[:], zero[:]) == 1
+}
+
+// prefixFromScalar derives a prefix for a secret key which only has a private
+// scalar, such as a recovered key:
+//
+//   (prefix || _) = sha512(prefix_str || a)
+func prefixFromScalar(a *Scalar) Buffer256 {
+	var hash = sha512.New()
+	var res Buffer512
+	var prefix Buffer256
+	hash.Write([]byte("zed25519_scalar_prefix"))
+	hash.Write(a[:])
+	hash.Sum(res[:0])
+	copy(prefix[:], res[:32])
+	return prefix
+}
+
+// Wipe overwrites the scalar, prefix and seed of the secret key with zeroes,
+// so that they do not linger in memory once the key is no longer needed. A
+// wiped key cannot be used again: Sign and VrfEval panic on it, since its
+// prefix is all zeroes. Signing, VRF evaluation and derivation also wipe
+// their own temporary copies of secret values before returning.
+//
+// This is a best-effort measure: the Go runtime may have already copied the
+// key (for example when growing a goroutine stack), and hash functions keep
+// their own internal buffers, neither of which can be reached from here.
+func (sk *Secret) Wipe() {
+	wipe(sk.scalar[:])
+	wipe(sk.prefix[:])
+	wipe(sk.seed[:])
+	sk.hasSeed = false
+}
+
+// wipe overwrites b with zeroes.
+func wipe(b []byte) {
+	for i := range b {
+		b[i] = 0
+	}
+}
+
+// Equal reports whether two secret keys hold the same scalar and prefix. The
+// comparison is constant-time, as both values are secret.
+func (sk *Secret) Equal(other *Secret) bool {
+	return ScalarEqual(&sk.scalar, &other.scalar) &&
+		subtle.ConstantTimeCompare(sk.prefix[:], other.prefix[:]) == 1
+}
+
 // Public creates the corresponding public key object for this secret key.
 func (sk *Secret) Public() *Public {
 	var pk = &Public{}
@@ -117,6 +266,30 @@ func PublicFromKey(key []byte) *Public {
 	return pk
 }
 
+// PublicFromKeyStrict is the same as PublicFromKey, but only accepts the
+// canonical encoding of a point (see DecompressPointStrict), so that each
+// public key has exactly one 32-byte form. Since keys handled strictly
+// usually come from untrusted input, it returns an error instead of
+// panicking.
+func PublicFromKeyStrict(key []byte) (*Public, error) {
+
+	// if public key length != 32 bytes, fail
+	if l := len(key); l != 32 {
+		return nil, errors.New("PublicFromKeyStrict: bad public key length: " + strconv.Itoa(l))
+	}
+
+	var pk = &Public{}
+	var kb Buffer256
+	copy(kb[:], key[:])
+
+	// point = decompress(key), or fail if invalid or non-canonical
+	if !DecompressPointStrict(&pk.point, &kb) {
+		return nil, errors.New("PublicFromKeyStrict: invalid or non-canonical point")
+	}
+
+	return pk, nil
+}
+
 // SecretFromKey is a helper function which builds a working form of the
 // Secret Key from its 64-byte serialized form.
 func SecretFromKey(key []byte) *Secret {
@@ -157,10 +330,12 @@ func SecretFromSeed(seed []byte) *Secret {
 	copy(sk.scalar[:], res[:32])
 	copy(sk.prefix[:], res[32:])
 
+	// keep seed, so the canonical key formats can be exported later
+	copy(sk.seed[:], seed[:32])
+	sk.hasSeed = true
+
 	// clamp scalar, as per Ed25519 spec
-	sk.scalar[0] &= 248
-	sk.scalar[31] &= 63
-	sk.scalar[31] |= 64
+	ClampScalar(&sk.scalar)
 
 	return sk
 }
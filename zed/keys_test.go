@@ -0,0 +1,200 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"bytes"
+	"crypto/ed25519"
+	"testing"
+)
+
+func TestHasTorsionComponent(t *testing.T) {
+	var pk = testSecret(2).Public()
+	if pk.HasTorsionComponent() {
+		t.Error("an honest key has a torsion component")
+	}
+
+	// A + T, for each small-order point T
+	for i := range SmallOrderPoints {
+		var T Point
+		if !DecompressPoint(&T, &SmallOrderPoints[i]) {
+			t.Fatalf("SmallOrderPoints[%d] does not decompress", i)
+		}
+		var mixed = &Public{}
+		PointAdd(&mixed.point, &pk.point, &T)
+
+		// only the identity (T = I) leaves the key in the subgroup
+		var isIdentity = PointToKey(&mixed.point) == pk.Key()
+		if mixed.HasTorsionComponent() == isIdentity {
+			t.Errorf("A + SmallOrderPoints[%d]: HasTorsionComponent = %v", i, !isIdentity)
+		}
+	}
+}
+
+func TestIsDerived(t *testing.T) {
+	var sk = testSecret(2)
+	if sk.IsDerived() {
+		t.Error("a seed key reports as derived")
+	}
+	if _, ok := sk.Seed(); !ok {
+		t.Error("a seed key has no seed")
+	}
+	if _, ok := sk.StdPrivateKey(); !ok {
+		t.Error("a seed key has no standard private key")
+	}
+
+	var child = sk.Derive([]byte("child"), nil)
+	var children = []*Secret{child, sk.Derive([]byte("child"), []byte{}), child.RotatePrefix([]byte("entropy"))}
+	var path, _ = sk.DerivePath("m/0'/1")
+	children = append(children, path)
+	for i, c := range children {
+		if !c.IsDerived() {
+			t.Errorf("derived key %d does not report as derived", i)
+		}
+		if _, ok := c.Seed(); ok {
+			t.Errorf("derived key %d has a seed", i)
+		}
+		if _, ok := c.StdPrivateKey(); ok {
+			t.Errorf("derived key %d has a standard private key", i)
+		}
+	}
+
+	// the derived flag is not part of the 64-byte key
+	var key = child.Key()
+	if SecretFromKey(key[:]).IsDerived() {
+		t.Error("SecretFromKey reports as derived")
+	}
+}
+
+func TestSecretEqual(t *testing.T) {
+	var sk = testSecret(2)
+	var key = sk.Key()
+	if !sk.Equal(SecretFromKey(key[:])) {
+		t.Error("Equal is false on equal keys")
+	}
+	if sk.Equal(testSecret(3)) {
+		t.Error("Equal is true on different keys")
+	}
+
+	// same scalar, different prefix
+	if sk.Equal(sk.RotatePrefix([]byte("entropy"))) {
+		t.Error("Equal ignores the prefix")
+	}
+
+	// same prefix, different scalar
+	var other = SecretFromKey(key[:])
+	other.scalar[31] ^= 1
+	if sk.Equal(other) {
+		t.Error("Equal ignores the scalar")
+	}
+
+	if sk.hasZeroPrefix() {
+		t.Error("hasZeroPrefix is true on a seed key")
+	}
+	other.Wipe()
+	if !other.hasZeroPrefix() {
+		t.Error("hasZeroPrefix is false on a wiped key")
+	}
+}
+
+func TestNegate(t *testing.T) {
+	var pk = testSecret(2).Public()
+	var neg = pk.Negate()
+	if neg.Key() == pk.Key() {
+		t.Error("Negate returned the same key")
+	}
+	if neg.Negate().Key() != pk.Key() {
+		t.Error("Negate is not an involution")
+	}
+
+	// A + (-A) = I
+	var sum, I Point
+	PointAdd(&sum, &pk.point, &neg.point)
+	PointIdentity(&I)
+	if !PointEqual(&sum, &I) {
+		t.Error("A + (-A) is not the identity")
+	}
+}
+
+func TestStdPrivateKey(t *testing.T) {
+	var seed = bytes.Repeat([]byte{0x5a}, 32)
+	var sk = SecretFromSeed(seed)
+
+	var key, ok = sk.StdPrivateKey()
+	if !ok {
+		t.Fatal("a seed key has no standard private key")
+	}
+	if !bytes.Equal(key, ed25519.NewKeyFromSeed(seed)) {
+		t.Errorf("StdPrivateKey = %x, want ed25519.NewKeyFromSeed", key)
+	}
+
+	// crypto/ed25519 signs the same with it
+	var msg = []byte("interop")
+	var sig = sk.Sign(msg)
+	if !bytes.Equal(ed25519.Sign(key, msg), sig[:]) {
+		t.Error("crypto/ed25519 signs differently with StdPrivateKey")
+	}
+
+	var raw = sk.Key()
+	if key, ok := SecretFromKey(raw[:]).StdPrivateKey(); ok || key != nil {
+		t.Error("a key without a seed has a standard private key")
+	}
+}
+
+func TestRotatePrefix(t *testing.T) {
+	var sk = testSecret(2)
+	var msg = []byte("rotate")
+	var before = sk.Sign(msg)
+
+	var rsk = sk.RotatePrefix([]byte("entropy"))
+	if rsk.Public().Key() != sk.Public().Key() {
+		t.Fatal("RotatePrefix changed the public key")
+	}
+	if rsk.Prefix() == sk.Prefix() {
+		t.Error("RotatePrefix did not change the prefix")
+	}
+	if _, ok := rsk.Seed(); ok {
+		t.Error("rotated key still has a seed")
+	}
+
+	// signatures change, but both verify under the same key
+	var after = rsk.Sign(msg)
+	if after == before {
+		t.Error("rotated key signs the same as before")
+	}
+	var pk = sk.Public()
+	if !pk.Verify(msg, before[:]) || !pk.Verify(msg, after[:]) {
+		t.Error("signatures before and after rotation do not both verify")
+	}
+
+	// rotation depends on the entropy
+	if sk.RotatePrefix([]byte("other")).Prefix() == rsk.Prefix() {
+		t.Error("different entropy gives the same prefix")
+	}
+	if sk.RotatePrefix([]byte("entropy")).Prefix() != rsk.Prefix() {
+		t.Error("RotatePrefix is not deterministic")
+	}
+}
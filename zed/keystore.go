@@ -0,0 +1,190 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/binary"
+	"errors"
+	"strconv"
+
+	"golang.org/x/crypto/argon2"
+	"golang.org/x/crypto/chacha20poly1305"
+)
+
+//
+//  Encrypted keystore format, for storing secret keys at rest under a
+//  password instead of as the plaintext 64 bytes of Secret.Key():
+//
+//    header = version || time || memory || threads || salt
+//    k = argon2id(password, salt, time, memory, threads, 32)
+//    blob = header || nonce || xchacha20poly1305(k, nonce, payload, header)
+//
+//  Version 1 uses a 16-byte salt, a 24-byte random nonce, and the payload
+//
+//    payload = flags || scalar || prefix || seed
+//
+//  where flags records whether the key has a seed and whether it was
+//  derived, so the imported key behaves exactly like the exported one. The
+//  header is authenticated as additional data, so the cost parameters cannot
+//  be tampered with. The version byte lets later versions change the KDF,
+//  the cipher or the parameters, while old blobs can still be imported.
+//
+
+// keystoreVersion is the version of blobs written by Export.
+const keystoreVersion = 1
+
+// Lengths of the parts of a version 1 keystore blob.
+const (
+	keystoreHeaderSize  = 1 + 4 + 4 + 1 + 16
+	keystorePayloadSize = 1 + 32 + 32 + 32
+	keystoreTagSize     = 16 // Poly1305 authentication tag
+	keystoreSize        = keystoreHeaderSize + chacha20poly1305.NonceSizeX +
+		keystorePayloadSize + keystoreTagSize
+)
+
+// Flags of the keystore payload.
+const (
+	keystoreHasSeed = 1 << iota
+	keystoreDerived
+)
+
+// keystoreMaxMemory is the most memory, in KiB, which ImportSecret lets the
+// KDF use, so that a forged blob cannot exhaust memory: 4 GiB.
+const keystoreMaxMemory = 4 * 1024 * 1024
+
+// keystoreMaxTime is the most passes over memory which ImportSecret lets the
+// KDF make, so that a forged blob cannot make it run for hours.
+const keystoreMaxTime = 64
+
+// ErrKeystorePassword is returned by ImportSecret when the blob cannot be
+// decrypted, because the password is wrong or the blob was modified.
+var ErrKeystorePassword = errors.New("zed: wrong password or corrupt keystore")
+
+// Export encrypts the secret key under password, in the current keystore
+// format, with the Argon2id cost parameters of DefaultArgon2Params.
+func (sk *Secret) Export(password []byte) ([]byte, error) {
+	return sk.ExportWithParams(password, DefaultArgon2Params)
+}
+
+// ExportWithParams is the same as Export, with the given Argon2id cost
+// parameters. They are stored in the blob, so ImportSecret needs no more
+// than the password.
+func (sk *Secret) ExportWithParams(password []byte, params Argon2Params) ([]byte, error) {
+	if params.Time < 1 || params.Threads < 1 || params.Memory < 8*uint32(params.Threads) ||
+		params.Memory > keystoreMaxMemory || params.Time > keystoreMaxTime {
+		return nil, errors.New("Export: invalid Argon2 parameters")
+	}
+
+	// header = version || time || memory || threads || salt
+	var blob = make([]byte, keystoreHeaderSize+chacha20poly1305.NonceSizeX, keystoreSize)
+	blob[0] = keystoreVersion
+	binary.BigEndian.PutUint32(blob[1:], params.Time)
+	binary.BigEndian.PutUint32(blob[5:], params.Memory)
+	blob[9] = params.Threads
+	if _, err := rand.Read(blob[10:]); err != nil {
+		return nil, err
+	}
+	var header, nonce = blob[:keystoreHeaderSize], blob[keystoreHeaderSize:]
+
+	// payload = flags || scalar || prefix || seed
+	var payload = make([]byte, 1, keystorePayloadSize)
+	if sk.hasSeed {
+		payload[0] |= keystoreHasSeed
+	}
+	if sk.derived {
+		payload[0] |= keystoreDerived
+	}
+	payload = append(payload, sk.scalar[:]...)
+	payload = append(payload, sk.prefix[:]...)
+	payload = append(payload, sk.seed[:]...)
+	defer wipe(payload)
+
+	var aead, err = keystoreCipher(password, header)
+	if err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(blob, nonce, payload, header), nil
+}
+
+// ImportSecret decrypts a secret key exported with Export. It returns
+// ErrKeystorePassword if the password is wrong or the blob was modified.
+func ImportSecret(blob, password []byte) (*Secret, error) {
+	if len(blob) < 1 {
+		return nil, errors.New("ImportSecret: empty keystore")
+	}
+	if blob[0] != keystoreVersion {
+		return nil, errors.New("ImportSecret: unsupported keystore version: " + strconv.Itoa(int(blob[0])))
+	}
+	if len(blob) != keystoreSize {
+		return nil, errors.New("ImportSecret: bad keystore length: " + strconv.Itoa(len(blob)))
+	}
+
+	var header = blob[:keystoreHeaderSize]
+	var nonce = blob[keystoreHeaderSize : keystoreHeaderSize+chacha20poly1305.NonceSizeX]
+	var ciphertext = blob[keystoreHeaderSize+chacha20poly1305.NonceSizeX:]
+
+	var aead, err = keystoreCipher(password, header)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := aead.Open(nil, nonce, ciphertext, header)
+	if err != nil {
+		return nil, ErrKeystorePassword
+	}
+	defer wipe(payload)
+
+	var sk = &Secret{
+		hasSeed: payload[0]&keystoreHasSeed != 0,
+		derived: payload[0]&keystoreDerived != 0,
+	}
+	copy(sk.scalar[:], payload[1:33])
+	copy(sk.prefix[:], payload[33:65])
+	copy(sk.seed[:], payload[65:97])
+
+	return sk, nil
+}
+
+// keystoreCipher derives the key of a version 1 keystore from the password
+// and the parameters and salt in its header.
+func keystoreCipher(password, header []byte) (cipher.AEAD, error) {
+	var time = binary.BigEndian.Uint32(header[1:])
+	var memory = binary.BigEndian.Uint32(header[5:])
+	var threads = header[9]
+	var salt = header[10:]
+	if time < 1 || threads < 1 || memory < 8*uint32(threads) ||
+		memory > keystoreMaxMemory || time > keystoreMaxTime {
+		return nil, errors.New("keystore: invalid Argon2 parameters")
+	}
+
+	// k = argon2id(password, salt, time, memory, threads, 32)
+	var k = argon2.IDKey(password, salt, time, memory, threads, chacha20poly1305.KeySize)
+	defer wipe(k)
+
+	return chacha20poly1305.NewX(k)
+}
@@ -0,0 +1,95 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"encoding/binary"
+	"testing"
+)
+
+// testArgon2Params are cheap Argon2id parameters, so the tests run quickly.
+var testArgon2Params = Argon2Params{Time: 1, Memory: 64, Threads: 1}
+
+func TestKeystore(t *testing.T) {
+	var sk = testSecret(9)
+	var blob, err = sk.ExportWithParams([]byte("hunter2"), testArgon2Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	isk, err := ImportSecret(blob, []byte("hunter2"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var iseed, iok = isk.Seed()
+	var seed, ok = sk.Seed()
+	if !isk.Equal(sk) || iseed != seed || iok != ok || isk.IsDerived() != sk.IsDerived() {
+		t.Error("imported key differs from the exported key")
+	}
+
+	if _, err := ImportSecret(blob, []byte("hunter3")); err != ErrKeystorePassword {
+		t.Errorf("wrong password: got %v, want ErrKeystorePassword", err)
+	}
+
+	// the header is authenticated
+	var forged = append([]byte(nil), blob...)
+	forged[keystoreHeaderSize-1] ^= 1
+	if _, err := ImportSecret(forged, []byte("hunter2")); err != ErrKeystorePassword {
+		t.Errorf("modified salt: got %v, want ErrKeystorePassword", err)
+	}
+}
+
+var keystoreParamsTests = []Argon2Params{
+	{Time: 0, Memory: 64, Threads: 1},
+	{Time: 1, Memory: 64, Threads: 0},
+	{Time: 1, Memory: 7, Threads: 1},
+	{Time: 1, Memory: keystoreMaxMemory + 1, Threads: 1},
+	{Time: keystoreMaxTime + 1, Memory: 64, Threads: 1},
+	{Time: 0xFFFFFFFF, Memory: 64, Threads: 1},
+}
+
+func TestKeystoreParams(t *testing.T) {
+	var sk = testSecret(9)
+	var blob, err = sk.ExportWithParams([]byte("hunter2"), testArgon2Params)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, params := range keystoreParamsTests {
+		if _, err := sk.ExportWithParams([]byte("hunter2"), params); err == nil {
+			t.Errorf("ExportWithParams accepted %+v", params)
+		}
+
+		// a forged blob must be rejected before the KDF runs
+		var forged = append([]byte(nil), blob...)
+		binary.BigEndian.PutUint32(forged[1:], params.Time)
+		binary.BigEndian.PutUint32(forged[5:], params.Memory)
+		forged[9] = params.Threads
+		if _, err := ImportSecret(forged, []byte("hunter2")); err == nil || err == ErrKeystorePassword {
+			t.Errorf("ImportSecret accepted forged parameters %+v: %v", params, err)
+		}
+	}
+}
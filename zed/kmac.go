@@ -0,0 +1,107 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"golang.org/x/crypto/sha3"
+)
+
+//
+//  KMAC256 is the keyed hash function (MAC and PRF) of NIST SP 800-185,
+//  defined on top of cSHAKE256 with the function name "KMAC":
+//
+//    newX = bytepad(encode_string(K), 136) || X || right_encode(L)
+//    KMAC256(K, X, L, S) = cSHAKE256(newX, L, "KMAC", S)
+//
+//  where L is the output length in bits and S is a customization string,
+//  which separates the uses of one key for different purposes. Unlike an
+//  ad-hoc keyed hash such as sha3_512(K || X), the key is padded to a full
+//  block and the output length is bound into the result.
+//
+//  REFERENCES:
+//    [1] NIST SP 800-185, "SHA-3 Derived Functions: cSHAKE, KMAC, TupleHash
+//        and ParallelHash"
+//        https://doi.org/10.6028/NIST.SP.800-185
+//
+
+// kmac256Rate is the rate of cSHAKE256 in bytes, which KMAC256 pads its key
+// to.
+const kmac256Rate = 136
+
+// KMAC256 computes KMAC256(key, data, 8 * outLen, custom) as specified by
+// NIST SP 800-185, returning outLen bytes. It panics if outLen is negative.
+func KMAC256(key, data []byte, outLen int, custom []byte) []byte {
+	if outLen < 0 {
+		panic("KMAC256: negative output length")
+	}
+
+	var h = sha3.NewCShake256([]byte("KMAC"), custom)
+
+	// bytepad(encode_string(K), 136)
+	var pad = leftEncode(kmac256Rate)
+	pad = append(pad, leftEncode(uint64(len(key))*8)...)
+	pad = append(pad, key...)
+	if r := len(pad) % kmac256Rate; r != 0 {
+		pad = append(pad, make([]byte, kmac256Rate-r)...)
+	}
+	h.Write(pad)
+	wipe(pad)
+
+	// X || right_encode(L)
+	h.Write(data)
+	h.Write(rightEncode(uint64(outLen) * 8))
+
+	var out = make([]byte, outLen)
+	h.Read(out)
+	return out
+}
+
+// leftEncode encodes x as its big-endian bytes, without leading zeroes but at
+// least one byte, preceded by their count.
+func leftEncode(x uint64) []byte {
+	var b = encodeUint(x)
+	return append([]byte{byte(len(b))}, b...)
+}
+
+// rightEncode encodes x as its big-endian bytes, without leading zeroes but
+// at least one byte, followed by their count.
+func rightEncode(x uint64) []byte {
+	var b = encodeUint(x)
+	return append(b, byte(len(b)))
+}
+
+// encodeUint returns the big-endian bytes of x, without leading zeroes but at
+// least one byte.
+func encodeUint(x uint64) []byte {
+	var b []byte
+	for ; x > 0; x >>= 8 {
+		b = append([]byte{byte(x)}, b...)
+	}
+	if len(b) == 0 {
+		b = []byte{0}
+	}
+	return b
+}
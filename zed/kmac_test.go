@@ -0,0 +1,125 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+// kmacSequence returns the bytes from, from + 1, ..., to.
+func kmacSequence(from, to byte) []byte {
+	var b []byte
+	for i := int(from); i <= int(to); i++ {
+		b = append(b, byte(i))
+	}
+	return b
+}
+
+// kmac256Tests are the KMAC256 samples 4 to 6 of NIST SP 800-185, with the
+// key 40 41 ... 5f and an output length of 512 bits.
+var kmac256Tests = []struct {
+	data   []byte
+	custom string
+	out    string
+}{
+	{
+		kmacSequence(0x00, 0x03), "My Tagged Application",
+		"20c570c31346f703c9ac36c61c03cb64c3970d0cfc787e9b79599d273a68d2f7" +
+			"f69d4cc3de9d104a351689f27cf6f5951f0103f33f4f24871024d9c27773a8dd",
+	},
+	{
+		kmacSequence(0x00, 0xc7), "",
+		"75358cf39e41494e949707927cee0af20a3ff553904c86b08f21cc414bcfd691" +
+			"589d27cf5e15369cbbff8b9a4c2eb17800855d0235ff635da82533ec6b759b69",
+	},
+	{
+		kmacSequence(0x00, 0xc7), "My Tagged Application",
+		"b58618f71f92e1d56c1b8c55ddd7cd188b97b4ca4d99831eb2699a837da2e4d9" +
+			"70fbacfde50033aea585f1a2708510c32d07880801bd182898fe476876fc8965",
+	},
+}
+
+func TestKMAC256(t *testing.T) {
+	var key = kmacSequence(0x40, 0x5f)
+	for i, test := range kmac256Tests {
+		var want, _ = hex.DecodeString(test.out)
+		var got = KMAC256(key, test.data, 64, []byte(test.custom))
+		if !bytes.Equal(got, want) {
+			t.Errorf("sample %d: KMAC256 = %x, want %x", i+4, got, want)
+		}
+	}
+}
+
+func TestKMAC256Length(t *testing.T) {
+	var key = kmacSequence(0x40, 0x5f)
+	var data = []byte("data")
+
+	// the output length is bound into the result, so a shorter output is
+	// not a prefix of a longer one
+	var short = KMAC256(key, data, 32, nil)
+	var long = KMAC256(key, data, 64, nil)
+	if len(short) != 32 || len(long) != 64 {
+		t.Fatalf("KMAC256 returned %d and %d bytes", len(short), len(long))
+	}
+	if bytes.Equal(short, long[:32]) {
+		t.Error("KMAC256 output is a prefix of a longer output")
+	}
+	if len(KMAC256(key, data, 0, nil)) != 0 {
+		t.Error("KMAC256 returned bytes for a length of 0")
+	}
+
+	// keys of more than one block are padded to a whole number of blocks
+	var longKey = bytes.Repeat([]byte{1}, kmac256Rate+1)
+	if bytes.Equal(KMAC256(longKey, data, 32, nil), KMAC256(longKey[:kmac256Rate], data, 32, nil)) {
+		t.Error("KMAC256 ignored the last byte of a long key")
+	}
+
+	expectPanic(t, "KMAC256", func() { KMAC256(key, data, -1, nil) })
+}
+
+var encodeTests = []struct {
+	x     uint64
+	left  string
+	right string
+}{
+	{0, "0100", "0001"},
+	{136, "0188", "8801"},
+	{256, "020100", "010002"},
+	{512, "020200", "020002"},
+}
+
+func TestLeftRightEncode(t *testing.T) {
+	for _, test := range encodeTests {
+		if got := hex.EncodeToString(leftEncode(test.x)); got != test.left {
+			t.Errorf("leftEncode(%d) = %s, want %s", test.x, got, test.left)
+		}
+		if got := hex.EncodeToString(rightEncode(test.x)); got != test.right {
+			t.Errorf("rightEncode(%d) = %s, want %s", test.x, got, test.right)
+		}
+	}
+}
@@ -0,0 +1,326 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"errors"
+	"hash"
+	"strconv"
+
+	"golang.org/x/crypto/sha3"
+)
+
+//
+//  A Merkle tree lets a signer commit to a large batch of messages with a
+//  single signature over the root of the tree, while still allowing each
+//  message (leaf) to be checked individually, using an inclusion proof made
+//  of the sibling hashes along the path from the leaf up to the root.
+//
+//  Hashes are SHA-512/256, with a one-byte domain prefix so that a leaf can
+//  never be passed off as an inner node, or vice versa (the "second preimage"
+//  attack on Merkle trees):
+//
+//    leaf = sha512_256(0x00 || data)
+//    node = sha512_256(0x01 || left || right)
+//
+//  A tree built by BuildTree may instead use SHA3-256 in the same way, as
+//  chosen by its MerkleHash. When a level has an odd number of nodes, the last
+//  one is promoted to the next level unchanged, rather than being paired with
+//  a copy of itself, so that no two different lists of leaves have the same
+//  root. Its proof simply has no step at that level.
+//
+//  Secret.SignRoot signs the root of such a tree along with its hash function,
+//  so that a signature on a root cannot be mistaken for a signature on any
+//  other message, nor on the same root under another hash function:
+//
+//    msg = root_str || hash || root
+//
+//  Public.VerifyLeaf then checks a single leaf against that signature, using
+//  only the leaf, its inclusion proof and the signature, without the root.
+//
+
+// MerkleStep is one step of a Merkle inclusion proof: the hash of the sibling
+// of the current node, and whether that sibling is on the left.
+type MerkleStep struct {
+	Hash []byte
+	Left bool
+}
+
+// MerkleProof is a Merkle inclusion proof, listing the siblings on the path
+// from a leaf up to the root, starting with the sibling of the leaf.
+type MerkleProof []MerkleStep
+
+// Root computes the Merkle root implied by the proof for the given leaf data.
+func (proof MerkleProof) Root(leaf []byte) []byte {
+	return proof.root(MerkleSHA512_256, leaf)
+}
+
+// root computes the Merkle root implied by the proof for the given leaf data,
+// with the hash function h, which must be known.
+func (proof MerkleProof) root(h MerkleHash, leaf []byte) []byte {
+	var node = merkleLeaf(h, leaf)
+	for _, step := range proof {
+		if step.Left {
+			node = merkleNode(h, step.Hash, node)
+		} else {
+			node = merkleNode(h, node, step.Hash)
+		}
+	}
+	return node
+}
+
+// VerifyMerkleProof checks whether proof shows that leaf is included in the
+// Merkle tree with the given root.
+func VerifyMerkleProof(leaf []byte, proof MerkleProof, root []byte) bool {
+	return bytes.Equal(proof.Root(leaf), root)
+}
+
+// VerifyMerkleSigned checks whether rootSig is a valid signature by pk on the
+// Merkle root root, and proof shows that leaf is included in the tree with
+// that root. Together, these show that the holder of the Secret Key signed
+// leaf as part of the batch committed to by root.
+func VerifyMerkleSigned(pk *Public, rootSig []byte, leaf []byte, proof MerkleProof, root []byte) bool {
+	return pk.Verify(root, rootSig) && VerifyMerkleProof(leaf, proof, root)
+}
+
+// MerkleHash selects the hash function of a Merkle tree.
+type MerkleHash byte
+
+// Hash functions for Merkle trees. The zero value is SHA-512/256, which is
+// also the hash function of MerkleProof.Root.
+const (
+	MerkleSHA512_256 MerkleHash = 0
+	MerkleSHA3_256   MerkleHash = 1
+)
+
+// new returns a new hash instance for h, or nil if h is unknown.
+func (h MerkleHash) new() hash.Hash {
+	switch h {
+	case MerkleSHA512_256:
+		return sha512.New512_256()
+	case MerkleSHA3_256:
+		return sha3.New256()
+	}
+	return nil
+}
+
+// MerkleTree is a Merkle tree over a list of leaves, from which the root and
+// the inclusion proof of each leaf can be read.
+type MerkleTree struct {
+	hash   MerkleHash
+	levels [][][]byte // levels[0] are the leaf hashes, the last level is the root
+}
+
+// BuildTree builds the Merkle tree of the given leaves, in order, using the
+// hash function h. It returns an error if there are no leaves, or h is
+// unknown.
+func BuildTree(leaves [][]byte, h MerkleHash) (*MerkleTree, error) {
+	if len(leaves) == 0 {
+		return nil, errors.New("BuildTree: no leaves")
+	}
+	if h.new() == nil {
+		return nil, errors.New("BuildTree: unknown hash: " + strconv.Itoa(int(h)))
+	}
+
+	// level 0 = leaf hashes
+	var level = make([][]byte, len(leaves))
+	for i, leaf := range leaves {
+		level[i] = merkleLeaf(h, leaf)
+	}
+	var tree = &MerkleTree{hash: h, levels: [][][]byte{level}}
+
+	// pair up the nodes of each level, promoting an odd last node, up to the root
+	for len(level) > 1 {
+		var next = make([][]byte, 0, (len(level)+1)/2)
+		for i := 0; i+1 < len(level); i += 2 {
+			next = append(next, merkleNode(h, level[i], level[i+1]))
+		}
+		if len(level)%2 == 1 {
+			next = append(next, level[len(level)-1])
+		}
+		tree.levels = append(tree.levels, next)
+		level = next
+	}
+
+	return tree, nil
+}
+
+// Hash returns the hash function of the tree.
+func (tree *MerkleTree) Hash() MerkleHash {
+	return tree.hash
+}
+
+// Len returns the number of leaves of the tree.
+func (tree *MerkleTree) Len() int {
+	return len(tree.levels[0])
+}
+
+// Root returns the Merkle root of the tree.
+func (tree *MerkleTree) Root() []byte {
+	var root = tree.levels[len(tree.levels)-1][0]
+	return append([]byte(nil), root...)
+}
+
+// Proof returns the inclusion proof of the leaf at index i, or an error if i
+// is out of range.
+func (tree *MerkleTree) Proof(i int) (*MerkleLeafProof, error) {
+	if i < 0 || i >= tree.Len() {
+		return nil, errors.New("MerkleTree.Proof: leaf index out of range: " + strconv.Itoa(i))
+	}
+
+	var proof = &MerkleLeafProof{Hash: tree.hash}
+	for _, level := range tree.levels[:len(tree.levels)-1] {
+
+		// an odd last node has no sibling, and is promoted unchanged
+		var sibling = i ^ 1
+		if sibling < len(level) {
+			proof.Path = append(proof.Path, MerkleStep{
+				Hash: append([]byte(nil), level[sibling]...),
+				Left: sibling < i,
+			})
+		}
+		i /= 2
+	}
+
+	return proof, nil
+}
+
+// MerkleLeafProof is the inclusion proof of a leaf in a tree built by
+// BuildTree: the path from the leaf to the root, and the hash function of the
+// tree.
+type MerkleLeafProof struct {
+	Hash MerkleHash
+	Path MerkleProof
+}
+
+// Root computes the Merkle root implied by the proof for the given leaf data,
+// or nil if the hash function of the proof is unknown.
+func (proof *MerkleLeafProof) Root(leaf []byte) []byte {
+	if proof.Hash.new() == nil {
+		return nil
+	}
+	return proof.Path.root(proof.Hash, leaf)
+}
+
+// Marshal serializes the proof as hash || n || step_1 || ... || step_n,
+// where n is one byte, and each step is a byte set to 1 if the sibling is on
+// the left or 0 otherwise, followed by the 32-byte sibling hash. It panics if
+// the proof has more than 255 steps, or a sibling hash is not 32 bytes long,
+// which cannot happen for a proof made by MerkleTree.Proof.
+func (proof *MerkleLeafProof) Marshal() []byte {
+	if len(proof.Path) > 255 {
+		panic("MerkleLeafProof.Marshal: too many steps")
+	}
+
+	var buf = make([]byte, 0, 2+33*len(proof.Path))
+	buf = append(buf, byte(proof.Hash), byte(len(proof.Path)))
+	for _, step := range proof.Path {
+		if len(step.Hash) != 32 {
+			panic("MerkleLeafProof.Marshal: bad sibling hash length")
+		}
+		var left byte
+		if step.Left {
+			left = 1
+		}
+		buf = append(buf, left)
+		buf = append(buf, step.Hash...)
+	}
+	return buf
+}
+
+// ParseMerkleLeafProof decodes a proof serialized by Marshal. It returns an
+// error for an unknown hash function, a bad length, or a direction byte other
+// than 0 or 1.
+func ParseMerkleLeafProof(buf []byte) (*MerkleLeafProof, error) {
+	if len(buf) < 2 {
+		return nil, errors.New("ParseMerkleLeafProof: proof too short")
+	}
+	var proof = &MerkleLeafProof{Hash: MerkleHash(buf[0])}
+	if proof.Hash.new() == nil {
+		return nil, errors.New("ParseMerkleLeafProof: unknown hash: " + strconv.Itoa(int(buf[0])))
+	}
+	var n = int(buf[1])
+	if l := len(buf); l != 2+33*n {
+		return nil, errors.New("ParseMerkleLeafProof: bad proof length: " + strconv.Itoa(l))
+	}
+
+	for buf = buf[2:]; len(buf) > 0; buf = buf[33:] {
+		if buf[0] > 1 {
+			return nil, errors.New("ParseMerkleLeafProof: bad direction byte")
+		}
+		proof.Path = append(proof.Path, MerkleStep{
+			Hash: append([]byte(nil), buf[1:33]...),
+			Left: buf[0] == 1,
+		})
+	}
+
+	return proof, nil
+}
+
+// SignRoot signs the root of the Merkle tree, so that each of its leaves can
+// then be verified on its own with Public.VerifyLeaf.
+func (sk *Secret) SignRoot(tree *MerkleTree) Signature {
+	return sk.Sign(merkleRootMessage(tree.hash, tree.Root()))
+}
+
+// VerifyLeaf checks whether sig is a signature by pk, made by SignRoot, on a
+// Merkle tree which proof shows includes leaf.
+func (pk *Public) VerifyLeaf(leaf []byte, proof *MerkleLeafProof, sig []byte) bool {
+	if proof == nil {
+		return false
+	}
+	var root = proof.Root(leaf)
+	if root == nil {
+		return false
+	}
+	return pk.Verify(merkleRootMessage(proof.Hash, root), sig)
+}
+
+// merkleRootMessage computes msg = root_str || hash || root.
+func merkleRootMessage(h MerkleHash, root []byte) []byte {
+	var msg = []byte("zed25519_merkle_root")
+	msg = append(msg, byte(h))
+	return append(msg, root...)
+}
+
+// merkleLeaf computes leaf = hash(0x00 || data).
+func merkleLeaf(h MerkleHash, data []byte) []byte {
+	var hash = h.new()
+	hash.Write([]byte{0x00})
+	hash.Write(data)
+	return hash.Sum(nil)
+}
+
+// merkleNode computes node = hash(0x01 || left || right).
+func merkleNode(h MerkleHash, left, right []byte) []byte {
+	var hash = h.new()
+	hash.Write([]byte{0x01})
+	hash.Write(left)
+	hash.Write(right)
+	return hash.Sum(nil)
+}
@@ -0,0 +1,146 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"testing"
+)
+
+// testLeaves returns n distinct leaves.
+func testLeaves(n int) [][]byte {
+	var leaves = make([][]byte, n)
+	for i := range leaves {
+		leaves[i] = []byte{'l', 'e', 'a', 'f', byte('0' + i)}
+	}
+	return leaves
+}
+
+func TestMerkleRoot(t *testing.T) {
+	var leaves = testLeaves(3)
+	var tree, err = BuildTree(leaves, MerkleSHA512_256)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// root = node(node(leaf_0, leaf_1), leaf_2), the odd leaf promoted
+	var h = func(b ...[]byte) []byte {
+		var sum = sha512.Sum512_256(bytes.Join(b, nil))
+		return sum[:]
+	}
+	var l0, l1, l2 = h([]byte{0}, leaves[0]), h([]byte{0}, leaves[1]), h([]byte{0}, leaves[2])
+	var want = h([]byte{1}, h([]byte{1}, l0, l1), l2)
+	if !bytes.Equal(tree.Root(), want) {
+		t.Errorf("root = %x, want %x", tree.Root(), want)
+	}
+}
+
+func TestVerifyMerkleSigned(t *testing.T) {
+	var sk = testSecret(22)
+	var pk = sk.Public()
+	var leaves = testLeaves(5)
+	var tree, _ = BuildTree(leaves, MerkleSHA512_256)
+	var root = tree.Root()
+	var rootSig = sk.Sign(root)
+
+	for i, leaf := range leaves {
+		var proof, err = tree.Proof(i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !VerifyMerkleSigned(pk, rootSig[:], leaf, proof.Path, root) {
+			t.Errorf("leaf %d: valid inclusion rejected", i)
+		}
+
+		// a forged leaf, or a leaf with another leaf's proof
+		if VerifyMerkleSigned(pk, rootSig[:], []byte("forged"), proof.Path, root) {
+			t.Errorf("leaf %d: forged leaf accepted", i)
+		}
+		var j = (i + 1) % len(leaves)
+		if VerifyMerkleSigned(pk, rootSig[:], leaves[j], proof.Path, root) {
+			t.Errorf("leaf %d: leaf %d accepted with its proof", i, j)
+		}
+	}
+
+	var proof, _ = tree.Proof(0)
+	if VerifyMerkleSigned(testSecret(23).Public(), rootSig[:], leaves[0], proof.Path, root) {
+		t.Error("root signature accepted under another key")
+	}
+	var otherTree, _ = BuildTree(testLeaves(4), MerkleSHA512_256)
+	var otherProof, _ = otherTree.Proof(0)
+	if VerifyMerkleSigned(pk, rootSig[:], leaves[0], otherProof.Path, otherTree.Root()) {
+		t.Error("unsigned root accepted")
+	}
+
+	// an inner node cannot pass as a leaf
+	var inner = merkleNode(MerkleSHA512_256, merkleLeaf(MerkleSHA512_256, leaves[0]), merkleLeaf(MerkleSHA512_256, leaves[1]))
+	if VerifyMerkleSigned(pk, rootSig[:], inner, proof.Path[1:], root) {
+		t.Error("inner node accepted as a leaf")
+	}
+}
+
+func TestVerifyLeaf(t *testing.T) {
+	var sk = testSecret(22)
+	var pk = sk.Public()
+	var leaves = testLeaves(7)
+
+	for _, h := range []MerkleHash{MerkleSHA512_256, MerkleSHA3_256} {
+		var tree, err = BuildTree(leaves, h)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var rootSig = sk.SignRoot(tree)
+		for i, leaf := range leaves {
+			var proof, _ = tree.Proof(i)
+			var parsed, err = ParseMerkleLeafProof(proof.Marshal())
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !pk.VerifyLeaf(leaf, parsed, rootSig[:]) {
+				t.Errorf("hash %d, leaf %d: valid leaf rejected", h, i)
+			}
+			if pk.VerifyLeaf([]byte("forged"), parsed, rootSig[:]) {
+				t.Errorf("hash %d, leaf %d: forged leaf accepted", h, i)
+			}
+		}
+
+		// a signature on the root is not a plain signature on the root bytes
+		if pk.Verify(tree.Root(), rootSig[:]) {
+			t.Errorf("hash %d: SignRoot signed the bare root", h)
+		}
+	}
+
+	if _, err := BuildTree(nil, MerkleSHA512_256); err == nil {
+		t.Error("BuildTree accepted no leaves")
+	}
+	if _, err := BuildTree(leaves, 2); err == nil {
+		t.Error("BuildTree accepted an unknown hash")
+	}
+	if pk.VerifyLeaf(leaves[0], nil, make([]byte, 64)) {
+		t.Error("VerifyLeaf accepted a nil proof")
+	}
+}
@@ -0,0 +1,216 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+//
+//  A multi-scalar multiplication computes a sum of many scalar-point
+//  products, a_1 * P_1 + ... + a_n * P_n, much faster than computing each
+//  product separately and adding them up. Two algorithms are used, depending
+//  on the number of products:
+//
+//  - Straus' method, the same one as the ref10 function
+//    GeDoubleScalarMultVartime (which computes the special case a * A + b * B):
+//    every scalar is recoded into signed digits with "slide", a table of odd
+//    multiples P, 3P, ..., 15P is built for each point, and then all the
+//    products share a single chain of 256 doublings, adding in each table
+//    entry where its scalar has a non-zero digit. The cost is about one
+//    addition per point per 6 bits of scalar.
+//
+//  - Pippenger's (bucket) method, for large sets: the scalars are split into
+//    c-bit windows, and for each window, every point is added into one of
+//    2^c - 1 "buckets" by the value of its digit. The buckets are then
+//    summed with weights 1, 2, ..., 2^c - 1 using only about 2^(c+1)
+//    additions, by a running sum from the highest bucket down. The cost is
+//    about one addition per point per c bits of scalar, plus the bucket sums,
+//    so it overtakes Straus once n is large enough for c > 6 to pay off.
+//
+//  Both are variable-time, and must only be used on public data.
+//
+
+// pippengerThreshold is the number of products from which MultiScalarMult
+// switches from Straus' method to Pippenger's.
+const pippengerThreshold = 512
+
+// MultiScalarMult sets r = scalars[0] * points[0] + ... +
+// scalars[n-1] * points[n-1], which is much faster than computing each
+// product with ScalarMultPointVartime and adding them up. The sum of an empty
+// set is the identity. It is variable-time, and must only be used on public
+// data. It panics if the two slices have different lengths.
+func MultiScalarMult(r *Point, scalars []*Scalar, points []*Point) {
+	if len(scalars) != len(points) {
+		panic("MultiScalarMult: mismatched lengths")
+	}
+
+	// Straus needs scalars < 2^255, which any reduced scalar is
+	var straus = len(points) < pippengerThreshold
+	for _, a := range scalars {
+		if a[31] > 127 {
+			straus = false
+		}
+	}
+
+	if straus {
+		multiScalarMultStraus(r, scalars, points)
+	} else {
+		multiScalarMultPippenger(r, scalars, points)
+	}
+}
+
+// multiScalarMultStraus computes MultiScalarMult with Straus' method. Every
+// scalar must be less than 2^255.
+func multiScalarMultStraus(r *Point, scalars []*Scalar, points []*Point) {
+	var n = len(points)
+	var slides = make([][256]int8, n)
+	var tables = make([][8]CachedGroupElement, n) // P,3P,5P,7P,9P,11P,13P,15P
+	var t CompletedGroupElement
+	var u, P2 ExtendedGroupElement
+	var rProj ProjectiveGroupElement
+
+	// recode scalars, and compute tables of odd multiples of each point
+	for j, p := range points {
+		var a = [32]byte(*scalars[j])
+		slide(&slides[j], &a)
+
+		p.ToCached(&tables[j][0])
+		p.Double(&t)
+		t.ToExtended(&P2)
+		for k := 0; k < 7; k++ {
+			geAdd(&t, &P2, &tables[j][k])
+			t.ToExtended(&u)
+			u.ToCached(&tables[j][k+1])
+		}
+	}
+
+	rProj.Zero()
+
+	// skip leading zero digits
+	var i int
+	for i = 255; i >= 0; i-- {
+		var nonzero = false
+		for j := range slides {
+			if slides[j][i] != 0 {
+				nonzero = true
+				break
+			}
+		}
+		if nonzero {
+			break
+		}
+	}
+
+	for ; i >= 0; i-- {
+		rProj.Double(&t)
+
+		for j := range slides {
+			if d := slides[j][i]; d > 0 {
+				t.ToExtended(&u)
+				geAdd(&t, &u, &tables[j][d/2])
+			} else if d < 0 {
+				t.ToExtended(&u)
+				geSub(&t, &u, &tables[j][(-d)/2])
+			}
+		}
+
+		t.ToProjective(&rProj)
+	}
+
+	rProj.ToExtended(r)
+}
+
+// multiScalarMultPippenger computes MultiScalarMult with Pippenger's method.
+func multiScalarMultPippenger(r *Point, scalars []*Scalar, points []*Point) {
+
+	// c = window size in bits, growing with the number of points
+	var c = 7
+	if len(points) >= 2048 {
+		c = 8
+	}
+
+	// cached form of each point, for additions
+	var cached = make([]CachedGroupElement, len(points))
+	for j, p := range points {
+		p.ToCached(&cached[j])
+	}
+
+	var buckets = make([]Point, (1<<uint(c))-1)
+	var t CompletedGroupElement
+	var sum, total, tmp CachedGroupElement
+	PointIdentity(r)
+
+	// process windows from the most significant down
+	var windows = (256 + c - 1) / c
+	for w := windows - 1; w >= 0; w-- {
+
+		// r = 2^c * r
+		var rProj ProjectiveGroupElement
+		r.ToProjective(&rProj)
+		for k := 0; k < c-1; k++ {
+			rProj.Double(&t)
+			t.ToProjective(&rProj)
+		}
+		rProj.Double(&t)
+		t.ToExtended(r)
+
+		// bucket[d - 1] = sum of the points whose digit in this window is d
+		for k := range buckets {
+			PointIdentity(&buckets[k])
+		}
+		for j, a := range scalars {
+			if d := scalarWindow(a, w*c, c); d != 0 {
+				geAdd(&t, &buckets[d-1], &cached[j])
+				t.ToExtended(&buckets[d-1])
+			}
+		}
+
+		// r += 1 * bucket[0] + 2 * bucket[1] + ... + (2^c - 1) * bucket[2^c - 2]
+		var runningSum, windowSum Point
+		PointIdentity(&runningSum)
+		PointIdentity(&windowSum)
+		for k := len(buckets) - 1; k >= 0; k-- {
+			buckets[k].ToCached(&tmp)
+			geAdd(&t, &runningSum, &tmp)
+			t.ToExtended(&runningSum)
+			runningSum.ToCached(&sum)
+			geAdd(&t, &windowSum, &sum)
+			t.ToExtended(&windowSum)
+		}
+		windowSum.ToCached(&total)
+		geAdd(&t, r, &total)
+		t.ToExtended(r)
+	}
+}
+
+// scalarWindow extracts the c-bit unsigned digit of a starting at bit pos.
+func scalarWindow(a *Scalar, pos, c int) int {
+	var d = 0
+	for k := c - 1; k >= 0; k-- {
+		d <<= 1
+		if b := pos + k; b < 256 {
+			d |= int(a[b/8]>>uint(b%8)) & 1
+		}
+	}
+	return d
+}
@@ -0,0 +1,339 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"strconv"
+)
+
+//
+//  MuSig lets n signers, each with their own keypair (x_i, A_i), jointly
+//  produce a single signature under an aggregate public key X, which verifies
+//  with plain Ed25519 Verify. Every signer must take part (n-of-n), so this
+//  can be used to split a key between e.g. a "hot" and a "cold" device,
+//  without any change for verifiers.
+//
+//  Key aggregation weights each key by a coefficient bound to the whole set,
+//  so that no signer can choose their key to cancel out the others' (the
+//  "rogue key" attack):
+//
+//    L = sha512(keys_str || As_1 || ... || As_n)
+//    a_i = sha512(coef_str || L || As_i) % q
+//    X = a_1 * A_1 + ... + a_n * A_n
+//
+//  Signing takes three rounds, tracked by a MuSession:
+//
+//    1. Commit: each signer picks a fresh random nonce r_i, computes
+//       R_i = r_i * G, and sends t_i = sha512(commit_str || Rs_i)[:32].
+//
+//    2. Reveal: once it has every commitment, each signer sends R_i.
+//
+//    3. Sign: once it has every R_i, and checked each against its
+//       commitment, each signer computes
+//
+//         R = R_1 + ... + R_n
+//         h = sha512(Rs || Xs || m) % q
+//         s_i = (r_i + h * a_i * x_i) % q
+//
+//       and the signature is (R, s_1 + ... + s_n).
+//
+//  The commitment round stops the last signer to reveal from choosing their
+//  R_i based on the others' (Wagner's attack). Nonces must never be reused,
+//  or derived deterministically from the message alone, since another signer
+//  could then make the session restart with a different R and learn x_i from
+//  the two partial signatures. A MuSession therefore draws its nonce from the
+//  system's random number generator (hedged with the secret prefix), and
+//  refuses to sign twice.
+//
+//  REFERENCES:
+//    [1] Gregory Maxwell, Andrew Poelstra, Yannick Seurin, Pieter Wuille
+//        "Simple Schnorr Multi-Signatures with Applications to Bitcoin"
+//        https://eprint.iacr.org/2018/068
+//
+
+// AggregatePublic computes the MuSig aggregate public key X of a set of
+// public keys, under which MuSession produces signatures. The order of the
+// keys matters, and every signer must use the same order. It panics if pks is
+// empty. It does not check the keys for torsion components; NewMuSession
+// does.
+func AggregatePublic(pks []*Public) *Public {
+	if len(pks) == 0 {
+		panic("AggregatePublic: no public keys")
+	}
+
+	var L = muKeysHash(pks)
+
+	// X = a_1 * A_1 + ... + a_n * A_n
+	var scalars = make([]*Scalar, len(pks))
+	var points = make([]*Point, len(pks))
+	for i, pk := range pks {
+		var a = muCoefficient(&L, pk)
+		scalars[i] = &a
+		points[i] = &pks[i].point
+	}
+
+	var agg = &Public{}
+	MultiScalarMult(&agg.point, scalars, points)
+
+	return agg
+}
+
+// MuSession is one signer's state in a MuSig signing session, which moves
+// through the rounds Commit, Reveal, Sign and (by any party) Combine, in that
+// order. A session signs one message once; start a new session for every
+// signature.
+type MuSession struct {
+	sk    *Secret
+	pks   []*Public
+	index int
+	msg   []byte
+
+	agg  *Public
+	coef Scalar
+
+	r           Scalar
+	rs          Buffer256
+	commitments []Buffer256
+	nonce       Buffer256
+	round       int
+}
+
+// NewMuSession starts a MuSig session for the signer sk, whose public key
+// must be one of pks, to sign msg under AggregatePublic(pks). It returns an
+// error if any key in pks has a torsion component, since a rogue signer could
+// otherwise use one to make the combined signature verify under some
+// verifiers and not others.
+func NewMuSession(sk *Secret, pks []*Public, msg []byte) (*MuSession, error) {
+	var As = sk.Public().Key()
+	var index = -1
+	for i, pk := range pks {
+		if pk.HasTorsionComponent() {
+			return nil, errors.New("NewMuSession: public key " + strconv.Itoa(i) + " has a torsion component")
+		}
+		if index < 0 && pk.Key() == As {
+			index = i
+		}
+	}
+	if index < 0 {
+		return nil, errors.New("NewMuSession: signer is not in the key set")
+	}
+
+	var L = muKeysHash(pks)
+	return &MuSession{
+		sk:    sk,
+		pks:   pks,
+		index: index,
+		msg:   msg,
+		agg:   AggregatePublic(pks),
+		coef:  muCoefficient(&L, pks[index]),
+	}, nil
+}
+
+// Aggregate gets the aggregate public key which the session signs under.
+func (ms *MuSession) Aggregate() *Public {
+	return ms.agg
+}
+
+// Commit runs the first round: it picks this signer's nonce, and returns the
+// commitment to it, which must be sent to every other signer.
+func (ms *MuSession) Commit() (Buffer256, error) {
+	if ms.round != 0 {
+		return Buffer256{}, errors.New("MuSession.Commit: wrong round")
+	}
+
+	// r = sha512(nonce_str || p || random || m) % q
+	var random Buffer256
+	if _, err := rand.Read(random[:]); err != nil {
+		return Buffer256{}, err
+	}
+	var hash = sha512.New()
+	var res Buffer512
+	hash.Write([]byte("zed25519_musig_nonce"))
+	hash.Write(ms.sk.prefix[:])
+	hash.Write(random[:])
+	hash.Write(ms.msg)
+	hash.Sum(res[:0])
+	ScalarReduce512(&ms.r, &res)
+	wipe(res[:])
+
+	// Rs = compress(r * G)
+	var R Point
+	ScalarMultBase(&R, &ms.r)
+	CompressPoint(&ms.rs, &R)
+
+	ms.round = 1
+	return muCommitment(&ms.rs), nil
+}
+
+// Reveal runs the second round: given the commitments of all the signers, in
+// the same order as the key set (including this signer's own), it returns
+// this signer's nonce point, which must be sent to every other signer.
+func (ms *MuSession) Reveal(commitments []Buffer256) (Buffer256, error) {
+	if ms.round != 1 {
+		return Buffer256{}, errors.New("MuSession.Reveal: wrong round")
+	}
+	if len(commitments) != len(ms.pks) {
+		return Buffer256{}, errors.New("MuSession.Reveal: wrong number of commitments")
+	}
+	if commitments[ms.index] != muCommitment(&ms.rs) {
+		return Buffer256{}, errors.New("MuSession.Reveal: own commitment does not match")
+	}
+
+	ms.commitments = append([]Buffer256{}, commitments...)
+	ms.round = 2
+	return ms.rs, nil
+}
+
+// Sign runs the third round: given the nonce points of all the signers, in
+// the same order as the key set, it checks each against its commitment, and
+// returns this signer's partial signature, which must be sent to whoever
+// combines the signature. After Sign, the session's nonce is destroyed, so
+// it can never be used again.
+func (ms *MuSession) Sign(nonces []Buffer256) (Scalar, error) {
+	if ms.round != 2 {
+		return Scalar{}, errors.New("MuSession.Sign: wrong round")
+	}
+	if len(nonces) != len(ms.pks) {
+		return Scalar{}, errors.New("MuSession.Sign: wrong number of nonces")
+	}
+
+	// R = R_1 + ... + R_n, checking each commitment
+	var R Point
+	PointIdentity(&R)
+	for i := range nonces {
+		var ti = muCommitment(&nonces[i])
+		if subtle.ConstantTimeCompare(ti[:], ms.commitments[i][:]) != 1 {
+			return Scalar{}, errors.New("MuSession.Sign: nonce " + strconv.Itoa(i) + " does not match its commitment")
+		}
+		var Ri Point
+		if !DecompressPoint(&Ri, &nonces[i]) {
+			return Scalar{}, errors.New("MuSession.Sign: nonce " + strconv.Itoa(i) + " is not a valid point")
+		}
+		PointAdd(&R, &R, &Ri)
+	}
+	CompressPoint(&ms.nonce, &R)
+
+	// h = sha512(Rs || Xs || m) % q
+	var h = ms.challenge()
+
+	// s_i = (r_i + h * a_i * x_i) % q
+	var ax, s Scalar
+	ScalarMultScalar(&ax, &ms.coef, &ms.sk.scalar)
+	ScalarMultScalarAddScalar(&s, &h, &ax, &ms.r)
+
+	// destroy the nonce, so the session can never sign again
+	wipe(ax[:])
+	wipe(ms.r[:])
+	ms.round = 3
+
+	return s, nil
+}
+
+// Combine adds up the partial signatures of all the signers, in the same
+// order as the key set, into the final signature, and checks that it is
+// valid for the aggregate public key. It can only be called after Sign.
+func (ms *MuSession) Combine(partials []Scalar) (Signature, error) {
+	if ms.round != 3 {
+		return Signature{}, errors.New("MuSession.Combine: wrong round")
+	}
+	if len(partials) != len(ms.pks) {
+		return Signature{}, errors.New("MuSession.Combine: wrong number of partial signatures")
+	}
+
+	// s = s_1 + ... + s_n
+	var s Scalar
+	for i := range partials {
+		ScalarMultScalarAddScalar(&s, &scalarOne, &s, &partials[i])
+	}
+
+	// sig = Rs || s
+	var sig Signature
+	copy(sig[:32], ms.nonce[:])
+	copy(sig[32:], s[:])
+
+	if !ms.agg.Verify(ms.msg, sig[:]) {
+		return Signature{}, errors.New("MuSession.Combine: combined signature is invalid")
+	}
+
+	return sig, nil
+}
+
+// challenge computes h = sha512(Rs || Xs || m) % q for the session.
+func (ms *MuSession) challenge() Scalar {
+	var hash = sha512.New()
+	var res Buffer512
+	var Xs = ms.agg.Key()
+	var h Scalar
+	hash.Write(ms.nonce[:])
+	hash.Write(Xs[:])
+	hash.Write(ms.msg)
+	hash.Sum(res[:0])
+	ScalarReduce512(&h, &res)
+	return h
+}
+
+// muKeysHash computes L = sha512(keys_str || As_1 || ... || As_n).
+func muKeysHash(pks []*Public) Buffer512 {
+	var hash = sha512.New()
+	var L Buffer512
+	hash.Write([]byte("zed25519_musig_keys"))
+	for _, pk := range pks {
+		var As = pk.Key()
+		hash.Write(As[:])
+	}
+	hash.Sum(L[:0])
+	return L
+}
+
+// muCoefficient computes a_i = sha512(coef_str || L || As_i) % q.
+func muCoefficient(L *Buffer512, pk *Public) Scalar {
+	var hash = sha512.New()
+	var res Buffer512
+	var As = pk.Key()
+	var a Scalar
+	hash.Write([]byte("zed25519_musig_coefficient"))
+	hash.Write(L[:])
+	hash.Write(As[:])
+	hash.Sum(res[:0])
+	ScalarReduce512(&a, &res)
+	return a
+}
+
+// muCommitment computes t = sha512(commit_str || Rs)[:32].
+func muCommitment(Rs *Buffer256) Buffer256 {
+	var hash = sha512.New()
+	var res Buffer512
+	var t Buffer256
+	hash.Write([]byte("zed25519_musig_commitment"))
+	hash.Write(Rs[:])
+	hash.Sum(res[:0])
+	copy(t[:], res[:32])
+	return t
+}
@@ -0,0 +1,170 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"strings"
+	"testing"
+)
+
+// testMuSessions starts a MuSig session for each of the signers, and runs
+// the commit round.
+func testMuSessions(t *testing.T, signers []*Secret, msg []byte) ([]*MuSession, []Buffer256) {
+	var pks = make([]*Public, len(signers))
+	for i, sk := range signers {
+		pks[i] = sk.Public()
+	}
+	var sessions = make([]*MuSession, len(signers))
+	var commitments = make([]Buffer256, len(signers))
+	for i, sk := range signers {
+		var err error
+		if sessions[i], err = NewMuSession(sk, pks, msg); err != nil {
+			t.Fatal(err)
+		}
+		if commitments[i], err = sessions[i].Commit(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return sessions, commitments
+}
+
+func TestMuSig(t *testing.T) {
+	var signers = []*Secret{testSecret(1), testSecret(2), testSecret(3)}
+	var msg = []byte("musig message")
+	var sessions, commitments = testMuSessions(t, signers, msg)
+
+	var agg = AggregatePublic([]*Public{signers[0].Public(), signers[1].Public(), signers[2].Public()})
+	for i, ms := range sessions {
+		if ms.Aggregate().Key() != agg.Key() {
+			t.Fatalf("session %d has another aggregate key", i)
+		}
+	}
+
+	var nonces = make([]Buffer256, len(sessions))
+	for i, ms := range sessions {
+		var err error
+		if nonces[i], err = ms.Reveal(commitments); err != nil {
+			t.Fatalf("session %d: Reveal = %v", i, err)
+		}
+	}
+
+	var partials = make([]Scalar, len(sessions))
+	for i, ms := range sessions {
+		var err error
+		if partials[i], err = ms.Sign(nonces); err != nil {
+			t.Fatalf("session %d: Sign = %v", i, err)
+		}
+	}
+
+	var sig, err = sessions[0].Combine(partials)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !agg.Verify(msg, sig[:]) {
+		t.Error("combined signature does not verify")
+	}
+	if agg.Verify([]byte("another message"), sig[:]) {
+		t.Error("combined signature verifies another message")
+	}
+
+	// the key order matters
+	var swapped = AggregatePublic([]*Public{signers[1].Public(), signers[0].Public(), signers[2].Public()})
+	if swapped.Key() == agg.Key() || swapped.Verify(msg, sig[:]) {
+		t.Error("swapping two keys gives the same aggregate")
+	}
+
+	// a session never signs twice
+	if _, err := sessions[0].Sign(nonces); err == nil {
+		t.Error("Sign ran twice")
+	}
+	if _, err := sessions[0].Commit(); err == nil {
+		t.Error("Commit ran after Sign")
+	}
+
+	// a wrong partial signature is caught by Combine
+	partials[1][0] ^= 1
+	if _, err := sessions[0].Combine(partials); err == nil {
+		t.Error("Combine accepted a wrong partial signature")
+	}
+}
+
+func TestMuSigMismatchedReveal(t *testing.T) {
+	var signers = []*Secret{testSecret(1), testSecret(2)}
+	var msg = []byte("musig message")
+	var sessions, commitments = testMuSessions(t, signers, msg)
+
+	var nonces = make([]Buffer256, len(sessions))
+	for i, ms := range sessions {
+		nonces[i], _ = ms.Reveal(commitments)
+	}
+
+	// signer 1 reveals a nonce other than the one it committed to
+	var other, _ = testMuSessions(t, signers, msg)
+	var otherCommitments = make([]Buffer256, 2)
+	for i := range other {
+		otherCommitments[i] = muCommitment(&other[i].rs)
+	}
+	var changed = []Buffer256{nonces[0], other[1].rs}
+	if _, err := sessions[0].Sign(changed); err == nil || !strings.Contains(err.Error(), "nonce 1 does not match") {
+		t.Errorf("Sign = %v, want a commitment mismatch", err)
+	}
+
+	// a signer's own commitment was replaced
+	var fresh, freshCommitments = testMuSessions(t, signers, msg)
+	freshCommitments[0] = otherCommitments[0]
+	if _, err := fresh[0].Reveal(freshCommitments); err == nil {
+		t.Error("Reveal accepted a replaced own commitment")
+	}
+	if _, err := fresh[0].Reveal(freshCommitments[:1]); err == nil {
+		t.Error("Reveal accepted too few commitments")
+	}
+	if _, err := sessions[1].Sign(nonces[:1]); err == nil {
+		t.Error("Sign accepted too few nonces")
+	}
+}
+
+func TestMuSigRogueKey(t *testing.T) {
+	var honest = testSecret(1)
+	var rogue = testSecret(2).Public()
+
+	// A + T, for a point T of order 8
+	var T Point
+	DecompressPoint(&T, &SmallOrderPoints[1])
+	var mixed = &Public{}
+	PointAdd(&mixed.point, &rogue.point, &T)
+	if !mixed.HasTorsionComponent() {
+		t.Fatal("test key has no torsion component")
+	}
+
+	var _, err = NewMuSession(honest, []*Public{honest.Public(), mixed}, []byte("msg"))
+	if err == nil || !strings.Contains(err.Error(), "public key 1 has a torsion component") {
+		t.Errorf("NewMuSession = %v, want a torsion error", err)
+	}
+
+	if _, err := NewMuSession(honest, []*Public{rogue}, []byte("msg")); err == nil {
+		t.Error("NewMuSession accepted a signer outside the key set")
+	}
+}
@@ -0,0 +1,275 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/binary"
+	"encoding/pem"
+	"errors"
+
+	"golang.org/x/crypto/blowfish"
+	"golang.org/x/crypto/ssh"
+)
+
+//
+//  OpenSSH key formats, so that SSH identities can be used as zed keys and
+//  the other way around:
+//
+//    id_ed25519       "OPENSSH PRIVATE KEY" PEM block (openssh-key-v1)
+//    id_ed25519.pub   authorized_keys line, "ssh-ed25519 <base64> comment"
+//
+//  Private keys can be protected with a passphrase the way ssh-keygen does
+//  it: the key block is encrypted with aes256-ctr, under a key and IV derived
+//  from the passphrase with bcrypt_pbkdf. Like PKCS #8, the private key format
+//  holds the seed, so only keys created with SecretFromSeed can be exported.
+//
+//  REFERENCES:
+//    [1] OpenSSH private key format
+//        https://github.com/openssh/openssh-portable/blob/master/PROTOCOL.key
+//
+
+// openSSHRounds is the number of bcrypt_pbkdf rounds used to encrypt private
+// keys, which is the ssh-keygen default.
+const openSSHRounds = 16
+
+// MarshalOpenSSH encodes the secret key in the OpenSSH private key format,
+// with the given comment. If passphrase is not empty, the key is encrypted
+// with it, as ssh-keygen would. It returns ErrNoSeed if the key has no seed.
+func (sk *Secret) MarshalOpenSSH(comment string, passphrase []byte) ([]byte, error) {
+	var key, ok = sk.StdPrivateKey()
+	if !ok {
+		return nil, ErrNoSeed
+	}
+	defer wipe(key)
+
+	var pubKey = ssh.Marshal(struct {
+		KeyType string
+		Pub     []byte
+	}{ssh.KeyAlgoED25519, key[32:]})
+
+	// check1 = check2 = random
+	var check [4]byte
+	if _, err := rand.Read(check[:]); err != nil {
+		return nil, err
+	}
+	var checkInt = binary.BigEndian.Uint32(check[:])
+
+	var block = ssh.Marshal(struct {
+		Check1  uint32
+		Check2  uint32
+		KeyType string
+		Pub     []byte
+		Priv    []byte
+		Comment string
+	}{checkInt, checkInt, ssh.KeyAlgoED25519, key[32:], key, comment})
+	defer wipe(block)
+
+	var cipherName, kdfName, kdfOpts = "none", "none", ""
+	var blockSize = 8
+	var stream cipher.Stream
+	if len(passphrase) > 0 {
+		var salt [16]byte
+		if _, err := rand.Read(salt[:]); err != nil {
+			return nil, err
+		}
+
+		// key || iv = bcrypt_pbkdf(passphrase, salt, rounds)
+		var k = bcryptPBKDF(passphrase, salt[:], openSSHRounds, 32+16)
+		defer wipe(k)
+		var c, _ = aes.NewCipher(k[:32])
+		stream = cipher.NewCTR(c, k[32:])
+
+		cipherName, kdfName, blockSize = "aes256-ctr", "bcrypt", aes.BlockSize
+		kdfOpts = string(ssh.Marshal(struct {
+			Salt   []byte
+			Rounds uint32
+		}{salt[:], openSSHRounds}))
+	}
+
+	// pad block with 1, 2, 3, ... to a multiple of the cipher block size
+	for i := 1; len(block)%blockSize != 0; i++ {
+		block = append(block, byte(i))
+	}
+	if stream != nil {
+		stream.XORKeyStream(block, block)
+	}
+
+	var der = append([]byte("openssh-key-v1\x00"), ssh.Marshal(struct {
+		CipherName   string
+		KdfName      string
+		KdfOpts      string
+		NumKeys      uint32
+		PubKey       []byte
+		PrivKeyBlock []byte
+	}{cipherName, kdfName, kdfOpts, 1, pubKey, block})...)
+	defer wipe(der)
+
+	return pem.EncodeToMemory(&pem.Block{Type: "OPENSSH PRIVATE KEY", Bytes: der}), nil
+}
+
+// ParseOpenSSH decodes a secret key in the OpenSSH private key format, which
+// must hold an Ed25519 key. If the key is encrypted, passphrase must be the
+// one it was encrypted with; otherwise passphrase must be empty.
+func ParseOpenSSH(data []byte, passphrase []byte) (*Secret, error) {
+	var key interface{}
+	var err error
+	if len(passphrase) > 0 {
+		key, err = ssh.ParseRawPrivateKeyWithPassphrase(data, passphrase)
+	} else {
+		key, err = ssh.ParseRawPrivateKey(data)
+	}
+	if err != nil {
+		return nil, errors.New("ParseOpenSSH: " + err.Error())
+	}
+
+	var edKey ed25519.PrivateKey
+	switch k := key.(type) {
+	case ed25519.PrivateKey:
+		edKey = k
+	case *ed25519.PrivateKey:
+		edKey = *k
+	default:
+		return nil, errors.New("ParseOpenSSH: not an Ed25519 key")
+	}
+	defer wipe(edKey)
+
+	return SecretFromSeed(edKey.Seed()), nil
+}
+
+// MarshalAuthorizedKey encodes the public key as a line of an OpenSSH
+// authorized_keys (or id_ed25519.pub) file, with the given comment, which
+// may be empty.
+func (pk *Public) MarshalAuthorizedKey(comment string) []byte {
+	var As = pk.Key()
+
+	// NewPublicKey cannot fail for an ed25519.PublicKey
+	var sshKey, _ = ssh.NewPublicKey(ed25519.PublicKey(As[:]))
+	var line = bytes.TrimSuffix(ssh.MarshalAuthorizedKey(sshKey), []byte("\n"))
+	if comment != "" {
+		line = append(append(line, ' '), comment...)
+	}
+
+	return append(line, '\n')
+}
+
+// ParseAuthorizedKey decodes a public key from a line of an OpenSSH
+// authorized_keys (or id_ed25519.pub) file, returning it with its comment.
+// The key must be an ssh-ed25519 key.
+func ParseAuthorizedKey(line []byte) (*Public, string, error) {
+	var sshKey, comment, _, _, err = ssh.ParseAuthorizedKey(line)
+	if err != nil {
+		return nil, "", errors.New("ParseAuthorizedKey: " + err.Error())
+	}
+
+	var cryptoKey, ok = sshKey.(ssh.CryptoPublicKey)
+	if !ok {
+		return nil, "", errors.New("ParseAuthorizedKey: not an Ed25519 key")
+	}
+	edKey, ok := cryptoKey.CryptoPublicKey().(ed25519.PublicKey)
+	if !ok {
+		return nil, "", errors.New("ParseAuthorizedKey: not an Ed25519 key")
+	}
+
+	var pk, valid = decodePublic(edKey)
+	if !valid {
+		return nil, "", errors.New("ParseAuthorizedKey: invalid point")
+	}
+
+	return pk, comment, nil
+}
+
+// bcryptPBKDF is bcrypt_pbkdf from OpenBSD, which OpenSSH uses to derive the
+// key encrypting a private key from its passphrase. golang.org/x/crypto/ssh
+// only has it in an internal package, which cannot be imported.
+func bcryptPBKDF(password, salt []byte, rounds, keyLen int) []byte {
+	const blockSize = 32
+	var numBlocks = (keyLen + blockSize - 1) / blockSize
+	var key = make([]byte, numBlocks*blockSize)
+
+	var hash = sha512.New()
+	hash.Write(password)
+	var shapass = hash.Sum(nil)
+	defer wipe(shapass)
+
+	var shasalt = make([]byte, 0, sha512.Size)
+	var cnt [4]byte
+	var tmp, out [blockSize]byte
+	for block := 1; block <= numBlocks; block++ {
+
+		// tmp = bcrypt_hash(shapass, sha512(salt || block))
+		hash.Reset()
+		hash.Write(salt)
+		binary.BigEndian.PutUint32(cnt[:], uint32(block))
+		hash.Write(cnt[:])
+		bcryptHash(tmp[:], shapass, hash.Sum(shasalt))
+
+		// out = tmp xor bcrypt_hash(shapass, sha512(tmp)) xor ...
+		out = tmp
+		for i := 2; i <= rounds; i++ {
+			hash.Reset()
+			hash.Write(tmp[:])
+			bcryptHash(tmp[:], shapass, hash.Sum(shasalt))
+			for j := range out {
+				out[j] ^= tmp[j]
+			}
+		}
+
+		// the output bytes of the blocks are interleaved
+		for i, v := range out {
+			key[i*numBlocks+(block-1)] = v
+		}
+	}
+	wipe(tmp[:])
+	wipe(out[:])
+
+	return key[:keyLen]
+}
+
+// bcryptHash is the bcrypt-based hash function of bcrypt_pbkdf.
+func bcryptHash(out, shapass, shasalt []byte) {
+	var c, _ = blowfish.NewSaltedCipher(shapass, shasalt)
+	for i := 0; i < 64; i++ {
+		blowfish.ExpandKey(shasalt, c)
+		blowfish.ExpandKey(shapass, c)
+	}
+	copy(out, "OxychromaticBlowfishSwatDynamite")
+	for i := 0; i < 32; i += 8 {
+		for j := 0; j < 64; j++ {
+			c.Encrypt(out[i:i+8], out[i:i+8])
+		}
+	}
+
+	// swap bytes of each 32-bit word, due to different endianness
+	for i := 0; i < 32; i += 4 {
+		out[i+3], out[i+2], out[i+1], out[i] = out[i], out[i+1], out[i+2], out[i+3]
+	}
+}
@@ -0,0 +1,158 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+)
+
+//
+//  An oblivious pseudo-random function (OPRF) lets a client compute
+//  F(k, x), a pseudo-random function of its input x under the server's
+//  secret key k, without the server learning x, and without the client
+//  learning k. In a verifiable OPRF (VOPRF), the server also proves that it
+//  used the key k matching its public key K = k * G, so it cannot tag
+//  clients by using a different key for each.
+//
+//    Client: P = hashToPoint(oprf_str || x)
+//            pick random r, B = r * P                  (OprfBlind)
+//    Server: Z = k * B, proof = DLEQ(K, B, Z)          (OprfEvaluate)
+//    Client: check proof, N = (1 / r) * Z = k * P
+//            y = sha512(output_str || x || Ns)         (VoprfFinalize)
+//
+//  The server can compute y = F(k, x) directly when it sees x (OprfOutput),
+//  e.g. to check a token redeemed in a Privacy Pass style protocol.
+//
+
+// OprfBlind hashes the input x to a point and blinds it with a fresh random
+// scalar, returning the blind (which the client must keep secret until
+// finalizing) and the blinded point to send to the server.
+func OprfBlind(x []byte) (blind Scalar, blinded Buffer256, err error) {
+	var buf Buffer512
+	if _, err = rand.Read(buf[:]); err != nil {
+		return
+	}
+	ScalarReduce512(&blind, &buf)
+	if blind == (Scalar{}) {
+		return blind, blinded, errors.New("OprfBlind: zero blind")
+	}
+
+	// B = r * P
+	var P = oprfInputPoint(x)
+	var B Point
+	ScalarMultPoint(&B, &blind, &P)
+	CompressPoint(&blinded, &B)
+
+	return blind, blinded, nil
+}
+
+// OprfEvaluate is the server side of the OPRF: it multiplies the client's
+// blinded point by the secret scalar of sk, and proves with a DLEQ proof that
+// it used the key of sk's public key. An error is returned if the blinded
+// point is not a valid point of the prime-order subgroup.
+func (sk *Secret) OprfEvaluate(blinded Buffer256) (evaluated Buffer256, proof DleqProof, err error) {
+
+	// B = decompress(blinded), or fail
+	var B, I Point
+	PointIdentity(&I)
+	if !DecompressPoint(&B, &blinded) || PointEqual(&B, &I) || !pointTorsionFree(&B) {
+		return evaluated, proof, errors.New("OprfEvaluate: invalid blinded point")
+	}
+
+	// Z = k * B
+	var Z Point
+	ScalarMultPoint(&Z, &sk.scalar, &B)
+	CompressPoint(&evaluated, &Z)
+
+	proof, err = DleqProve(sk, &B, &Z)
+	return evaluated, proof, err
+}
+
+// OprfFinalize unblinds the server's evaluated point and computes the OPRF
+// output for x, without checking that the server used the right key. Use
+// VoprfFinalize to check the server's proof.
+func OprfFinalize(x []byte, blind Scalar, evaluated Buffer256) (Buffer512, error) {
+
+	// Z = decompress(evaluated), or fail
+	var Z Point
+	if !DecompressPoint(&Z, &evaluated) {
+		return Buffer512{}, errors.New("OprfFinalize: invalid evaluated point")
+	}
+
+	// N = (1 / r) * Z
+	var rInv Scalar
+	var N Point
+	ScalarInvert(&rInv, &blind)
+	ScalarMultPoint(&N, &rInv, &Z)
+
+	return oprfOutput(x, &N), nil
+}
+
+// VoprfFinalize checks the server's DLEQ proof that it evaluated the blinded
+// point with the secret key of pk, then unblinds the evaluated point and
+// computes the OPRF output for x, as OprfFinalize does.
+func VoprfFinalize(pk *Public, x []byte, blind Scalar, blinded, evaluated Buffer256, proof DleqProof) (Buffer512, error) {
+	var B, Z Point
+	if !DecompressPoint(&B, &blinded) || !DecompressPoint(&Z, &evaluated) {
+		return Buffer512{}, errors.New("VoprfFinalize: invalid point")
+	}
+	if !DleqVerify(pk, &B, &Z, proof) {
+		return Buffer512{}, errors.New("VoprfFinalize: invalid proof")
+	}
+	return OprfFinalize(x, blind, evaluated)
+}
+
+// OprfOutput computes the OPRF output for x directly with the server's key,
+// giving the same result as the client gets from the blinded protocol.
+func (sk *Secret) OprfOutput(x []byte) Buffer512 {
+	var P = oprfInputPoint(x)
+	var N Point
+	ScalarMultPoint(&N, &sk.scalar, &P)
+	return oprfOutput(x, &N)
+}
+
+// oprfInputPoint computes P = hashToPoint(oprf_str || x).
+func oprfInputPoint(x []byte) Point {
+	var P Point
+	HashToPointVartime(&P, append([]byte("zed25519_oprf"), x...))
+	return P
+}
+
+// oprfOutput computes y = sha512(output_str || x || Ns).
+func oprfOutput(x []byte, N *Point) Buffer512 {
+	var Ns Buffer256
+	CompressPoint(&Ns, N)
+
+	var hash = sha512.New()
+	var y Buffer512
+	hash.Write([]byte("zed25519_oprf_output"))
+	hash.Write(x)
+	hash.Write(Ns[:])
+	hash.Sum(y[:0])
+	return y
+}
@@ -0,0 +1,87 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"errors"
+
+	"golang.org/x/crypto/argon2"
+)
+
+//
+//  Keys derived from a passphrase ("brain wallets") are only as strong as the
+//  passphrase, and a single fast hash such as SHA-256 lets an attacker test
+//  billions of guesses per second. SecretFromPassphrase instead stretches the
+//  passphrase with Argon2id, which is deliberately slow and memory-hard:
+//
+//    seed = argon2id(passphrase, salt, time, memory, threads, 32)
+//    sk = SecretFromSeed(seed)
+//
+//  The salt should be unique to the user (e.g. an account name or email
+//  address), so that one precomputed table of guesses cannot attack all
+//  users at once. The same passphrase, salt and parameters always give the
+//  same key, so the parameters must be kept along with the salt; changing
+//  any of them gives a different key.
+//
+//  Even so, a passphrase must have enough entropy on its own (e.g. a random
+//  mnemonic phrase) to resist guessing; stretching only makes each guess more
+//  expensive.
+//
+
+// Argon2Params are the cost parameters of Argon2id, as used by
+// SecretFromPassphrase.
+type Argon2Params struct {
+	Time    uint32 // number of passes over memory
+	Memory  uint32 // memory in KiB
+	Threads uint8  // degree of parallelism
+}
+
+// DefaultArgon2Params are the second recommended parameters of RFC 9106:
+// 3 passes over 64 MiB of memory, with 4 threads.
+var DefaultArgon2Params = Argon2Params{Time: 3, Memory: 64 * 1024, Threads: 4}
+
+// SecretFromPassphrase derives a Secret Key from a passphrase and salt, by
+// stretching them into a seed with Argon2id and the given parameters. The
+// result has a seed, so it can be exported like a key from SecretFromSeed.
+// An error is returned if the passphrase is empty, the salt is shorter than
+// 8 bytes, or the parameters are invalid.
+func SecretFromPassphrase(passphrase, salt []byte, params Argon2Params) (*Secret, error) {
+	if len(passphrase) == 0 {
+		return nil, errors.New("SecretFromPassphrase: empty passphrase")
+	}
+	if len(salt) < 8 {
+		return nil, errors.New("SecretFromPassphrase: salt too short")
+	}
+	if params.Time < 1 || params.Threads < 1 || params.Memory < 8*uint32(params.Threads) {
+		return nil, errors.New("SecretFromPassphrase: invalid Argon2 parameters")
+	}
+
+	// seed = argon2id(passphrase, salt, time, memory, threads, 32)
+	var seed = argon2.IDKey(passphrase, salt, params.Time, params.Memory, params.Threads, 32)
+	defer wipe(seed)
+
+	return SecretFromSeed(seed), nil
+}
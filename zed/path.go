@@ -0,0 +1,142 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"errors"
+	"strings"
+)
+
+//
+//  A derivation path names a chain of derivations in a single string, in the
+//  style of BIP-32:
+//
+//    m/purpose/account/chain/index
+//
+//  where "m" is the key the path is applied to, and each segment after it
+//  names one derivation step. A segment ending in "'" is "hardened", and
+//  uses secret derivation, so that its children cannot be linked to the
+//  parent public key. Other segments use public derivation, and can also be
+//  followed from a Public.
+//
+//  Each segment s is encoded as a derivation index with EncodeIndex, so that
+//  path indexes can never collide with indexes built some other way, and
+//  hardened segments use the fixed skey pathHardenedKey:
+//
+//    index = EncodeIndex("path", s)
+//
+//  Every step uses DerivationV3, so that each key on a path has its own
+//  prefix (see the warning on derivation prefixes in derive.go). For
+//  example, "m/wallet'/0/7" is the same as:
+//
+//    var c1, _ = sk.DeriveVersion(DerivationV3, EncodeIndex([]byte("path"), []byte("wallet")), pathHardenedKey)
+//    var c2, _ = c1.DeriveVersion(DerivationV3, EncodeIndex([]byte("path"), []byte("0")), nil)
+//    var c3, _ = c2.DeriveVersion(DerivationV3, EncodeIndex([]byte("path"), []byte("7")), nil)
+//
+//  Note that these are zed derivations, and produce different keys than
+//  BIP-32 or SLIP-0010 wallets do for the same path.
+//
+
+// pathHardenedKey is the skey of hardened path segments.
+var pathHardenedKey = []byte("zed25519_path_hardened")
+
+// pathSegment is one parsed step of a derivation path.
+type pathSegment struct {
+	name     string
+	index    []byte
+	hardened bool
+}
+
+// DerivePath derives the child of sk at the given derivation path, such as
+// "m/purpose'/account'/chain/index", deriving once for each segment as
+// described above: hardened segments (ending in "'") use secret derivation,
+// and the others use public derivation. The path "m" returns a copy of sk.
+// An error is returned if the path is malformed.
+func (sk *Secret) DerivePath(path string) (*Secret, error) {
+	var segments, err = parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var nsk = &Secret{}
+	*nsk = *sk
+	for _, seg := range segments {
+		if seg.hardened {
+			nsk.deriveInto(nsk, DerivationV3, seg.index, pathHardenedKey)
+		} else {
+			nsk.deriveInto(nsk, DerivationV3, seg.index, nil)
+		}
+	}
+
+	return nsk, nil
+}
+
+// DerivePath derives the child of pk at the given derivation path, which
+// gives the public key of the child that Secret.DerivePath derives for the
+// same path. An error is returned if the path is malformed, or contains a
+// hardened segment, which cannot be derived from a public key.
+func (pk *Public) DerivePath(path string) (*Public, error) {
+	var segments, err = parsePath(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var npk = &Public{point: pk.point}
+	for _, seg := range segments {
+		if seg.hardened {
+			return nil, errors.New("DerivePath: hardened segment in public derivation: " + seg.name + "'")
+		}
+		npk.deriveInto(npk, DerivationV3, seg.index)
+	}
+
+	return npk, nil
+}
+
+// parsePath splits a derivation path into its segments, after the leading
+// "m".
+func parsePath(path string) ([]pathSegment, error) {
+	var parts = strings.Split(path, "/")
+	if parts[0] != "m" {
+		return nil, errors.New("DerivePath: path must start with \"m\": " + path)
+	}
+
+	var segments = make([]pathSegment, 0, len(parts)-1)
+	for _, part := range parts[1:] {
+		var seg pathSegment
+		if strings.HasSuffix(part, "'") {
+			seg.hardened = true
+			part = part[:len(part)-1]
+		}
+		if part == "" {
+			return nil, errors.New("DerivePath: empty segment in path: " + path)
+		}
+		seg.name = part
+		seg.index = EncodeIndex([]byte("path"), []byte(part))
+		segments = append(segments, seg)
+	}
+
+	return segments, nil
+}
@@ -0,0 +1,102 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"testing"
+)
+
+func TestDerivePath(t *testing.T) {
+	var sk = testSecret(5)
+	var pk = sk.Public()
+
+	// m/wallet'/0/7, step by step
+	var c1, _ = sk.DeriveVersion(DerivationV3, EncodeIndex([]byte("path"), []byte("wallet")), pathHardenedKey)
+	var c2, _ = c1.DeriveVersion(DerivationV3, EncodeIndex([]byte("path"), []byte("0")), nil)
+	var c3, _ = c2.DeriveVersion(DerivationV3, EncodeIndex([]byte("path"), []byte("7")), nil)
+
+	var child, err = sk.DerivePath("m/wallet'/0/7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !child.Equal(c3) {
+		t.Error("DerivePath does not match the documented derivation chain")
+	}
+
+	// the public path from a hardened parent gives the child's public key
+	var pchild, _ = c1.Public().DerivePath("m/0/7")
+	if pchild.Key() != child.Public().Key() {
+		t.Error("Public.DerivePath does not match Secret.DerivePath")
+	}
+
+	// "m" is a copy
+	var same, _ = sk.DerivePath("m")
+	if !same.Equal(sk) || same == sk {
+		t.Error("DerivePath(\"m\") is not a copy of the key")
+	}
+	var psame, _ = pk.DerivePath("m")
+	if psame.Key() != pk.Key() {
+		t.Error("Public.DerivePath(\"m\") is not the same key")
+	}
+
+	// hardened and unhardened segments differ
+	var h, _ = sk.DerivePath("m/0'")
+	var u, _ = sk.DerivePath("m/0")
+	if h.Public().Key() == u.Public().Key() {
+		t.Error("hardened and unhardened segments derive the same key")
+	}
+
+	// path indexes differ from the raw segment
+	if u.Equal(sk.Derive([]byte("0"), nil)) {
+		t.Error("DerivePath uses the raw segment as the index")
+	}
+}
+
+var derivePathErrorTests = []string{
+	"",
+	"x/1",
+	"m/",
+	"m//1",
+	"m/'",
+	"/m/1",
+}
+
+func TestDerivePathErrors(t *testing.T) {
+	var sk = testSecret(5)
+	var pk = sk.Public()
+	for _, path := range derivePathErrorTests {
+		if _, err := sk.DerivePath(path); err == nil {
+			t.Errorf("Secret.DerivePath(%q) did not fail", path)
+		}
+		if _, err := pk.DerivePath(path); err == nil {
+			t.Errorf("Public.DerivePath(%q) did not fail", path)
+		}
+	}
+
+	if _, err := pk.DerivePath("m/0/1'"); err == nil {
+		t.Error("Public.DerivePath accepted a hardened segment")
+	}
+}
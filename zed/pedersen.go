@@ -0,0 +1,106 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"sync"
+)
+
+//
+//  A Pedersen commitment to a value v with blinding factor b is the point
+//
+//    C = v * G + b * H
+//
+//  where H is a second generator whose discrete log with respect to G is
+//  unknown. H is derived by hashing a fixed string to the curve, so nobody
+//  (including whoever wrote this) knows h such that H = h * G. The commitment
+//  hides v as long as b is random, and binds the committer to (v, b) as long
+//  as the discrete log of H is unknown.
+//
+//  Commitments are additively homomorphic:
+//
+//    Commit(v1, b1) + Commit(v2, b2) = Commit(v1 + v2, b1 + b2)
+//    Commit(v1, b1) - Commit(v2, b2) = Commit(v1 - v2, b1 - b2)
+//
+//  so sums of committed amounts can be checked without opening them.
+//
+
+// pedersenH holds the second generator H. Like the base point, it is computed
+// once, on first use; use PedersenH to access it.
+var pedersenH Point
+var pedersenHOnce sync.Once
+
+// PedersenH sets r to the second generator H used by Commit, which is
+// HashToPointVartime("zed25519_pedersen_h").
+func PedersenH(r *Point) {
+	pedersenHOnce.Do(func() {
+		HashToPointVartime(&pedersenH, []byte("zed25519_pedersen_h"))
+	})
+	PointCopy(r, &pedersenH)
+}
+
+// Commit computes the Pedersen commitment C = value * G + blinding * H. Both
+// scalars must be reduced modulo q; Commit panics otherwise.
+func Commit(value, blinding *Scalar) Point {
+	if !ValidScalar(value) || !ValidScalar(blinding) {
+		panic("Commit: scalar not reduced")
+	}
+
+	// C = v * G + b * H
+	var H, vG, bH, C Point
+	PedersenH(&H)
+	ScalarMultBase(&vG, value)
+	ScalarMultPoint(&bH, blinding, &H)
+	PointAdd(&C, &vG, &bH)
+
+	return C
+}
+
+// CommitAdd adds two commitments, giving a commitment to the sum of their
+// values under the sum of their blinding factors.
+func CommitAdd(a, b *Point) Point {
+	var C Point
+	PointAdd(&C, a, b)
+	return C
+}
+
+// CommitSub subtracts commitment b from a, giving a commitment to the
+// difference of their values under the difference of their blinding factors.
+func CommitSub(a, b *Point) Point {
+	var C Point
+	PointSub(&C, a, b)
+	return C
+}
+
+// VerifyCommitment reports whether the commitment c opens to value with the
+// given blinding factor, i.e. whether c = value * G + blinding * H.
+func VerifyCommitment(c *Point, value, blinding *Scalar) bool {
+	if !ValidScalar(value) || !ValidScalar(blinding) {
+		return false
+	}
+	var C = Commit(value, blinding)
+	return PointEqual(c, &C)
+}
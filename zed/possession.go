@@ -0,0 +1,135 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha512"
+)
+
+//
+//  A proof of possession shows that whoever registers a public key knows its
+//  secret scalar. Aggregation schemes which simply add public keys (such as
+//  half-aggregation, or naive multi-signatures) are open to rogue-key
+//  attacks, in which an attacker registers A' = X - A for someone else's key
+//  A, so that the aggregate is a key X it controls. Requiring a proof of
+//  possession with every registered key rules that out.
+//
+//  The proof is a Schnorr proof of knowledge of a, bound to a context string
+//  (e.g. the chain and registration round), with its own domain, so it is
+//  never a valid signature on any message, nor the other way around:
+//
+//    r = sha512(pop_nonce_str || p || ctx) % q,  R = r * G
+//    c = sha512(pop_str || Rs || As || ctx) % q
+//    proof = Rs || (r + c * a) % q
+//
+//  The proof is deterministic: proving possession again for the same context
+//  gives the same proof.
+//
+
+// PossessionProof is a proof of possession of a secret key, made by
+// ProvePossession.
+type PossessionProof [64]byte
+
+// ProvePossession proves knowledge of the secret scalar of sk, bound to the
+// context string. It panics in the same cases as Sign.
+func (sk *Secret) ProvePossession(context []byte) PossessionProof {
+
+	// if prefix is all zeroes, panic
+	if sk.hasZeroPrefix() {
+		panic("ProvePossession: secret key has an all-zero prefix")
+	}
+
+	// sha512 instance, result buffer
+	var hash = sha512.New()
+	var res Buffer512
+
+	var a = sk.Scalar()
+	var p = sk.Prefix()
+	var As = sk.Public().Key()
+
+	// r = sha512(pop_nonce_str || p || ctx) % q
+	var r Scalar
+	hash.Write([]byte("zed25519_pop_nonce"))
+	hash.Write(p[:])
+	hash.Write(context)
+	hash.Sum(res[:0])
+	ScalarReduce512(&r, &res)
+
+	// R = r * G
+	var R Point
+	ScalarMultBase(&R, &r)
+	var Rs = PointToKey(&R)
+
+	// s = (r + ca) % q
+	var c = possessionChallenge(&Rs, &As, context)
+	var s Scalar
+	ScalarMultScalarAddScalar(&s, &c, &a, &r)
+
+	// proof = Rs || s
+	var proof PossessionProof
+	copy(proof[:32], Rs[:])
+	copy(proof[32:], s[:])
+
+	// forget the secrets
+	wipe(a[:])
+	wipe(p[:])
+	wipe(r[:])
+	wipe(res[:])
+
+	return proof
+}
+
+// VerifyPossession checks a proof of possession of the secret key of pk,
+// for the context string. It also fails if pk is not a valid key (see
+// Public.Validate), since no proof can make such a key safe to aggregate.
+func (pk *Public) VerifyPossession(proof PossessionProof, context []byte) bool {
+	if pk.Validate() != nil {
+		return false
+	}
+	var ps, err = ParseSignature(proof[:])
+	if err != nil {
+		return false
+	}
+
+	// valid if: s * G == R + c * A
+	var As = pk.Key()
+	var c = possessionChallenge(&ps.rs, &As, context)
+	return pk.verifyChallenge(ps, &c)
+}
+
+// possessionChallenge computes c = sha512(pop_str || Rs || As || ctx) % q.
+func possessionChallenge(Rs, As *Buffer256, context []byte) Scalar {
+	var hash = sha512.New()
+	var res Buffer512
+	var c Scalar
+	hash.Write([]byte("zed25519_pop"))
+	hash.Write(Rs[:])
+	hash.Write(As[:])
+	hash.Write(context)
+	hash.Sum(res[:0])
+	ScalarReduce512(&c, &res)
+	return c
+}
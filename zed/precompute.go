@@ -0,0 +1,127 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+//
+//  Verifying a signature computes h * A for the signer's public key A, which
+//  (like any multiplication of an arbitrary point) needs a chain of about 256
+//  point doublings. The base point multiplication s * G needs only 4, because
+//  ref10 has tables of the multiples j * 16^i * G built in, so that a scalar
+//  written in signed base-16 digits e[i] can be multiplied by just adding up
+//  the table entries e[i] * 16^i * G.
+//
+//  Precompute builds the same kind of table for a public key, which makes
+//  h * A several times cheaper, and verification about twice as fast, in
+//  exchange for about 80 KB of memory per key. This pays off for keys which
+//  verify many signatures, such as the keys of a fixed set of validators.
+//
+
+// publicTable holds the multiples j * 16^i * A of a public key A, for
+// i = 0..63 and j = 1..8.
+type publicTable [64][8]CachedGroupElement
+
+// Precompute builds a table of multiples of the public key's point, which
+// Verify, VerifyParsed and VrfVerify then use to compute h * A without any
+// point doublings. It is worth calling on keys which will verify many
+// signatures. The table is kept with the key, and is discarded if the key is
+// overwritten by DeriveInto. Calling Precompute again has no effect.
+//
+// Precompute may be called while other goroutines verify with the same key:
+// they use the table once it is complete, and until then compute h * A
+// without it. If several goroutines call Precompute at once, each may build
+// a table, and one of them is kept.
+func (pk *Public) Precompute() {
+	if pk.loadTable() != nil {
+		return
+	}
+
+	var table = &publicTable{}
+	var t CompletedGroupElement
+	var base, u Point
+	PointCopy(&base, &pk.point)
+
+	for i := range table {
+
+		// table[i][j] = (j + 1) * base, for base = 16^i * A
+		base.ToCached(&table[i][0])
+		PointCopy(&u, &base)
+		for j := 1; j < 8; j++ {
+			geAdd(&t, &u, &table[i][0])
+			t.ToExtended(&u)
+			u.ToCached(&table[i][j])
+		}
+
+		// base = 16 * base, from u = 8 * base
+		u.Double(&t)
+		t.ToExtended(&base)
+	}
+
+	pk.table.Store(table)
+}
+
+// loadTable returns the precomputed table of the public key, or nil if
+// Precompute has not been called.
+func (pk *Public) loadTable() *publicTable {
+	var table, _ = pk.table.Load().(*publicTable)
+	return table
+}
+
+// scalarMult sets r = a * A for the public key A, using the precomputed table
+// if there is one. It is variable-time, and a must be reduced.
+func (pk *Public) scalarMult(r *Point, a *Scalar) {
+	var table = pk.loadTable()
+	if table == nil {
+		ScalarMultPointVartime(r, a, &pk.point)
+		return
+	}
+
+	// recode a into signed digits e[i], each between -8 and 8
+	var e [64]int8
+	for i, v := range a {
+		e[2*i] = int8(v & 15)
+		e[2*i+1] = int8((v >> 4) & 15)
+	}
+	var carry = int8(0)
+	for i := 0; i < 63; i++ {
+		e[i] += carry
+		carry = (e[i] + 8) >> 4
+		e[i] -= carry << 4
+	}
+	e[63] += carry
+
+	// r = sum(e[i] * 16^i * A)
+	var t CompletedGroupElement
+	PointIdentity(r)
+	for i, d := range e {
+		if d > 0 {
+			geAdd(&t, r, &table[i][d-1])
+			t.ToExtended(r)
+		} else if d < 0 {
+			geSub(&t, r, &table[i][-d-1])
+			t.ToExtended(r)
+		}
+	}
+}
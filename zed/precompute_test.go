@@ -0,0 +1,100 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"sync"
+	"testing"
+)
+
+func TestPrecompute(t *testing.T) {
+	var sk = testSecret(1)
+	var msg = []byte("message")
+	var sig = sk.Sign(msg)
+	var y, proof = sk.VrfEval(msg)
+
+	var pk = sk.Public()
+	pk.Precompute()
+	pk.Precompute()
+
+	if !pk.Verify(msg, sig[:]) {
+		t.Fatal("Verify with a precomputed table rejected a valid signature")
+	}
+	sig[40] ^= 1
+	if pk.Verify(msg, sig[:]) {
+		t.Fatal("Verify with a precomputed table accepted an invalid signature")
+	}
+	if y2, ok := pk.VrfVerify(msg, proof[:]); !ok || y2 != y {
+		t.Fatal("VrfVerify with a precomputed table failed")
+	}
+
+	// h * A agrees with and without the table, for a range of scalars
+	var plain = sk.Public()
+	for i := 0; i < 32; i++ {
+		var h = Scalar{byte(i), 0xff, byte(3 * i)}
+		h[31] = byte(i) & 15
+		var P1, P2 Point
+		pk.scalarMult(&P1, &h)
+		plain.scalarMult(&P2, &h)
+		if !PointEqual(&P1, &P2) {
+			t.Fatalf("scalar %d: precomputed and plain results differ", i)
+		}
+	}
+
+	// deriving into the key discards the table
+	pk.DeriveInto(pk, []byte("child"))
+	if pk.loadTable() != nil {
+		t.Fatal("DeriveInto kept the table of the parent key")
+	}
+}
+
+// TestPrecomputeConcurrent verifies with a key from several goroutines while
+// its table is built. Run it with -race.
+func TestPrecomputeConcurrent(t *testing.T) {
+	var sk = testSecret(1)
+	var msg = []byte("message")
+	var sig = sk.Sign(msg)
+	var pk = sk.Public()
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			pk.Precompute()
+		}()
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if !pk.Verify(msg, sig[:]) {
+					t.Error("Verify rejected a valid signature")
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
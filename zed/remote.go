@@ -0,0 +1,127 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"errors"
+)
+
+//
+//  A Secret holds its private scalar and nonce prefix in process memory,
+//  which is exactly what a hardware security module (HSM), smart card, or
+//  signing agent exists to prevent. A RemoteSigner is the interface to such a
+//  device: it holds the secret key, and performs the operations which need
+//  it, while everything which needs only the Public Key (verification, public
+//  key derivation, fingerprints, addresses, and so on) stays in this package,
+//  on the Public returned by the signer.
+//
+//  The operations which need the secret key are signing, and VRF
+//  evaluation, since the VRF proof nonce, like the signature nonce, is
+//  derived from the secret prefix. A device which can only make plain
+//  Ed25519 signatures (as PKCS#11 tokens, and ssh-agent, can) returns
+//  ErrRemoteUnsupported from VrfEval.
+//
+//  A device is not trusted to compute correctly: RemoteSign and RemoteVrfEval
+//  check its results against its Public Key before returning them, so that
+//  a faulty device cannot release an invalid signature or proof (which, for
+//  a device computing with a faulty nonce, might also leak its key).
+//
+//  LocalSigner adapts a Secret to the interface, so that code written
+//  against RemoteSigner can also be run with keys in memory.
+//
+
+// ErrRemoteUnsupported is returned by a RemoteSigner for operations which
+// its device does not support.
+var ErrRemoteUnsupported = errors.New("zed: operation not supported by remote signer")
+
+// ErrRemoteInvalid is returned by RemoteSign and RemoteVrfEval when a
+// RemoteSigner returns a result which does not verify.
+var ErrRemoteInvalid = errors.New("zed: remote signer returned an invalid result")
+
+// RemoteSigner is a signer whose secret key is held outside process memory,
+// by a hardware security module, token, or agent.
+type RemoteSigner interface {
+	// Public returns the Public Key of the signer.
+	Public() *Public
+
+	// Sign returns a standard Ed25519 signature on msg, as Secret.Sign does.
+	Sign(msg []byte) (Signature, error)
+
+	// VrfEval evaluates the VRF on the input x, as Secret.VrfEval does, or
+	// returns ErrRemoteUnsupported.
+	VrfEval(x []byte) (VrfResult, VrfProof, error)
+}
+
+// localSigner is a RemoteSigner for a Secret in memory.
+type localSigner struct {
+	sk *Secret
+}
+
+// LocalSigner returns a RemoteSigner which signs with the Secret Key sk.
+func LocalSigner(sk *Secret) RemoteSigner {
+	return &localSigner{sk: sk}
+}
+
+func (ls *localSigner) Public() *Public {
+	return ls.sk.Public()
+}
+
+func (ls *localSigner) Sign(msg []byte) (Signature, error) {
+	return ls.sk.Sign(msg), nil
+}
+
+func (ls *localSigner) VrfEval(x []byte) (VrfResult, VrfProof, error) {
+	var y, proof = ls.sk.VrfEval(x)
+	return y, proof, nil
+}
+
+// RemoteSign signs msg with the RemoteSigner rs, and checks the signature
+// against its Public Key before returning it.
+func RemoteSign(rs RemoteSigner, msg []byte) (Signature, error) {
+	var sig, err = rs.Sign(msg)
+	if err != nil {
+		return Signature{}, err
+	}
+	if !rs.Public().Verify(msg, sig[:]) {
+		return Signature{}, ErrRemoteInvalid
+	}
+	return sig, nil
+}
+
+// RemoteVrfEval evaluates the VRF on the input x with the RemoteSigner rs,
+// and checks the proof and output against its Public Key before returning
+// them.
+func RemoteVrfEval(rs RemoteSigner, x []byte) (VrfResult, VrfProof, error) {
+	var y, proof, err = rs.VrfEval(x)
+	if err != nil {
+		return VrfResult{}, VrfProof{}, err
+	}
+	var yv, verr = rs.Public().VrfVerifyProof(x, &proof)
+	if verr != nil || yv != y {
+		return VrfResult{}, VrfProof{}, ErrRemoteInvalid
+	}
+	return y, proof, nil
+}
@@ -0,0 +1,178 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"errors"
+)
+
+//
+//  An Ed25519 signature (R, s) on a message m satisfies s = r + h * a, where
+//  r is the secret nonce with R = r * G, and h = sha512(R || A || m). If the
+//  same nonce r is ever used to sign two different messages, then the two
+//  signatures share R but have different challenges h1 and h2, and anyone
+//  holding both can solve for the private scalar:
+//
+//    s1 - s2 = (h1 - h2) * a   =>   a = (s1 - s2) / (h1 - h2)
+//
+//  This is exactly why Ed25519 derives r deterministically from the secret
+//  prefix and the message: the same message always gets the same nonce, and
+//  different messages get unrelated nonces. Any scheme which chooses nonces
+//  some other way (random, counter, caller-supplied) must make sure they are
+//  never repeated, or the key is lost.
+//
+//  The functions here are intended for auditing and education, for example
+//  to scan a set of published signatures for reused nonces.
+//
+
+// SameNonce reports whether two signatures share the same nonce commitment R
+// (their first 32 bytes). Two distinct valid signatures by the same key with
+// the same R reveal the private key. Signatures shorter than 32 bytes never
+// match.
+func SameNonce(sigA, sigB []byte) bool {
+	if len(sigA) < 32 || len(sigB) < 32 {
+		return false
+	}
+	return bytes.Equal(sigA[:32], sigB[:32])
+}
+
+// FindReusedNonces scans a list of signatures for reused nonces, and returns
+// the index pairs (i, j), with i < j, of the signatures which share the same
+// nonce commitment R, in order. Signatures shorter than 32 bytes are ignored.
+// Any pair of distinct valid signatures it returns for the same key can be
+// passed to RecoverScalarFromReusedNonce.
+func FindReusedNonces(sigs [][]byte) [][2]int {
+	var seen = make(map[Buffer256][]int)
+	var pairs [][2]int
+	for j, sig := range sigs {
+		if len(sig) < 32 {
+			continue
+		}
+		var Rs Buffer256
+		copy(Rs[:], sig[:32])
+		for _, i := range seen[Rs] {
+			pairs = append(pairs, [2]int{i, j})
+		}
+		seen[Rs] = append(seen[Rs], j)
+	}
+	return pairs
+}
+
+// RecoverScalarFromReusedNonce recovers the private scalar a of pk from two
+// valid signatures sig1 and sig2 on different messages msg1 and msg2 which
+// were made with the same nonce, as described above, and checks that
+// a * G == A. An error is returned if either signature is invalid for pk, the
+// signatures do not share a nonce, or they have the same challenge.
+func RecoverScalarFromReusedNonce(msg1, sig1, msg2, sig2 []byte, pk *Public) (Scalar, error) {
+	var a, reason = recoverReusedNonce(sig1, msg1, sig2, msg2, pk)
+	if reason != "" {
+		return a, errors.New("RecoverScalarFromReusedNonce: " + reason)
+	}
+	return a, nil
+}
+
+// RecoverKeyFromReusedNonce recovers the Secret Key of pk from two valid
+// signatures sigA and sigB on different messages msgA and msgB which were
+// made with the same nonce. The recovered key has the private scalar of pk,
+// and so produces signatures which verify under pk, but its prefix cannot be
+// recovered; a new prefix is derived from the scalar instead, so it will not
+// reproduce the original signatures.
+//
+// An error is returned if either signature is invalid for pk, the signatures
+// do not share a nonce, or they have the same challenge.
+func RecoverKeyFromReusedNonce(sigA, msgA, sigB, msgB []byte, pk *Public) (*Secret, error) {
+	var a, reason = recoverReusedNonce(sigA, msgA, sigB, msgB, pk)
+	if reason != "" {
+		return nil, errors.New("RecoverKeyFromReusedNonce: " + reason)
+	}
+
+	var sk = &Secret{scalar: a}
+	sk.prefix = prefixFromScalar(&sk.scalar)
+	return sk, nil
+}
+
+// recoverReusedNonce recovers the private scalar of pk from two signatures
+// sharing a nonce, or returns the reason it failed.
+func recoverReusedNonce(sigA, msgA, sigB, msgB []byte, pk *Public) (Scalar, string) {
+	var a Scalar
+	if !pk.Verify(msgA, sigA) {
+		return a, "first signature is invalid"
+	}
+	if !pk.Verify(msgB, sigB) {
+		return a, "second signature is invalid"
+	}
+	if !SameNonce(sigA, sigB) {
+		return a, "signatures do not share a nonce"
+	}
+
+	// h1 = sha512(Rs || As || m1) % q, h2 = sha512(Rs || As || m2) % q
+	var As = pk.Key()
+	var h1 = reusedNonceChallenge(sigA[:32], As[:], msgA)
+	var h2 = reusedNonceChallenge(sigB[:32], As[:], msgB)
+
+	// dh = h1 - h2, or fail if zero
+	var dh Scalar
+	ScalarSub(&dh, &h1, &h2)
+	if dh == (Scalar{}) {
+		return a, "signatures have the same challenge"
+	}
+
+	// ds = s1 - s2
+	var s1, s2, ds Scalar
+	copy(s1[:], sigA[32:])
+	copy(s2[:], sigB[32:])
+	ScalarSub(&ds, &s1, &s2)
+
+	// a = ds / dh
+	var dhInv Scalar
+	ScalarInvert(&dhInv, &dh)
+	ScalarMultScalar(&a, &ds, &dhInv)
+
+	// check that a * G == A
+	var A = pk.Point()
+	var aG Point
+	ScalarMultBase(&aG, &a)
+	if !PointEqual(&aG, &A) {
+		return Scalar{}, "recovered scalar does not match public key"
+	}
+
+	return a, ""
+}
+
+// reusedNonceChallenge computes h = sha512(Rs || As || m) % q.
+func reusedNonceChallenge(Rs, As, msg []byte) Scalar {
+	var hash = sha512.New()
+	var res Buffer512
+	var h Scalar
+	hash.Write(Rs)
+	hash.Write(As)
+	hash.Write(msg)
+	hash.Sum(res[:0])
+	ScalarReduce512(&h, &res)
+	return h
+}
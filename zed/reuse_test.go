@@ -0,0 +1,98 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"testing"
+)
+
+func TestRecoverFromReusedNonce(t *testing.T) {
+	var sk = testSecret(20)
+	var pk = sk.Public()
+	var r = Scalar{0x42, 0x13, 0x37}
+	var msg1, msg2 = []byte("pay alice"), []byte("pay mallory")
+
+	var sig1 = sk.SignWithNonce(msg1, &r)
+	var sig2 = sk.SignWithNonce(msg2, &r)
+	if !pk.Verify(msg1, sig1[:]) || !pk.Verify(msg2, sig2[:]) {
+		t.Fatal("signatures with a chosen nonce do not verify")
+	}
+	if !SameNonce(sig1[:], sig2[:]) {
+		t.Fatal("SameNonce missed a reused nonce")
+	}
+
+	// a = (s1 - s2) / (h1 - h2) is the key's scalar, reduced mod q
+	var a, err = RecoverScalarFromReusedNonce(msg1, sig1[:], msg2, sig2[:], pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var want, zero Scalar
+	ScalarMultScalarAddScalar(&want, &scalarOne, &sk.scalar, &zero)
+	if a != want {
+		t.Errorf("recovered scalar %x, want %x", a, want)
+	}
+
+	// the recovered key signs for pk
+	rsk, err := RecoverKeyFromReusedNonce(sig1[:], msg1, sig2[:], msg2, pk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var forged = rsk.Sign([]byte("pay mallory again"))
+	if !pk.Verify([]byte("pay mallory again"), forged[:]) {
+		t.Error("recovered key does not sign for pk")
+	}
+
+	var plain = sk.Sign(msg1)
+	if pairs := FindReusedNonces([][]byte{sig1[:], plain[:], sig2[:]}); len(pairs) != 1 || pairs[0] != [2]int{0, 2} {
+		t.Errorf("FindReusedNonces = %v, want [[0 2]]", pairs)
+	}
+}
+
+func TestRecoverFromReusedNonceErrors(t *testing.T) {
+	var sk = testSecret(20)
+	var pk = sk.Public()
+	var r = Scalar{0x42, 0x13, 0x37}
+	var msg1, msg2 = []byte("pay alice"), []byte("pay mallory")
+	var sig1 = sk.SignWithNonce(msg1, &r)
+	var sig2 = sk.SignWithNonce(msg2, &r)
+	var fresh = sk.Sign(msg2)
+
+	if SameNonce(sig1[:], fresh[:]) || SameNonce(sig1[:], sig1[:31]) {
+		t.Error("SameNonce matched different nonces")
+	}
+	if _, err := RecoverScalarFromReusedNonce(msg1, sig1[:], msg2, fresh[:], pk); err == nil {
+		t.Error("recovered from signatures with different nonces")
+	}
+	if _, err := RecoverScalarFromReusedNonce(msg1, sig1[:], msg1, sig1[:], pk); err == nil {
+		t.Error("recovered from the same signature twice")
+	}
+	if _, err := RecoverScalarFromReusedNonce(msg2, sig1[:], msg2, sig2[:], pk); err == nil {
+		t.Error("recovered from an invalid signature")
+	}
+	if _, err := RecoverKeyFromReusedNonce(sig1[:], msg1, sig2[:], msg2, testSecret(21).Public()); err == nil {
+		t.Error("recovered a key for another public key")
+	}
+}
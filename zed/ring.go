@@ -0,0 +1,245 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"strconv"
+)
+
+//
+//  Ring signatures allow a signer to prove that a message was signed by one of
+//  the members of a "ring" (a list of public keys), without revealing which
+//  member it was. This is the Abe-Ohkubo-Suzuki (AOS) construction over
+//  Ed25519 Schnorr signatures, built only from the package's point and scalar
+//  primitives.
+//
+//  Basic Algorithm, for a ring (A_0, ..., A_n-1) where the signer holds a_j:
+//    L = sha512(ring_str || A_0 || ... || A_n-1)
+//    c_j+1 = H(L || m || alpha * G)
+//    for i = j+1, ..., j-1 (wrapping around the ring):
+//      pick s_i
+//      c_i+1 = H(L || m || s_i * G - c_i * A_i)
+//    s_j = alpha + c_j * a_j
+//    sig = (c_0, s_0, ..., s_n-1)
+//
+//  A verifier recomputes c_1, ..., c_n from c_0 and the s values, and accepts
+//  if the chain closes, i.e. c_n == c_0. Closing the chain requires knowing
+//  the secret scalar of at least one member.
+//
+//  The anonymity set is exactly the ring: a valid signature shows that some
+//  member of the ring signed, and every member is equally likely to have done
+//  so. Signatures are not linkable, so two signatures by the same member
+//  cannot be identified as such. The ring itself (including its order) is
+//  bound into every challenge, so a signature is only valid for the ring it
+//  was made for.
+//
+//  As in Sign, the nonce alpha and the values s_i are derived
+//  deterministically from the signer's secret prefix, the ring and the
+//  message, so no source of randomness is needed.
+//
+
+// RingSig is a ring signature, holding the initial challenge C and one
+// response scalar S[i] for each member of the ring.
+type RingSig struct {
+	C Scalar
+	S []Scalar
+}
+
+// Bytes encodes the ring signature as C || S[0] || ... || S[n-1], which is
+// 32 * (n + 1) bytes long for a ring of n members.
+func (sig *RingSig) Bytes() []byte {
+	var b = make([]byte, 0, 32*(len(sig.S)+1))
+	b = append(b, sig.C[:]...)
+	for i := range sig.S {
+		b = append(b, sig.S[i][:]...)
+	}
+	return b
+}
+
+// ParseRingSig decodes a ring signature encoded by Bytes. The ring size is
+// implied by the length, which must be a multiple of 32 bytes, for a ring of
+// at least one member. An error is returned if the length is wrong, or any
+// scalar is not fully reduced.
+func ParseRingSig(b []byte) (RingSig, error) {
+	if len(b) < 64 || len(b)%32 != 0 {
+		return RingSig{}, errors.New("ParseRingSig: bad ring signature length: " + strconv.Itoa(len(b)))
+	}
+
+	var sig = RingSig{S: make([]Scalar, len(b)/32-1)}
+	copy(sig.C[:], b[:32])
+	if !ValidScalar(&sig.C) {
+		return RingSig{}, errors.New("ParseRingSig: invalid scalar")
+	}
+	for i := range sig.S {
+		copy(sig.S[i][:], b[32*(i+1):])
+		if !ValidScalar(&sig.S[i]) {
+			return RingSig{}, errors.New("ParseRingSig: invalid scalar")
+		}
+	}
+
+	return sig, nil
+}
+
+// ringHash computes L = sha512(ring_str || A_0 || ... || A_n-1), binding the
+// ring members and their order.
+func ringHash(ring []*Public) Buffer512 {
+	var hash = sha512.New()
+	var L Buffer512
+	hash.Write([]byte("zed25519_ring"))
+	for _, pk := range ring {
+		var As = pk.Key()
+		hash.Write(As[:])
+	}
+	hash.Sum(L[:0])
+	return L
+}
+
+// ringChallenge computes c = sha512(L || m || Rs) % q.
+func ringChallenge(L *Buffer512, msg []byte, R *Point) Scalar {
+	var hash = sha512.New()
+	var res Buffer512
+	var Rs Buffer256
+	CompressPoint(&Rs, R)
+	hash.Write(L[:])
+	hash.Write(msg)
+	hash.Write(Rs[:])
+	hash.Sum(res[:0])
+
+	var c Scalar
+	ScalarReduce512(&c, &res)
+	return c
+}
+
+// RingSign signs msg on behalf of ring, which must contain the public key of
+// sk. An error is returned if it does not.
+func (sk *Secret) RingSign(ring []*Public, msg []byte) (RingSig, error) {
+	var n = len(ring)
+
+	// find the signer's position j in the ring
+	var As = sk.Public().Key()
+	var j = -1
+	for i, pk := range ring {
+		if pk.Key() == As {
+			j = i
+			break
+		}
+	}
+	if j < 0 {
+		return RingSig{}, errors.New("RingSign: signer is not a member of the ring")
+	}
+
+	// L = ringHash(ring)
+	var L = ringHash(ring)
+
+	// nonces are derived from sha512(nonce_str || p || L || m || i)
+	var a = sk.Scalar()
+	var p = sk.Prefix()
+	var nonce = func(i int) Scalar {
+		var hash = sha512.New()
+		var res Buffer512
+		var ib [8]byte
+		binary.BigEndian.PutUint64(ib[:], uint64(i))
+		hash.Write([]byte("zed25519_ring_nonce"))
+		hash.Write(p[:])
+		hash.Write(L[:])
+		hash.Write(msg)
+		hash.Write(ib[:])
+		hash.Sum(res[:0])
+
+		var r Scalar
+		ScalarReduce512(&r, &res)
+		return r
+	}
+
+	var sig = RingSig{S: make([]Scalar, n)}
+	var c = make([]Scalar, n)
+
+	// alpha = nonce(j), R = alpha * G
+	var alpha = nonce(j)
+	var R Point
+	ScalarMultBase(&R, &alpha)
+
+	// c_j+1 = H(L || m || R)
+	c[(j+1)%n] = ringChallenge(&L, msg, &R)
+
+	// for every other member, pick s_i and compute the next challenge
+	var sG, cA Point
+	for k := 1; k < n; k++ {
+		var i = (j + k) % n
+
+		// s_i = nonce(i)
+		sig.S[i] = nonce(i)
+
+		// R = s_i * G - c_i * A_i
+		ScalarMultBase(&sG, &sig.S[i])
+		ScalarMultPointVartime(&cA, &c[i], &ring[i].point)
+		PointSub(&R, &sG, &cA)
+
+		// c_i+1 = H(L || m || R)
+		c[(i+1)%n] = ringChallenge(&L, msg, &R)
+	}
+
+	// s_j = (alpha + c_j * a_j) % q
+	ScalarMultScalarAddScalar(&sig.S[j], &c[j], &a, &alpha)
+	sig.C = c[0]
+
+	return sig, nil
+}
+
+// RingVerify checks whether sig is a valid ring signature on msg by one of
+// the members of ring.
+func RingVerify(ring []*Public, msg []byte, sig RingSig) bool {
+	var n = len(ring)
+	if n == 0 || len(sig.S) != n || !ValidScalar(&sig.C) {
+		return false
+	}
+
+	// L = ringHash(ring)
+	var L = ringHash(ring)
+
+	// walk the ring from c_0
+	var c = sig.C
+	var R, sG, cA Point
+	for i := 0; i < n; i++ {
+		if !ValidScalar(&sig.S[i]) {
+			return false
+		}
+
+		// R = s_i * G - c_i * A_i
+		ScalarMultBase(&sG, &sig.S[i])
+		ScalarMultPointVartime(&cA, &c, &ring[i].point)
+		PointSub(&R, &sG, &cA)
+
+		// c_i+1 = H(L || m || R)
+		c = ringChallenge(&L, msg, &R)
+	}
+
+	// valid if the ring closes: c_n == c_0
+	return c == sig.C
+}
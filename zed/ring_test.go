@@ -0,0 +1,109 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"testing"
+)
+
+// testRing returns n secret keys and the ring of their public keys.
+func testRing(n int) ([]*Secret, []*Public) {
+	var sks = make([]*Secret, n)
+	var ring = make([]*Public, n)
+	for i := range sks {
+		sks[i] = testSecret(byte(0x40 + i))
+		ring[i] = sks[i].Public()
+	}
+	return sks, ring
+}
+
+func TestRingSign(t *testing.T) {
+	var sks, ring = testRing(4)
+	var msg = []byte("one of us")
+
+	for j, sk := range sks {
+		var sig, err = sk.RingSign(ring, msg)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !RingVerify(ring, msg, sig) {
+			t.Errorf("signature by member %d does not verify", j)
+		}
+		if RingVerify(ring, []byte("other"), sig) {
+			t.Errorf("signature by member %d verifies on another message", j)
+		}
+
+		// the ring and its order are bound into the signature
+		var swapped = []*Public{ring[1], ring[0], ring[2], ring[3]}
+		if RingVerify(swapped, msg, sig) {
+			t.Errorf("signature by member %d verifies for a reordered ring", j)
+		}
+		if RingVerify(ring[:3], msg, RingSig{C: sig.C, S: sig.S[:3]}) {
+			t.Errorf("signature by member %d verifies for a smaller ring", j)
+		}
+
+		var parsed, perr = ParseRingSig(sig.Bytes())
+		if perr != nil || !RingVerify(ring, msg, parsed) {
+			t.Errorf("signature by member %d does not survive Bytes and ParseRingSig", j)
+		}
+	}
+}
+
+func TestRingSignNonMember(t *testing.T) {
+	var _, ring = testRing(3)
+	var outsider = testSecret(0x50)
+	var msg = []byte("one of us")
+
+	if _, err := outsider.RingSign(ring, msg); err == nil {
+		t.Error("RingSign accepted a signer outside the ring")
+	}
+
+	// sign for a ring containing the outsider, then present it for the real
+	// ring with the outsider's key replaced
+	var forged = append([]*Public{outsider.Public()}, ring[1:]...)
+	var sig, err = outsider.RingSign(forged, msg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !RingVerify(forged, msg, sig) {
+		t.Fatal("signature does not verify for its own ring")
+	}
+	if RingVerify(ring, msg, sig) {
+		t.Error("a non-member's signature verifies for the ring")
+	}
+
+	// an ordinary signature is not a ring signature
+	var plain = outsider.Sign(msg)
+	var fake RingSig
+	copy(fake.C[:], plain[32:])
+	fake.S = make([]Scalar, len(ring))
+	if RingVerify(ring, msg, fake) {
+		t.Error("a made-up ring signature verifies")
+	}
+	if RingVerify(nil, msg, RingSig{}) {
+		t.Error("a signature for an empty ring verifies")
+	}
+}
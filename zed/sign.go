@@ -27,6 +27,10 @@ package zed
 
 import (
 	"crypto/sha512"
+	"errors"
+	"hash"
+	"io"
+	"strconv"
 )
 
 //
@@ -50,7 +54,8 @@ import (
 //  This implementation may also be used in the case that you wish to supply
 //  supply a custom value for "r" when creating a digital signature,
 //  instead of deriving it deterministically from the message and the public
-//  key, as per the spec. Most implementations do not let you do this.
+//  key, as per the spec, with SignWithNonce. Most implementations do not let
+//  you do this. SignWithRandomNonce instead draws r from a random source.
 //
 //  WARNING: ONLY USE A CUSTOM R VALUE IF YOU REALLY KNOW WHAT YOU ARE DOING.
 //  If a key creates signatures on any 2 messages with the same "r" value,
@@ -67,40 +72,215 @@ import (
 // message msg. Such a valid signature on msg can only be produced by a party
 // holding sk, although it can be verified by any party holding the
 // corresponding Public Key.
+//
+// Sign panics if the prefix of sk is all zeroes, as it is for a Secret which
+// was never properly initialized or was imported from a bare scalar. With a
+// zero prefix, the nonce r = sha512(0 || m) depends on the message alone, so
+// anyone could compute it and solve s = r + ha for the private scalar from a
+// single signature. Such keys must be given a prefix with SetPrefixFromScalar
+// first.
 func (sk *Secret) Sign(msg []byte) Signature {
+	var sig, _ = sk.SignWithPublic(msg)
+	return sig
+}
 
-	// sha512 instance, result buffer
-	var hash = sha512.New()
-	var res Buffer512
+// SignWithPublic is the same as Sign, but also returns the compressed public
+// key which was computed while signing, for protocols which transmit the
+// signature, public key and message together.
+func (sk *Secret) SignWithPublic(msg []byte) (sig Signature, pub Buffer256) {
+	return sk.signDom(sha512.New, nil, msg)
+}
 
-	// Take private scalar "a", prefix "p", and public point "A" from ISecret object
-	var a = sk.Scalar()
+// SignCtx produces an Ed25519ctx signature (RFC 8032, section 5.1) by the
+// Secret Key sk on the message msg, bound to the context string ctx, which
+// must be between 1 and 255 bytes long. A signature made for one context
+// never verifies for another context, nor as a plain Ed25519 signature, so
+// one keypair can sign several kinds of messages (e.g. transactions and
+// votes) without any risk of one being passed off as the other. It panics if
+// ctx has a bad length, or in the same cases as Sign.
+func (sk *Secret) SignCtx(msg, ctx []byte) Signature {
+
+	// if ctx length not in 1..255, panic
+	if l := len(ctx); l < 1 || l > 255 {
+		panic("SignCtx: bad context length: " + strconv.Itoa(l))
+	}
+
+	var sig, _ = sk.signDom(sha512.New, dom2(0, ctx), msg)
+	return sig
+}
+
+// signDom signs msg with the domain separation string dom prepended to both
+// hashes, as RFC 8032 does for its Ed25519 variants. For plain Ed25519, dom
+// is empty, and newHash is sha512.New.
+func (sk *Secret) signDom(newHash func() hash.Hash, dom, msg []byte) (sig Signature, pub Buffer256) {
+
+	// if prefix is all zeroes, panic
+	if sk.hasZeroPrefix() {
+		panic("Sign: secret key has an all-zero prefix")
+	}
+
+	// r = sha512(dom || p || m) % q
 	var p = sk.Prefix()
-	var A = sk.Public().Point()
+	var hash = newHash()
+	var res Buffer512
+	var r Scalar
+	hash.Write(dom)
+	hash.Write(p[:])
+	hash.Write(msg)
+	hash.Sum(res[:0])
+	ScalarReduce512(&r, &res)
 
-	// As = compress(A)
-	var As Buffer256
-	CompressPoint(&As, &A)
+	sig, pub = sk.signNonce(newHash, dom, msg, &r)
+
+	// wipe secret temporaries
+	wipe(p[:])
+	wipe(r[:])
+	wipe(res[:])
+
+	return sig, pub
+}
+
+// SignWithNonce produces an Ed25519 signature by the Secret Key sk on the
+// message msg, using the caller's nonce r instead of deriving it from the
+// prefix and the message. The signature verifies like any other, but it is
+// only as safe as r:
+//
+// DANGER: if r is ever used for two different messages, or is predictable,
+// or leaks, anyone holding the signature can compute the private key (see
+// RecoverScalarFromReusedNonce). Use Sign unless a protocol requires a
+// specific nonce, and never use it with a nonce from an untrusted source.
+//
+// SignWithNonce panics if r is zero or not fully reduced.
+func (sk *Secret) SignWithNonce(msg []byte, r *Scalar) Signature {
+	var zero Scalar
+	if !ValidScalar(r) || ScalarEqual(r, &zero) {
+		panic("SignWithNonce: invalid nonce")
+	}
+
+	var sig, _ = sk.signNonce(sha512.New, nil, msg, r)
+	return sig
+}
 
-	// r = sha512(p || m) % q
+// SignWithRandomNonce produces an Ed25519 signature by the Secret Key sk on
+// the message msg, with a nonce r drawn from 64 bytes of rand instead of
+// being derived from the message. Signing the same message twice gives two
+// different signatures, which both verify, so an attacker cannot compare a
+// faulty signature with a correct one on the same message to solve for the
+// private scalar, as for Sign.
+//
+// The key is only as safe as rand: if it ever repeats or is predictable, the
+// private key can be computed from the signatures (see
+// RecoverScalarFromReusedNonce). SignHedged resists faults in the same way
+// without this risk. An error is returned if rand fails.
+func (sk *Secret) SignWithRandomNonce(msg []byte, rand io.Reader) (Signature, error) {
+
+	// r = random % q
+	var random Buffer512
+	if _, err := io.ReadFull(rand, random[:]); err != nil {
+		return Signature{}, errors.New("SignWithRandomNonce: " + err.Error())
+	}
 	var r Scalar
-	hash.Reset()
+	ScalarReduce512(&r, &random)
+
+	var sig, _ = sk.signNonce(sha512.New, nil, msg, &r)
+
+	// wipe secret temporaries
+	wipe(random[:])
+	wipe(r[:])
+
+	return sig, nil
+}
+
+// SignHedged produces an Ed25519 signature by the Secret Key sk on the
+// message msg, with a hedged nonce which mixes 32 fresh random bytes Z from
+// rand into the deterministic derivation of Sign:
+//
+//   r = sha512(hedge_str || p || Z || m) % q
+//
+// As with SignWithRandomNonce, signing the same message twice gives two
+// different signatures, which defeats fault attacks comparing a faulty
+// signature with a correct one, and side channels averaged over many
+// signatures of the same message. Unlike it, the nonce stays secret as long
+// as the prefix p does, even if rand is broken or predictable, in which case
+// the signatures are simply as safe as those of Sign. The signatures verify
+// like any other.
+//
+// rand is normally crypto/rand.Reader; a fixed reader makes the signatures
+// reproducible, for tests. An error is returned if rand fails, rather than
+// signing without randomness. SignHedged panics in the same cases as Sign.
+//
+// REFERENCES:
+//   [1] Phillip Hallam-Baker, Bjoern Haase et al., "Deterministic Nonce-based
+//       Signatures with Noise Injection"
+//       https://datatracker.ietf.org/doc/draft-irtf-cfrg-det-sigs-with-noise/
+func (sk *Secret) SignHedged(msg []byte, rand io.Reader) (Signature, error) {
+	return sk.signHedged(sha512.New, msg, rand)
+}
+
+// signHedged implements SignHedged with the hash function newHash.
+func (sk *Secret) signHedged(newHash func() hash.Hash, msg []byte, rand io.Reader) (Signature, error) {
+
+	// if prefix is all zeroes, panic
+	if sk.hasZeroPrefix() {
+		panic("SignHedged: secret key has an all-zero prefix")
+	}
+
+	// Z = 32 random bytes
+	var Z Buffer256
+	if _, err := io.ReadFull(rand, Z[:]); err != nil {
+		return Signature{}, errors.New("SignHedged: " + err.Error())
+	}
+
+	// r = sha512(hedge_str || p || Z || m) % q
+	var p = sk.Prefix()
+	var hash = newHash()
+	var res Buffer512
+	var r Scalar
+	hash.Write([]byte("zed25519_hedged_nonce"))
 	hash.Write(p[:])
+	hash.Write(Z[:])
 	hash.Write(msg)
 	hash.Sum(res[:0])
 	ScalarReduce512(&r, &res)
 
+	var sig, _ = sk.signNonce(newHash, nil, msg, &r)
+
+	// wipe secret temporaries
+	wipe(p[:])
+	wipe(r[:])
+	wipe(res[:])
+
+	return sig, nil
+}
+
+// signNonce signs msg with the domain separation string dom and the nonce r,
+// which it does not wipe, hashing the challenge with newHash.
+func (sk *Secret) signNonce(newHash func() hash.Hash, dom, msg []byte, r *Scalar) (sig Signature, pub Buffer256) {
+
+	// sha512 instance, result buffer
+	var hash = newHash()
+	var res Buffer512
+
+	// Take private scalar "a" and public point "A" from ISecret object
+	var a = sk.Scalar()
+	var A = sk.Public().Point()
+
+	// As = compress(A)
+	var As Buffer256
+	CompressPoint(&As, &A)
+
 	// R = r * G
 	var R Point
-	ScalarMultBase(&R, &r)
+	ScalarMultBase(&R, r)
 
 	// Rs = compress(R)
 	var Rs Buffer256
 	CompressPoint(&Rs, &R)
 
-	// h = sha512(Rs || As || m) % q
+	// h = sha512(dom || Rs || As || m) % q
 	var h Scalar
 	hash.Reset()
+	hash.Write(dom)
 	hash.Write(Rs[:])
 	hash.Write(As[:])
 	hash.Write(msg[:])
@@ -109,72 +289,201 @@ func (sk *Secret) Sign(msg []byte) Signature {
 
 	// s = (r + ha) % q
 	var s Scalar
-	ScalarMultScalarAddScalar(&s, &h, &a, &r)
+	ScalarMultScalarAddScalar(&s, &h, &a, r)
+
+	// wipe secret temporaries
+	wipe(a[:])
 
 	// sig = Rs || s
-	var sig Signature
 	copy(sig[:], Rs[:])
 	copy(sig[32:], s[:])
 
+	return sig, As
+}
+
+// SignFaultResistant produces the same signature as Sign, but checks it with
+// Verify before returning it, and panics if the check fails.
+//
+// Because Ed25519 nonces are deterministic, an attacker who can induce a
+// hardware fault while a message is being signed (voltage glitching, rowhammer,
+// etc.) can compare the faulty signature with a correct signature on the same
+// message and solve for the private scalar. Checking the signature before it
+// is released means a faulty signature never leaves this function. This does
+// not protect against an attacker who can fault both the signing and the
+// check, nor against any side channel other than faults.
+//
+// The check costs one verification, which makes this roughly 3 times slower
+// than Sign. Use it for signers running on hardware where fault injection is
+// part of the threat model.
+func (sk *Secret) SignFaultResistant(msg []byte) Signature {
+	var sig = sk.Sign(msg)
+	sk.checkSignature(msg, &sig)
 	return sig
 }
 
+// checkSignature is the self-check of SignFaultResistant: it panics unless sig
+// is a valid signature on msg for sk.
+func (sk *Secret) checkSignature(msg []byte, sig *Signature) {
+
+	// recompute the public key, rather than trusting any value used in Sign
+	if !sk.Public().Verify(msg, sig[:]) {
+		panic("SignFaultResistant: signature failed self-check")
+	}
+}
+
 // Verify checks whether the signature sig on the message msg is valid for
 // the Public Key pk, proving it must have been produced by a party which
 // holds the corresponding Secret Key.
 func (pk *Public) Verify(msg, sig []byte) bool {
+	var _, ok = pk.VerifyRecoverR(msg, sig)
+	return ok
+}
+
+// VerifyRecoverR performs the same check as Verify, but additionally returns
+// the decompressed nonce point R of the signature, which adaptor signature
+// protocols need in order to relate a completed signature to its
+// pre-signature. R is only meaningful when ok is true; on failure it is
+// returned in an unspecified state and must not be used.
+func (pk *Public) VerifyRecoverR(msg, sig []byte) (R Point, ok bool) {
+	var ps, err = ParseSignature(sig)
+	if err != nil {
+		return R, false
+	}
+	return ps.r, pk.VerifyParsed(msg, ps)
+}
+
+// ParsedSignature holds a signature which has already been decoded into its
+// curve point R and scalar s, so that it can be verified against several
+// public keys without being decoded again each time.
+type ParsedSignature struct {
+	rs Buffer256
+	r  Point
+	s  Scalar
+}
+
+// ParseSignature decodes a 64-byte signature, decompressing its R point and
+// checking that its s scalar is fully reduced. An error is returned if the
+// signature could never be valid for any public key.
+//
+// Every verification function parses signatures this way, so they all reject
+// an s with any of the top 3 bits of sig[63] set (sig[63]&224 != 0, i.e.
+// s >= 2^253), and any other s >= q. They accept an R in a non-canonical
+// encoding, as long as it decodes to a point; see CanonicalizeSignature and
+// VerifyCanonical for rejecting those too.
+func ParseSignature(sig []byte) (*ParsedSignature, error) {
 
 	// if sig length != 64, or bits incorrect, fail
-	if len(sig) != 64 || sig[63]&224 != 0 {
-		return false
+	if l := len(sig); l != 64 {
+		return nil, errors.New("ParseSignature: bad signature length: " + strconv.Itoa(l))
+	}
+	if sig[63]&224 != 0 {
+		return nil, errors.New("ParseSignature: invalid scalar")
+	}
+
+	var ps = &ParsedSignature{}
+
+	// Rs = sig[:32]
+	copy(ps.rs[:], sig[:32])
+
+	// R = decompress(Rs), or fail
+	if !DecompressPoint(&ps.r, &ps.rs) {
+		return nil, errors.New("ParseSignature: invalid point")
+	}
+
+	// s = sig[32:]
+	copy(ps.s[:], sig[32:])
+
+	// if s >= q, fail
+	if !ValidScalar(&ps.s) {
+		return nil, errors.New("ParseSignature: invalid scalar")
 	}
 
+	return ps, nil
+}
+
+// VerifyParsed checks whether the parsed signature ps on the message msg is
+// valid for the Public Key pk. It gives the same result as Verify on the
+// original signature bytes.
+func (pk *Public) VerifyParsed(msg []byte, ps *ParsedSignature) bool {
+
 	// init sha512 instance, result buffer
 	var hash = sha512.New()
 	var res Buffer512
 
-	// Get As and A from public key object
+	// Get As from public key object
 	var As = pk.Key()
-	var A = pk.Point()
 
-	// Rs = sig[:32]
-	var Rs Buffer256
-	copy(Rs[:], sig[:32])
+	// h = sha512(Rs || As || m) % q
+	var h Scalar
+	hash.Write(ps.rs[:])
+	hash.Write(As[:])
+	hash.Write(msg[:])
+	hash.Sum(res[:0])
+	ScalarReduce512(&h, &res)
 
-	// R = decompress(Rs), or fail
-	var R Point
-	if !DecompressPoint(&R, &Rs) {
+	return pk.verifyChallenge(ps, &h)
+}
+
+// VerifyCtx checks whether sig is a valid Ed25519ctx signature, made with
+// SignCtx, on the message msg for the context ctx and the Public Key pk. It
+// returns false if ctx is not between 1 and 255 bytes long.
+func (pk *Public) VerifyCtx(msg, sig, ctx []byte) bool {
+
+	// if ctx length not in 1..255, fail
+	if l := len(ctx); l < 1 || l > 255 {
 		return false
 	}
 
-	// s = sig[32:]
-	var s Scalar
-	copy(s[:], sig[32:])
-
-	// if s >= q, fail
-	if !ValidScalar(&s) {
+	var ps, err = ParseSignature(sig)
+	if err != nil {
 		return false
 	}
 
-	// h = sha512(Rs || As || m) % q
+	// init sha512 instance, result buffer
+	var hash = sha512.New()
+	var res Buffer512
+
+	// Get As from public key object
+	var As = pk.Key()
+
+	// h = sha512(dom2(0, ctx) || Rs || As || m) % q
 	var h Scalar
-	hash.Write(Rs[:])
+	hash.Write(dom2(0, ctx))
+	hash.Write(ps.rs[:])
 	hash.Write(As[:])
 	hash.Write(msg[:])
 	hash.Sum(res[:0])
 	ScalarReduce512(&h, &res)
 
+	return pk.verifyChallenge(ps, &h)
+}
+
+// dom2 builds the RFC 8032 domain separation string for Ed25519ctx (phflag
+// 0) and Ed25519ph (phflag 1):
+//
+//   dom2 = "SigEd25519 no Ed25519 collisions" || phflag || len(ctx) || ctx
+func dom2(phflag byte, ctx []byte) []byte {
+	var dom = []byte("SigEd25519 no Ed25519 collisions")
+	dom = append(dom, phflag, byte(len(ctx)))
+	return append(dom, ctx...)
+}
+
+// verifyChallenge checks the Ed25519 verification equation for a parsed
+// signature, given the challenge scalar h which the caller has computed from
+// the message, however it was hashed.
+func (pk *Public) verifyChallenge(ps *ParsedSignature, h *Scalar) bool {
+
 	// sB = s * G
 	var sB Point
-	ScalarMultBase(&sB, &s)
+	ScalarMultBase(&sB, &ps.s)
 
 	// hA = h * A
 	var hA Point
-	ScalarMultPointVartime(&hA, &h, &A)
+	pk.scalarMult(&hA, h)
 
 	// RphA = R + hA
 	var RphA Point
-	PointAdd(&RphA, &R, &hA)
+	PointAdd(&RphA, &ps.r, &hA)
 
 	// valid if: sB == R + hA
 	return PointEqual(&sB, &RphA)
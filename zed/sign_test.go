@@ -0,0 +1,197 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"testing"
+)
+
+func TestVerifyRecoverR(t *testing.T) {
+	var sk = testSecret(1)
+	var pk = sk.Public()
+	var sig = sk.Sign([]byte("adaptor"))
+
+	var R, ok = pk.VerifyRecoverR([]byte("adaptor"), sig[:])
+	if !ok {
+		t.Fatal("VerifyRecoverR rejected a valid signature")
+	}
+	var Rs Buffer256
+	CompressPoint(&Rs, &R)
+	if string(Rs[:]) != string(sig[:32]) {
+		t.Error("recovered R does not recompress to sig[:32]")
+	}
+
+	if _, ok := pk.VerifyRecoverR([]byte("other"), sig[:]); ok {
+		t.Error("VerifyRecoverR accepted a signature on another message")
+	}
+	if _, ok := pk.VerifyRecoverR([]byte("adaptor"), sig[:63]); ok {
+		t.Error("VerifyRecoverR accepted a truncated signature")
+	}
+}
+
+// expectPanic reports an error unless f panics.
+func expectPanic(t *testing.T, name string, f func()) {
+	t.Helper()
+	defer func() {
+		if recover() == nil {
+			t.Errorf("%s did not panic", name)
+		}
+	}()
+	f()
+}
+
+func TestSignFaultResistant(t *testing.T) {
+	var sk = testSecret(1)
+	var msg = []byte("glitch")
+
+	var sig = sk.SignFaultResistant(msg)
+	if sig != sk.Sign(msg) {
+		t.Error("SignFaultResistant differs from Sign")
+	}
+
+	// a fault in s = (r + ha) % q
+	var faultS = sig
+	faultS[40] ^= 0x10
+	expectPanic(t, "self-check of a faulty s", func() {
+		sk.checkSignature(msg, &faultS)
+	})
+
+	// a fault in r after R = r * G was computed
+	var r = Scalar{1, 2, 3}
+	var faultR = sk.SignWithNonce(msg, &r)
+	r[0]++
+	var other = sk.SignWithNonce(msg, &r)
+	copy(faultR[32:], other[32:])
+	expectPanic(t, "self-check of a faulty r", func() {
+		sk.checkSignature(msg, &faultR)
+	})
+}
+
+// whichSigned returns the index of the first of pks for which sig is a valid
+// signature on msg, or -1, parsing sig only once.
+func whichSigned(pks []*Public, msg, sig []byte) int {
+	var ps, err = ParseSignature(sig)
+	if err != nil {
+		return -1
+	}
+	for i, pk := range pks {
+		if pk.VerifyParsed(msg, ps) {
+			return i
+		}
+	}
+	return -1
+}
+
+func TestVerifyParsed(t *testing.T) {
+	var pks = []*Public{testSecret(1).Public(), testSecret(2).Public(), testSecret(3).Public()}
+	var msg = []byte("parsed")
+	var sig = testSecret(2).Sign(msg)
+
+	var ps, err = ParseSignature(sig[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, pk := range pks {
+		for _, m := range [][]byte{msg, []byte("other")} {
+			if got, want := pk.VerifyParsed(m, ps), pk.Verify(m, sig[:]); got != want {
+				t.Errorf("key %d, message %q: VerifyParsed = %v, Verify = %v", i, m, got, want)
+			}
+		}
+	}
+
+	if i := whichSigned(pks, msg, sig[:]); i != 1 {
+		t.Errorf("whichSigned = %d, want 1", i)
+	}
+	if i := whichSigned(pks, []byte("other"), sig[:]); i != -1 {
+		t.Errorf("whichSigned on another message = %d, want -1", i)
+	}
+
+	if _, err := ParseSignature(sig[:32]); err == nil {
+		t.Error("ParseSignature accepted a short signature")
+	}
+	var high = sig
+	high[63] |= 0x80
+	if _, err := ParseSignature(high[:]); err == nil {
+		t.Error("ParseSignature accepted s >= 2^255")
+	}
+}
+
+func TestSignWithPublic(t *testing.T) {
+	var sk = testSecret(1)
+	var msg = []byte("sig and key")
+
+	var sig, pub = sk.SignWithPublic(msg)
+	if pub != sk.Public().Key() {
+		t.Error("SignWithPublic returned the wrong public key")
+	}
+	if sig != sk.Sign(msg) {
+		t.Error("SignWithPublic signs differently from Sign")
+	}
+	var pk, err = PublicFromKeyStrict(pub[:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pk.Verify(msg, sig[:]) {
+		t.Error("returned public key does not verify the returned signature")
+	}
+}
+
+func TestSignZeroPrefix(t *testing.T) {
+	var sk = testSecret(1)
+	var key = sk.Key()
+	var zero = make([]byte, 64)
+	copy(zero, key[:32])
+
+	// a key with only a scalar refuses to sign
+	var scalarOnly = SecretFromKey(zero)
+	var msg = []byte("zero prefix")
+	expectPanic(t, "Sign", func() { scalarOnly.Sign(msg) })
+	expectPanic(t, "SignWithPublic", func() { scalarOnly.SignWithPublic(msg) })
+	expectPanic(t, "SignCtx", func() { scalarOnly.SignCtx(msg, []byte("ctx")) })
+	expectPanic(t, "SignFaultResistant", func() { scalarOnly.SignFaultResistant(msg) })
+	expectPanic(t, "VrfEval", func() { scalarOnly.VrfEval(msg) })
+
+	// a wiped key too
+	var wiped = SecretFromKey(key[:])
+	wiped.Wipe()
+	expectPanic(t, "Sign after Wipe", func() { wiped.Sign(msg) })
+
+	// SetPrefixFromScalar makes it usable, with a prefix fixed by the scalar
+	scalarOnly.SetPrefixFromScalar()
+	if scalarOnly.hasZeroPrefix() {
+		t.Fatal("SetPrefixFromScalar left a zero prefix")
+	}
+	if scalarOnly.Prefix() != prefixFromScalar(&sk.scalar) {
+		t.Error("SetPrefixFromScalar did not derive the prefix from the scalar")
+	}
+	var sig = scalarOnly.Sign(msg)
+	if !sk.Public().Verify(msg, sig[:]) {
+		t.Error("signature after SetPrefixFromScalar does not verify")
+	}
+	if sig == sk.Sign(msg) {
+		t.Error("derived prefix equals the seed prefix")
+	}
+}
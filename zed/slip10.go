@@ -0,0 +1,98 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"strconv"
+	"strings"
+)
+
+//
+//  SLIP-0010 is the hierarchical deterministic key derivation scheme for
+//  Ed25519 used by hardware wallets such as Ledger and Trezor, and by most
+//  software wallets. It is a variant of BIP-32, which derives child seeds
+//  rather than child scalars:
+//
+//    (k || c) = hmac_sha512("ed25519 seed", seed)
+//    (k' || c') = hmac_sha512(c, 0x00 || k || ser32(i + 2^31))
+//
+//  where k is the child's 32-byte Ed25519 seed, c is its chain code, and i is
+//  the index of a path segment. Ed25519 only supports hardened derivation in
+//  SLIP-0010, so every segment of the path must be hardened.
+//
+//  This is independent of Derive and DerivePath, which use zed's own blinding
+//  scheme: the same path gives different keys in each. Use DeriveSLIP10 to
+//  recover keys that were created by another wallet from the same seed.
+//
+//  REFERENCES:
+//    [1] SatoshiLabs
+//        "SLIP-0010: Universal private key derivation from master private key"
+//        https://github.com/satoshilabs/slips/blob/master/slip-0010.md
+//
+
+// DeriveSLIP10 derives the SLIP-0010 Ed25519 key at the given path, such as
+// "m/44'/148'/0'", from a master seed (typically 16 to 64 bytes, e.g. the
+// output of a BIP-39 mnemonic). Every segment must be a hardened index,
+// marked with "'" or "h". The returned key is a seed-based key, identical to
+// the one a SLIP-0010 wallet derives for the same seed and path. An error is
+// returned if the path is malformed or has a non-hardened segment.
+func DeriveSLIP10(seed []byte, path string) (*Secret, error) {
+	var parts = strings.Split(path, "/")
+	if parts[0] != "m" {
+		return nil, errors.New("DeriveSLIP10: path must start with \"m\": " + path)
+	}
+
+	// (k || c) = hmac_sha512("ed25519 seed", seed)
+	var mac = hmac.New(sha512.New, []byte("ed25519 seed"))
+	mac.Write(seed)
+	var res = mac.Sum(nil)
+
+	for _, part := range parts[1:] {
+		if !strings.HasSuffix(part, "'") && !strings.HasSuffix(part, "h") {
+			return nil, errors.New("DeriveSLIP10: non-hardened segment in path: " + part)
+		}
+		var i, err = strconv.ParseUint(part[:len(part)-1], 10, 31)
+		if err != nil {
+			return nil, errors.New("DeriveSLIP10: bad segment in path: " + part)
+		}
+
+		// data = 0x00 || k || ser32(i + 2^31)
+		var data [37]byte
+		copy(data[1:33], res[:32])
+		binary.BigEndian.PutUint32(data[33:], uint32(i)|0x80000000)
+
+		// (k' || c') = hmac_sha512(c, data)
+		mac = hmac.New(sha512.New, res[32:])
+		mac.Write(data[:])
+		res = mac.Sum(nil)
+	}
+
+	return SecretFromSeed(res[:32]), nil
+}
@@ -0,0 +1,120 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"encoding/hex"
+	"testing"
+)
+
+// Test vectors for ed25519 of SLIP-0010. The public keys are given without
+// the 0x00 prefix of the specification.
+var slip10Tests = []struct {
+	seed  string
+	paths []struct{ path, private, public string }
+}{
+	{"000102030405060708090a0b0c0d0e0f", []struct{ path, private, public string }{
+		{"m",
+			"2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7",
+			"a4b2856bfec510abab89753fac1ac0e1112364e7d250545963f135f2a33188ed"},
+		{"m/0'",
+			"68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3",
+			"8c8a13df77a28f3445213a0f432fde644acaa215fc72dcdf300d5efaa85d350c"},
+		{"m/0'/1'",
+			"b1d0bad404bf35da785a64ca1ac54b2617211d2777696fbffaf208f746ae84f2",
+			"1932a5270f335bed617d5b935c80aedb1a35bd9fc1e31acafd5372c30f5c1187"},
+		{"m/0'/1'/2'",
+			"92a5b23c0b8a99e37d07df3fb9966917f5d06e02ddbd909c7e184371463e9fc9",
+			"ae98736566d30ed0e9d2f4486a64bc95740d89c7db33f52121f8ea8f76ff0fc1"},
+		{"m/0'/1'/2'/2'",
+			"30d1dc7e5fc04c31219ab25a27ae00b50f6fd66622f6e9c913253d6511d1e662",
+			"8abae2d66361c879b900d204ad2cc4984fa2aa344dd7ddc46007329ac76c429c"},
+		{"m/0'/1'/2'/2'/1000000000'",
+			"8f94d394a8e8fd6b1bc2f3f49f5c47e385281d5c17e65324b0f62483e37e8793",
+			"3c24da049451555d51a7014a37337aa4e12d41e485abccfa46b47dfb2af54b7a"},
+	}},
+	{"fffcf9f6f3f0edeae7e4e1dedbd8d5d2cfccc9c6c3c0bdbab7b4b1aeaba8a5a29f9c999693908d8a8784817e7b7875726f6c696663605d5a5754514e4b484542", []struct{ path, private, public string }{
+		{"m",
+			"171cb88b1b3c1db25add599712e36245d75bc65a1a5c9e18d76f9f2b1eab4012",
+			"8fe9693f8fa62a4305a140b9764c5ee01e455963744fe18204b4fb948249308a"},
+		{"m/0h",
+			"1559eb2bbec5790b0c65d8693e4d0875b1747f4970ae8b650486ed7470845635",
+			"86fab68dcb57aa196c77c5f264f215a112c22a912c10d123b0d03c3c28ef1037"},
+		{"m/0h/2147483647h",
+			"ea4f5bfe8694d8bb74b7b59404632fd5968b774ed545e810de9c32a4fb4192f4",
+			"5ba3b9ac6e90e83effcd25ac4e58a1365a9e35a3d3ae5eb07b9e4d90bcf7506d"},
+		{"m/0h/2147483647h/1h",
+			"3757c7577170179c7868353ada796c839135b3d30554bbb74a4b1e4a5a58505c",
+			"2e66aa57069c86cc18249aecf5cb5a9cebbfd6fadeab056254763874a9352b45"},
+		{"m/0h/2147483647h/1h/2147483646h",
+			"5837736c89570de861ebc173b1086da4f505d4adb387c6a1b1342d5e4ac9ec72",
+			"e33c0f7d81d843c572275f287498e8d408654fdf0d1e065b84e2e6f157aab09b"},
+		{"m/0h/2147483647h/1h/2147483646h/2h",
+			"551d333177df541ad876a60ea71f00447931c0a9da16f227c11ea080d7391b8d",
+			"47150c75db263559a70d5778bf36abbab30fb061ad69f69ece61a72b0cfa4fc0"},
+	}},
+}
+
+func TestDeriveSLIP10(t *testing.T) {
+	for _, vector := range slip10Tests {
+		var seed, _ = hex.DecodeString(vector.seed)
+		for _, test := range vector.paths {
+			var sk, err = DeriveSLIP10(seed, test.path)
+			if err != nil {
+				t.Fatalf("%s: %v", test.path, err)
+			}
+			var private, ok = sk.Seed()
+			if !ok || hex.EncodeToString(private[:]) != test.private {
+				t.Errorf("%s: private key = %x, want %s", test.path, private, test.private)
+			}
+			var public = sk.Public().Key()
+			if hex.EncodeToString(public[:]) != test.public {
+				t.Errorf("%s: public key = %x, want %s", test.path, public, test.public)
+			}
+		}
+	}
+}
+
+var slip10ErrorTests = []string{
+	"",
+	"0'/1'",
+	"m/0",           // non-hardened
+	"m/0'/1",        // non-hardened last segment
+	"m/0'/1/2'",     // non-hardened middle segment
+	"m/2147483648'", // out of range
+	"m/'",
+	"m/x'",
+	"m//0'",
+}
+
+func TestDeriveSLIP10Errors(t *testing.T) {
+	var seed, _ = hex.DecodeString(slip10Tests[0].seed)
+	for _, path := range slip10ErrorTests {
+		if sk, err := DeriveSLIP10(seed, path); err == nil || sk != nil {
+			t.Errorf("DeriveSLIP10(%q) did not fail", path)
+		}
+	}
+}
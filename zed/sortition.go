@@ -0,0 +1,169 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"math/big"
+)
+
+//
+//  VRF sortition uses VRF outputs to select participants (for example block
+//  proposers or committee members) with probability proportional to their
+//  weight (for example stake), in a way anyone can verify, as in Algorand.
+//
+//  The 32-byte VRF output y is interpreted as a big-endian fraction in [0, 1):
+//
+//    f = y / 2^256
+//
+//  Each unit of weight is treated as an independent candidate, selected with
+//  probability p = expected / totalWeight, so that on average "expected" units
+//  are selected out of the total. A participant holding "weight" units is
+//  selected if at least one of its units is, which happens with probability
+//
+//    P = 1 - (1 - p)^weight
+//
+//  and so the participant is selected if f < P.
+//
+//  Sortition goes further and counts how many of the participant's units are
+//  selected, so that it can be given that many votes (sub-users). The number
+//  of selected units j follows the binomial distribution B(j; weight, p), and
+//  j is chosen by inverting its cumulative distribution at 1 - f:
+//
+//    j = min { j : 1 - f <= B(0) + ... + B(j) }
+//    B(k) = C(weight, k) * p^k * (1 - p)^(weight - k)
+//
+//  with B(k + 1) = B(k) * (weight - k) / (k + 1) * p / (1 - p), so each step
+//  is one multiplication. Since B(0) = 1 - P, j is 0 exactly when f >= P,
+//  that is when VrfSelected returns false.
+//
+//  REFERENCES:
+//    [1] Yossi Gilad, Rotem Hemo, Silvio Micali, Georgios Vlachos, Nickolai
+//        Zeldovich, "Algorand: Scaling Byzantine Agreements for
+//        Cryptocurrencies", section 5.1
+//        https://people.csail.mit.edu/nickolai/papers/gilad-algorand-eprint.pdf
+//
+
+// sortitionPrec is the precision in bits of the sortition arithmetic, which is
+// comfortably more than the 256 bits of the VRF output.
+const sortitionPrec = 320
+
+// vrfFraction interprets y as a big-endian fraction f = y / 2^256.
+func vrfFraction(y *VrfResult) *big.Float {
+	var n = new(big.Int).SetBytes(y[:])
+	var f = new(big.Float).SetPrec(sortitionPrec).SetInt(n)
+	return f.SetMantExp(f, -256)
+}
+
+// floatPow computes x^n by repeated squaring.
+func floatPow(x *big.Float, n uint64) *big.Float {
+	var res = new(big.Float).SetPrec(sortitionPrec).SetInt64(1)
+	var sq = new(big.Float).SetPrec(sortitionPrec).Set(x)
+	for ; n > 0; n >>= 1 {
+		if n&1 == 1 {
+			res.Mul(res, sq)
+		}
+		sq.Mul(sq, sq)
+	}
+	return res
+}
+
+// VrfSelected decides whether a participant holding weight out of totalWeight
+// is selected by the VRF output y, when on average expected units of weight
+// should be selected. A participant with no weight is never selected, and if
+// expected >= totalWeight, every participant with any weight is selected.
+func VrfSelected(y VrfResult, weight, totalWeight uint64, expected uint64) bool {
+	if weight == 0 || totalWeight == 0 || expected == 0 {
+		return false
+	}
+	if expected >= totalWeight {
+		return true
+	}
+
+	// q = 1 - p = (totalWeight - expected) / totalWeight
+	var q = new(big.Float).SetPrec(sortitionPrec).SetUint64(totalWeight - expected)
+	q.Quo(q, new(big.Float).SetPrec(sortitionPrec).SetUint64(totalWeight))
+
+	// P = 1 - q^weight
+	var P = new(big.Float).SetPrec(sortitionPrec).SetInt64(1)
+	P.Sub(P, floatPow(q, weight))
+
+	// selected if f < P
+	return vrfFraction(&y).Cmp(P) < 0
+}
+
+// Sortition returns how many of the stake units of a participant, out of
+// totalStake, are selected by the VRF output y, when on average expectedSize
+// units should be selected in total, using the binomial method of
+// Algorand. It returns 0 if the participant is not selected at all, and
+// never more than stake. If expectedSize >= totalStake, every unit is
+// selected.
+func Sortition(y VrfResult, stake, totalStake uint64, expectedSize float64) uint64 {
+	if stake == 0 || totalStake == 0 || !(expectedSize > 0) {
+		return 0
+	}
+	if stake > totalStake {
+		stake = totalStake
+	}
+	if expectedSize >= float64(totalStake) {
+		return stake
+	}
+
+	// p = expectedSize / totalStake, q = 1 - p = (totalStake - expectedSize) / totalStake
+	var total = new(big.Float).SetPrec(sortitionPrec).SetUint64(totalStake)
+	var p = new(big.Float).SetPrec(sortitionPrec).SetFloat64(expectedSize)
+	var q = new(big.Float).SetPrec(sortitionPrec).Sub(total, p)
+	p.Quo(p, total)
+	q.Quo(q, total)
+
+	// ratio = p / q
+	var ratio = new(big.Float).SetPrec(sortitionPrec).Quo(p, q)
+
+	// g = 1 - f
+	var g = new(big.Float).SetPrec(sortitionPrec).SetInt64(1)
+	g.Sub(g, vrfFraction(&y))
+
+	// B = B(0) = q^stake, cdf = B
+	var B = floatPow(q, stake)
+	var cdf = new(big.Float).SetPrec(sortitionPrec).Set(B)
+	var factor = new(big.Float).SetPrec(sortitionPrec)
+
+	// j = min { j : g <= cdf(j) }
+	var j uint64
+	for j < stake && g.Cmp(cdf) > 0 {
+
+		// B(j + 1) = B(j) * (stake - j) / (j + 1) * p / q
+		factor.SetUint64(stake - j)
+		B.Mul(B, factor)
+		factor.SetUint64(j + 1)
+		B.Quo(B, factor)
+		B.Mul(B, ratio)
+
+		cdf.Add(cdf, B)
+		j++
+	}
+
+	return j
+}
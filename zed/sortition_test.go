@@ -0,0 +1,112 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"math"
+	"testing"
+)
+
+// testVrfOutputs returns n uniformly distributed, reproducible VRF outputs.
+func testVrfOutputs(n int) []VrfResult {
+	var ys = make([]VrfResult, n)
+	var b [8]byte
+	for i := range ys {
+		binary.BigEndian.PutUint64(b[:], uint64(i))
+		ys[i] = sha256.Sum256(b[:])
+	}
+	return ys
+}
+
+func TestVrfSelectedBoundaries(t *testing.T) {
+	var zero, max VrfResult
+	for i := range max {
+		max[i] = 0xff
+	}
+	var ys = append(testVrfOutputs(16), zero, max)
+
+	for _, y := range ys {
+		if VrfSelected(y, 0, 100, 10) {
+			t.Errorf("weight 0 selected by %x", y)
+		}
+		if !VrfSelected(y, 1, 100, 100) || !VrfSelected(y, 1, 100, 1000) {
+			t.Errorf("expected >= totalWeight does not select %x", y)
+		}
+		if VrfSelected(y, 10, 0, 10) || VrfSelected(y, 10, 100, 0) {
+			t.Errorf("zero total or expected weight selects %x", y)
+		}
+		if Sortition(y, 0, 100, 10) != 0 || Sortition(y, 7, 100, 100) != 7 {
+			t.Errorf("Sortition boundaries fail for %x", y)
+		}
+		if j := Sortition(y, 100, 100, 50); j > 100 {
+			t.Errorf("Sortition selected %d of 100 units", j)
+		}
+	}
+
+	// f = 0 is below any P > 0, and f close to 1 is above any P < 1
+	if !VrfSelected(zero, 1, 1000, 1) {
+		t.Error("y = 0 is not selected")
+	}
+	if VrfSelected(max, 100, 100, 50) {
+		t.Error("y = 2^256 - 1 is selected with P = 1 - 2^-100")
+	}
+	if Sortition(max, 100, 100, 50) != 0 {
+		t.Error("y = 2^256 - 1 selects some units with P = 1 - 2^-100")
+	}
+	if Sortition(zero, 100, 100, 50) != 100 {
+		t.Error("y = 0 does not select every unit")
+	}
+}
+
+func TestVrfSelectedRate(t *testing.T) {
+	const n = 4000
+	var ys = testVrfOutputs(n)
+
+	// p = 20 / 100, P = 1 - 0.8^10
+	var want = 1 - math.Pow(0.8, 10)
+	var selected, units uint64
+	for _, y := range ys {
+		var ok = VrfSelected(y, 10, 100, 20)
+		var j = Sortition(y, 10, 100, 20)
+		if ok != (j > 0) {
+			t.Fatalf("VrfSelected = %v but Sortition = %d for %x", ok, j, y)
+		}
+		if ok {
+			selected++
+		}
+		units += j
+	}
+
+	// within about 5 standard deviations
+	if rate := float64(selected) / n; math.Abs(rate-want) > 0.025 {
+		t.Errorf("selection rate = %.4f, want about %.4f", rate, want)
+	}
+	if mean := float64(units) / n; math.Abs(mean-2) > 0.1 {
+		t.Errorf("mean selected units = %.4f, want about 2", mean)
+	}
+}
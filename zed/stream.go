@@ -0,0 +1,284 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha512"
+	"errors"
+	"hash"
+	"io"
+	"strconv"
+)
+
+//
+//  Streaming variants of Sign and Verify, for messages which are too large to
+//  be held in memory at once, such as files.
+//
+//  Sign and Verify themselves never copy the message: SHA-512 consumes the
+//  []byte passed in place, so signing a multi-gigabyte buffer needs no memory
+//  beyond the buffer itself. The streaming variants instead read the message
+//  through io.Copy, holding only a small fixed-size chunk at a time, and
+//  produce exactly the same signatures as Sign and Verify.
+//
+//  Verification only needs to hash the message once, since R is known from
+//  the signature before the message is read. Signing has to hash the message
+//  twice: once to derive the nonce r, and again after R = r * G is known, to
+//  compute the challenge. SignReader therefore needs an io.ReadSeeker, so that
+//  it can rewind the message between the two passes.
+//
+//  When the message can only be read once, e.g. from a pipe, NewSigner and
+//  NewVerifier use Ed25519ph (RFC 8032, section 5.1) instead: the message is
+//  pre-hashed with SHA-512 as it is written, and the 64-byte digest is what
+//  gets signed, with the dom2 prefix telling the two schemes apart:
+//
+//    PH = sha512(m)
+//    r = sha512(dom2(1, ctx) || p || PH) % q
+//    h = sha512(dom2(1, ctx) || Rs || As || PH) % q
+//
+//  Ed25519ph signatures are NOT interchangeable with Ed25519 ones: they only
+//  verify with a Verifier, and Verify rejects them.
+//
+
+// ErrInvalidSignature is returned by verification functions which report
+// their result as an error, when the signature is not valid.
+var ErrInvalidSignature = errors.New("zed: invalid signature")
+
+// SignReader produces the same signature as Sign would on the full contents
+// of msg, from its current position to EOF, reading it twice. On success,
+// msg is left positioned at EOF.
+func (sk *Secret) SignReader(msg io.ReadSeeker) (Signature, error) {
+
+	// remember where the message starts, for the second pass
+	var start, err = msg.Seek(0, io.SeekCurrent)
+	if err != nil {
+		return Signature{}, err
+	}
+
+	// sha512 instance, result buffer
+	var hash = sha512.New()
+	var res Buffer512
+
+	// Take private scalar "a", prefix "p", and public point "A" from Secret
+	var a = sk.Scalar()
+	var p = sk.Prefix()
+	var A = sk.Public().Point()
+
+	// As = compress(A)
+	var As Buffer256
+	CompressPoint(&As, &A)
+
+	// r = sha512(p || m) % q
+	var r Scalar
+	hash.Reset()
+	hash.Write(p[:])
+	if _, err = io.Copy(hash, msg); err != nil {
+		return Signature{}, err
+	}
+	hash.Sum(res[:0])
+	ScalarReduce512(&r, &res)
+
+	// R = r * G
+	var R Point
+	ScalarMultBase(&R, &r)
+
+	// Rs = compress(R)
+	var Rs Buffer256
+	CompressPoint(&Rs, &R)
+
+	// rewind message for the second pass
+	if _, err = msg.Seek(start, io.SeekStart); err != nil {
+		return Signature{}, err
+	}
+
+	// h = sha512(Rs || As || m) % q
+	var h Scalar
+	hash.Reset()
+	hash.Write(Rs[:])
+	hash.Write(As[:])
+	if _, err = io.Copy(hash, msg); err != nil {
+		return Signature{}, err
+	}
+	hash.Sum(res[:0])
+	ScalarReduce512(&h, &res)
+
+	// s = (r + ha) % q
+	var s Scalar
+	ScalarMultScalarAddScalar(&s, &h, &a, &r)
+
+	// sig = Rs || s
+	var sig Signature
+	copy(sig[:], Rs[:])
+	copy(sig[32:], s[:])
+
+	return sig, nil
+}
+
+// VerifyReader checks whether sig is a valid signature by pk on the full
+// contents of msg, reading it once. It returns nil if the signature is valid,
+// ErrInvalidSignature if it is not, or the error which occurred while reading
+// msg.
+func (pk *Public) VerifyReader(msg io.Reader, sig []byte) error {
+	var ps, err = ParseSignature(sig)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	// sha512 instance, result buffer
+	var hash = sha512.New()
+	var res Buffer512
+
+	// Get As from public key object
+	var As = pk.Key()
+
+	// h = sha512(Rs || As || m) % q
+	var h Scalar
+	hash.Write(ps.rs[:])
+	hash.Write(As[:])
+	if _, err = io.Copy(hash, msg); err != nil {
+		return err
+	}
+	hash.Sum(res[:0])
+	ScalarReduce512(&h, &res)
+
+	if !pk.verifyChallenge(ps, &h) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
+
+// Signer is an io.Writer which produces an Ed25519ph signature on everything
+// written to it. It is created with Secret.NewSigner.
+type Signer struct {
+	sk   *Secret
+	ctx  []byte
+	hash hash.Hash
+}
+
+// NewSigner returns a Signer for an Ed25519ph signature by sk with an empty
+// context string.
+func (sk *Secret) NewSigner() *Signer {
+	return sk.NewSignerCtx(nil)
+}
+
+// NewSignerCtx returns a Signer for an Ed25519ph signature by sk bound to the
+// context string ctx, which must be at most 255 bytes long. It panics if ctx
+// is too long.
+func (sk *Secret) NewSignerCtx(ctx []byte) *Signer {
+
+	// if ctx length over 255, panic
+	if l := len(ctx); l > 255 {
+		panic("NewSignerCtx: bad context length: " + strconv.Itoa(l))
+	}
+
+	return &Signer{sk: sk, ctx: append([]byte{}, ctx...), hash: sha512.New()}
+}
+
+// Write adds more of the message to be signed. It never returns an error.
+func (s *Signer) Write(p []byte) (int, error) {
+	return s.hash.Write(p)
+}
+
+// Final returns the Ed25519ph signature on everything written so far. It does
+// not change the state of the Signer, so more can still be written and
+// signed afterwards.
+func (s *Signer) Final() Signature {
+
+	// PH = sha512(m)
+	var ph Buffer512
+	s.hash.Sum(ph[:0])
+
+	var sig, _ = s.sk.signDom(sha512.New, dom2(1, s.ctx), ph[:])
+	return sig
+}
+
+// Verifier is an io.Writer which checks an Ed25519ph signature on everything
+// written to it. It is created with Public.NewVerifier.
+type Verifier struct {
+	pk   *Public
+	ctx  []byte
+	hash hash.Hash
+}
+
+// NewVerifier returns a Verifier for Ed25519ph signatures by pk with an empty
+// context string.
+func (pk *Public) NewVerifier() *Verifier {
+	return pk.NewVerifierCtx(nil)
+}
+
+// NewVerifierCtx returns a Verifier for Ed25519ph signatures by pk bound to
+// the context string ctx. If ctx is longer than 255 bytes, no signature will
+// verify.
+func (pk *Public) NewVerifierCtx(ctx []byte) *Verifier {
+	return &Verifier{pk: pk, ctx: append([]byte{}, ctx...), hash: sha512.New()}
+}
+
+// Write adds more of the message to be verified. It never returns an error.
+func (v *Verifier) Write(p []byte) (int, error) {
+	return v.hash.Write(p)
+}
+
+// Final checks whether sig is a valid Ed25519ph signature on everything
+// written so far. It returns nil if the signature is valid, and
+// ErrInvalidSignature if it is not.
+func (v *Verifier) Final(sig []byte) error {
+
+	// if ctx length over 255, fail
+	if len(v.ctx) > 255 {
+		return ErrInvalidSignature
+	}
+
+	var ps, err = ParseSignature(sig)
+	if err != nil {
+		return ErrInvalidSignature
+	}
+
+	// sha512 instance, result buffer
+	var hash = sha512.New()
+	var res Buffer512
+
+	// PH = sha512(m)
+	var ph Buffer512
+	v.hash.Sum(ph[:0])
+
+	// Get As from public key object
+	var As = v.pk.Key()
+
+	// h = sha512(dom2(1, ctx) || Rs || As || PH) % q
+	var h Scalar
+	hash.Write(dom2(1, v.ctx))
+	hash.Write(ps.rs[:])
+	hash.Write(As[:])
+	hash.Write(ph[:])
+	hash.Sum(res[:0])
+	ScalarReduce512(&h, &res)
+
+	if !v.pk.verifyChallenge(ps, &h) {
+		return ErrInvalidSignature
+	}
+
+	return nil
+}
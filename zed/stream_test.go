@@ -0,0 +1,84 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+)
+
+func TestStreamLarge(t *testing.T) {
+	var sk = testSecret(7)
+	var pk = sk.Public()
+
+	// 32 MiB, much larger than any chunk io.Copy uses
+	var msg = bytes.Repeat([]byte("zed25519"), 4<<20)
+	var sig = sk.Sign(msg)
+
+	rsig, err := sk.SignReader(bytes.NewReader(msg))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rsig != sig {
+		t.Error("SignReader differs from Sign")
+	}
+
+	// VerifyReader only needs an io.Reader
+	if err := pk.VerifyReader(io.MultiReader(bytes.NewReader(msg[:1000]), bytes.NewReader(msg[1000:])), sig[:]); err != nil {
+		t.Errorf("VerifyReader: %v", err)
+	}
+	if err := pk.VerifyReader(bytes.NewReader(msg[1:]), sig[:]); err != ErrInvalidSignature {
+		t.Errorf("VerifyReader on a truncated message: got %v, want ErrInvalidSignature", err)
+	}
+	if err := pk.VerifyReader(bytes.NewReader(msg), sig[:63]); err != ErrInvalidSignature {
+		t.Errorf("VerifyReader with a short signature: got %v, want ErrInvalidSignature", err)
+	}
+
+	var readErr = errors.New("read failed")
+	var broken = io.MultiReader(bytes.NewReader(msg[:1000]), iotest.ErrReader(readErr))
+	if err := pk.VerifyReader(broken, sig[:]); err != readErr {
+		t.Errorf("VerifyReader with a failing reader: got %v, want %v", err, readErr)
+	}
+}
+
+func TestSignReaderPosition(t *testing.T) {
+	var sk = testSecret(7)
+	var msg = []byte("header|body")
+
+	// SignReader signs from the current position
+	var r = bytes.NewReader(msg)
+	r.Seek(7, io.SeekStart)
+	var sig, err = sk.SignReader(r)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sig != sk.Sign(msg[7:]) {
+		t.Error("SignReader did not sign from the current position")
+	}
+}
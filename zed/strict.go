@@ -0,0 +1,340 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+	"hash"
+)
+
+//
+//  Strict verification rejects some signatures which Verify accepts, which
+//  are valid by the Ed25519 equation but can only be produced by a dishonest
+//  signer, or allow a signature to be modified without invalidating it.
+//
+//  Ed25519 uses a curve whose order is 8 times the prime order q of the
+//  subgroup generated by the base point, so there are 8 points of small
+//  order (the points P for which 8 * P is the identity). A signature whose R
+//  or public key A is one of those points can be satisfied for many messages
+//  at once, or be re-used across keys, so VerifyStrict rejects them.
+//
+//  Verify, and the ZIP215 rules used by Zcash and other consensus systems,
+//  accept signatures whose R is a small-order point, so VerifyStrict may
+//  reject a signature that those accept. Applications must pick one rule and
+//  use it consistently.
+//
+//  For consensus systems, where every node must reach exactly the same
+//  verdict on every signature, VerifyWithOpts takes the rule as a VerifyOpts
+//  policy. With ZIP215 set, it implements the ZIP 215 rules exactly:
+//
+//    - s must be canonical (s < q),
+//    - A and R may be any encoding which decodes to a curve point, including
+//      non-canonical ones (y >= p, or x = 0 with the sign bit set),
+//    - h = sha512(Rs || As || m) % q over the encodings as given,
+//    - the cofactored equation 8 * (s * G - R - h * A) == identity must hold.
+//
+//  The cofactored equation accepts every signature the cofactorless one does,
+//  and also the ones a batch verifier accepts, so single and batch
+//  verification always agree. RejectNonCanonicalR, RejectNonCanonicalA and
+//  RejectSmallOrderA tighten either rule further.
+//
+//  Non-canonical encodings are 32-byte strings which decode to the same point
+//  as its canonical encoding, so they let one key or signature be written in
+//  several ways. DecompressPointStrict, PublicFromKeyStrict and
+//  VrfVerifyStrict reject them, for applications which map encodings to
+//  identities, such as key-to-address mappings.
+//
+//  REFERENCES:
+//    [1] Henry de Valence, "ZIP 215: Explicitly Defining and Modifying
+//        Ed25519 Validation Rules"
+//        https://zips.z.cash/zip-0215
+//
+
+// SmallOrderPoints holds the canonical encodings of the 8 points of small
+// order on the Ed25519 curve, as k * T for k = 0, ..., 7, where T is a point
+// of order 8. Index 0 is the identity.
+var SmallOrderPoints = [8]Buffer256{
+	{0x01},
+	{
+		0x26, 0xe8, 0x95, 0x8f, 0xc2, 0xb2, 0x27, 0xb0,
+		0x45, 0xc3, 0xf4, 0x89, 0xf2, 0xef, 0x98, 0xf0,
+		0xd5, 0xdf, 0xac, 0x05, 0xd3, 0xc6, 0x33, 0x39,
+		0xb1, 0x38, 0x02, 0x88, 0x6d, 0x53, 0xfc, 0x05,
+	},
+	{0x00},
+	{
+		0xc7, 0x17, 0x6a, 0x70, 0x3d, 0x4d, 0xd8, 0x4f,
+		0xba, 0x3c, 0x0b, 0x76, 0x0d, 0x10, 0x67, 0x0f,
+		0x2a, 0x20, 0x53, 0xfa, 0x2c, 0x39, 0xcc, 0xc6,
+		0x4e, 0xc7, 0xfd, 0x77, 0x92, 0xac, 0x03, 0x7a,
+	},
+	{
+		0xec, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f,
+	},
+	{
+		0xc7, 0x17, 0x6a, 0x70, 0x3d, 0x4d, 0xd8, 0x4f,
+		0xba, 0x3c, 0x0b, 0x76, 0x0d, 0x10, 0x67, 0x0f,
+		0x2a, 0x20, 0x53, 0xfa, 0x2c, 0x39, 0xcc, 0xc6,
+		0x4e, 0xc7, 0xfd, 0x77, 0x92, 0xac, 0x03, 0xfa,
+	},
+	{
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x80,
+	},
+	{
+		0x26, 0xe8, 0x95, 0x8f, 0xc2, 0xb2, 0x27, 0xb0,
+		0x45, 0xc3, 0xf4, 0x89, 0xf2, 0xef, 0x98, 0xf0,
+		0xd5, 0xdf, 0xac, 0x05, 0xd3, 0xc6, 0x33, 0x39,
+		0xb1, 0x38, 0x02, 0x88, 0x6d, 0x53, 0xfc, 0x85,
+	},
+}
+
+// pointIsSmallOrder checks whether p is one of the 8 small-order points, by
+// checking whether 8 * P is the identity. Unlike comparing against
+// SmallOrderPoints, this also catches points which were decoded from a
+// non-canonical encoding.
+func pointIsSmallOrder(p *Point) bool {
+	var cP, I Point
+	PointClearCofactor(&cP, p)
+	PointIdentity(&I)
+	return PointEqual(&cP, &I)
+}
+
+// IsSmallOrder reports whether p is one of the 8 points of small order, by
+// comparing its canonical encoding against SmallOrderPoints. The comparison
+// is constant-time.
+func (p *ExtendedGroupElement) IsSmallOrder() bool {
+	var Ps = PointToKey(p)
+	var found = 0
+	for i := range SmallOrderPoints {
+		found |= subtle.ConstantTimeCompare(Ps[:], SmallOrderPoints[i][:])
+	}
+	return found == 1
+}
+
+// IsTorsionFree reports whether p lies in the prime-order subgroup generated
+// by the base point, i.e. whether q * P is the identity. Every honestly
+// generated public key and signature R is torsion-free; a point which is
+// not is the sum of such a point and a small-order point.
+func (p *ExtendedGroupElement) IsTorsionFree() bool {
+	return pointTorsionFree(p)
+}
+
+// Errors returned by Public.Validate.
+var (
+	ErrSmallOrderPublic = errors.New("zed: public key has small order")
+	ErrTorsionPublic    = errors.New("zed: public key has a torsion component")
+)
+
+// Validate checks that the public key is usable, returning
+// ErrSmallOrderPublic if it is a point of small order (which signatures can
+// be forged for without any secret), or ErrTorsionPublic if it is not in the
+// prime-order subgroup (which no honestly generated key ever is).
+func (pk *Public) Validate() error {
+	if pk.point.IsSmallOrder() {
+		return ErrSmallOrderPublic
+	}
+	if !pk.point.IsTorsionFree() {
+		return ErrTorsionPublic
+	}
+	return nil
+}
+
+// VerifyStrict checks whether sig is a valid signature by pk on msg, like
+// Verify, but additionally rejects the signature if its R point, or the
+// public key itself, is a point of small order.
+func (pk *Public) VerifyStrict(msg, sig []byte) bool {
+	var ps, err = ParseSignature(sig)
+	if err != nil {
+		return false
+	}
+
+	// if A or R is small order, fail
+	if pointIsSmallOrder(&pk.point) || pointIsSmallOrder(&ps.r) {
+		return false
+	}
+
+	return pk.VerifyParsed(msg, ps)
+}
+
+// RejectsSmallOrderR reports whether VerifyStrict rejects signatures by this
+// key whose R is a small-order point, which it always does. It allows code
+// selecting a verification rule to check that policy explicitly, since Verify
+// (and ZIP215 verification) accept such signatures.
+func (pk *Public) RejectsSmallOrderR() bool {
+	return true
+}
+
+// VerifyOpts is a signature verification policy for VerifyWithOpts. The
+// zero value gives the same result as Verify.
+type VerifyOpts struct {
+	// ZIP215 selects the cofactored verification equation of ZIP 215,
+	// instead of the cofactorless one used by Verify.
+	ZIP215 bool
+
+	// RejectNonCanonicalR rejects signatures whose R is not the canonical
+	// encoding of its point.
+	RejectNonCanonicalR bool
+
+	// RejectNonCanonicalA rejects all signatures for a public key which is
+	// not given in the canonical encoding of its point. It only has an effect
+	// on the function VerifyWithOpts, since a Public is always encoded
+	// canonically.
+	RejectNonCanonicalA bool
+
+	// RejectSmallOrderA rejects all signatures for a public key of small
+	// order, which can be satisfied without knowing any secret.
+	RejectSmallOrderA bool
+}
+
+// VerifyWithOpts checks whether sig is a valid signature on msg for the
+// public key encoded as key, under the verification policy opts. The key is
+// taken as bytes, and hashed as given, so that the result is exactly
+// specified by the policy even for non-canonical key encodings. It returns
+// false if key is not 32 bytes, or does not decode to a curve point.
+func VerifyWithOpts(key, msg, sig []byte, opts VerifyOpts) bool {
+	if len(key) != 32 {
+		return false
+	}
+	var As Buffer256
+	copy(As[:], key)
+
+	// A = decompress(As), or fail, also if non-canonical and rejected
+	var pk = &Public{}
+	var decompress = DecompressPoint
+	if opts.RejectNonCanonicalA {
+		decompress = DecompressPointStrict
+	}
+	if !decompress(&pk.point, &As) {
+		return false
+	}
+
+	return pk.verifyOpts(sha512.New, &As, msg, sig, opts)
+}
+
+// VerifyWithOpts checks whether sig is a valid signature on msg for pk,
+// under the verification policy opts.
+func (pk *Public) VerifyWithOpts(msg, sig []byte, opts VerifyOpts) bool {
+	var As = pk.Key()
+	return pk.verifyOpts(sha512.New, &As, msg, sig, opts)
+}
+
+// verifyOpts implements VerifyWithOpts for a public key and its encoding As,
+// hashing the challenge with newHash.
+func (pk *Public) verifyOpts(newHash func() hash.Hash, As *Buffer256, msg, sig []byte, opts VerifyOpts) bool {
+	var ps, err = ParseSignature(sig)
+	if err != nil {
+		return false
+	}
+
+	// if R is not canonical, and that is rejected, fail
+	if opts.RejectNonCanonicalR && PointToKey(&ps.r) != ps.rs {
+		return false
+	}
+
+	// if A is small order, and that is rejected, fail
+	if opts.RejectSmallOrderA && pointIsSmallOrder(&pk.point) {
+		return false
+	}
+
+	// h = sha512(Rs || As || m) % q
+	var hash = newHash()
+	var res Buffer512
+	var h Scalar
+	hash.Write(ps.rs[:])
+	hash.Write(As[:])
+	hash.Write(msg)
+	hash.Sum(res[:0])
+	ScalarReduce512(&h, &res)
+
+	if !opts.ZIP215 {
+		return pk.verifyChallenge(ps, &h)
+	}
+
+	// sB = s * G, hA = h * A
+	var sB, hA Point
+	ScalarMultBase(&sB, &ps.s)
+	pk.scalarMult(&hA, &h)
+
+	// valid if: 8 * (sB - R - hA) == identity
+	var check Point
+	PointSub(&check, &sB, &ps.r)
+	PointSub(&check, &check, &hA)
+	return pointIsSmallOrder(&check)
+}
+
+// CanonicalizeSignature returns the canonical form of the 64-byte signature
+// sig: its R re-encoded canonically, and its s reduced modulo q. ok reports
+// whether sig was already in canonical form, in which case the result is
+// identical to sig. If sig cannot be decoded at all (wrong length, or R not
+// a point), the result is all zeroes and ok is false.
+//
+// A signature with s >= q is never valid, and re-encoding R changes the
+// challenge hash, so canonicalizing a signature never turns an invalid one
+// into a valid one. Systems which index signatures by their bytes should
+// reject any signature for which ok is false (or verify with VerifyCanonical),
+// so that each valid signature has exactly one accepted form.
+func CanonicalizeSignature(sig []byte) (canonical Signature, ok bool) {
+	if len(sig) != 64 {
+		return canonical, false
+	}
+
+	// R = decompress(sig[:32]), or fail
+	var Rs Buffer256
+	var R Point
+	copy(Rs[:], sig[:32])
+	if !DecompressPoint(&R, &Rs) {
+		return canonical, false
+	}
+
+	// s = sig[32:] % q
+	var sb Buffer512
+	var s Scalar
+	copy(sb[:32], sig[32:])
+	ScalarReduce512(&s, &sb)
+
+	// canonical = compress(R) || s
+	var Rc = PointToKey(&R)
+	copy(canonical[:32], Rc[:])
+	copy(canonical[32:], s[:])
+
+	return canonical, subtle.ConstantTimeCompare(canonical[:], sig) == 1
+}
+
+// VerifyCanonical checks whether sig is a valid signature by pk on msg, like
+// Verify, but additionally rejects it if it is not in canonical form (see
+// CanonicalizeSignature). Verify already rejects s >= q, so this only adds
+// the check that R is canonically encoded.
+func (pk *Public) VerifyCanonical(msg, sig []byte) bool {
+	return pk.VerifyWithOpts(msg, sig, VerifyOpts{RejectNonCanonicalR: true})
+}
@@ -0,0 +1,84 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha512"
+	"testing"
+)
+
+// smallOrderRSignature builds a signature by sk on msg whose R is the
+// small-order point Rs, with s = h * a, so that 8 * s * G = 8 * (R + h * A).
+func smallOrderRSignature(sk *Secret, msg []byte, Rs Buffer256) Signature {
+	var As = sk.Public().Key()
+
+	// h = sha512(Rs || As || m) % q, s = h * a
+	var hash = sha512.New()
+	var res Buffer512
+	hash.Write(Rs[:])
+	hash.Write(As[:])
+	hash.Write(msg)
+	hash.Sum(res[:0])
+	var h, s Scalar
+	ScalarReduce512(&h, &res)
+	ScalarMultScalar(&s, &h, &sk.scalar)
+
+	var sig Signature
+	copy(sig[:32], Rs[:])
+	copy(sig[32:], s[:])
+	return sig
+}
+
+func TestVerifyStrictSmallOrderR(t *testing.T) {
+	var sk = testSecret(13)
+	var pk = sk.Public()
+	var msg = []byte("small order R")
+	var zip215 = VerifyOpts{ZIP215: true}
+
+	if !pk.RejectsSmallOrderR() {
+		t.Error("RejectsSmallOrderR is false")
+	}
+
+	var honest = sk.Sign(msg)
+	if !pk.VerifyStrict(msg, honest[:]) {
+		t.Error("VerifyStrict rejected an honest signature")
+	}
+
+	for i := range SmallOrderPoints {
+		var sig = smallOrderRSignature(sk, msg, SmallOrderPoints[i])
+		if pk.VerifyStrict(msg, sig[:]) {
+			t.Errorf("SmallOrderPoints[%d]: VerifyStrict accepted a small-order R", i)
+		}
+		if !pk.VerifyWithOpts(msg, sig[:], zip215) {
+			t.Errorf("SmallOrderPoints[%d]: ZIP215 rejected a small-order R", i)
+		}
+
+		// the cofactorless equation only holds for R = I
+		if pk.Verify(msg, sig[:]) != (i == 0) {
+			t.Errorf("SmallOrderPoints[%d]: Verify = %v", i, i != 0)
+		}
+	}
+}
@@ -0,0 +1,292 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha512"
+	"errors"
+	"hash"
+	"io"
+	"strconv"
+
+	"golang.org/x/crypto/blake2b"
+	"golang.org/x/crypto/sha3"
+)
+
+//
+//  A Suite bundles the choices which the package functions otherwise make
+//  one way each, so that an application can fix them once, in one place,
+//  rather than picking among variants of each function at every call site:
+//
+//    - the hash function of signatures and VRF proofs (SuiteSHA512, the
+//      standard Ed25519 hash, by default),
+//    - the signature verification policy (VerifyOpts{}, the rule of Verify,
+//      by default),
+//    - a label which namespaces every derivation index, so that keys derived
+//      by different applications from the same parent never collide (none by
+//      default); keys are always derived with DerivationV3, so that sibling
+//      signers never share a nonce prefix,
+//    - deterministic nonces as in Sign (the default), or hedged nonces as in
+//      SignHedged.
+//
+//  A Suite is configured with functional options when it is created, and is
+//  immutable afterwards, so it can be shared freely:
+//
+//    suite, err := zed.NewSuite(
+//        zed.WithVerifyOpts(zed.VerifyOpts{ZIP215: true}),
+//        zed.WithDerivationLabel("wallet"),
+//        zed.WithHedgedNonces(rand.Reader),
+//    )
+//    signer := suite.Signer(sk)
+//    sig, err := signer.Sign(msg)
+//    ok := suite.Verifier(pk).Verify(msg, sig[:])
+//
+//  A Suite with no options behaves exactly as the package functions.
+//
+//  NON-STANDARD HASHES: SuiteSHA3_512 and SuiteBLAKE2b512 replace SHA-512 in
+//  every hash of the signature and VRF algorithms with SHA3-512 or
+//  BLAKE2b-512 respectively: the nonce r, the challenge h, and the VRF output
+//  y. Everything else, including the keys and the hash-to-point function of
+//  the VRF input, is unchanged. These variants are NOT Ed25519 as specified
+//  by RFC 8032: their signatures only verify under a suite with the same
+//  hash, never with Verify or any other Ed25519 implementation, and likewise
+//  for their VRF proofs. They exist for systems whose specification requires
+//  one hash function throughout.
+//
+
+// SuiteHash selects the hash function of the signatures and VRF proofs of a
+// Suite.
+type SuiteHash byte
+
+// Hash functions for a Suite. Only SuiteSHA512 is standard Ed25519.
+const (
+	SuiteSHA512     SuiteHash = 0
+	SuiteSHA3_512   SuiteHash = 1 // non-standard
+	SuiteBLAKE2b512 SuiteHash = 2 // non-standard
+)
+
+// newHash returns the constructor of the hash function h, or nil if h is
+// unknown.
+func (h SuiteHash) newHash() func() hash.Hash {
+	switch h {
+	case SuiteSHA512:
+		return sha512.New
+	case SuiteSHA3_512:
+		return sha3.New512
+	case SuiteBLAKE2b512:
+		return newBlake2b512
+	}
+	return nil
+}
+
+// newBlake2b512 returns an unkeyed BLAKE2b-512 instance.
+func newBlake2b512() hash.Hash {
+	var hash, _ = blake2b.New512(nil)
+	return hash
+}
+
+// Suite is a configuration of the signature, VRF and derivation algorithms,
+// created by NewSuite.
+type Suite struct {
+	hash   func() hash.Hash
+	verify VerifyOpts
+	label  []byte
+	rand   io.Reader
+}
+
+// SuiteOption configures a Suite in NewSuite.
+type SuiteOption func(*Suite) error
+
+// WithHash sets the hash function of signatures and VRF proofs.
+func WithHash(h SuiteHash) SuiteOption {
+	return func(s *Suite) error {
+		var newHash = h.newHash()
+		if newHash == nil {
+			return errors.New("WithHash: unknown hash: " + strconv.Itoa(int(h)))
+		}
+		s.hash = newHash
+		return nil
+	}
+}
+
+// WithVerifyOpts sets the signature verification policy (see VerifyOpts).
+// With RejectNonCanonicalR, VRF proofs must also encode their point V
+// canonically, as for VrfVerifyStrict.
+func WithVerifyOpts(opts VerifyOpts) SuiteOption {
+	return func(s *Suite) error {
+		s.verify = opts
+		return nil
+	}
+}
+
+// WithDerivationLabel sets a label which is combined with every derivation
+// index, as EncodeIndex(label, index), so that the same index gives a
+// different child key under each label. The label must not be empty.
+func WithDerivationLabel(label string) SuiteOption {
+	return func(s *Suite) error {
+		if label == "" {
+			return errors.New("WithDerivationLabel: empty label")
+		}
+		s.label = []byte(label)
+		return nil
+	}
+}
+
+// WithHedgedNonces makes signers draw fresh randomness from rand for every
+// signature, as SignHedged does, instead of deriving their nonces
+// deterministically as Sign does.
+func WithHedgedNonces(rand io.Reader) SuiteOption {
+	return func(s *Suite) error {
+		if rand == nil {
+			return errors.New("WithHedgedNonces: nil reader")
+		}
+		s.rand = rand
+		return nil
+	}
+}
+
+// NewSuite creates a Suite configured by the given options, applied in order.
+// It returns the error of the first option which fails.
+func NewSuite(opts ...SuiteOption) (*Suite, error) {
+	var s = &Suite{hash: sha512.New}
+	for _, opt := range opts {
+		if err := opt(s); err != nil {
+			return nil, err
+		}
+	}
+	return s, nil
+}
+
+// derivationIndex namespaces a derivation index with the suite's label.
+func (s *Suite) derivationIndex(index []byte) []byte {
+	if s.label == nil {
+		return index
+	}
+	return EncodeIndex(s.label, index)
+}
+
+// SuiteSigner signs and evaluates the VRF with a Secret Key, as configured by
+// its Suite.
+type SuiteSigner struct {
+	suite *Suite
+	sk    *Secret
+}
+
+// Signer returns a SuiteSigner for the Secret Key sk.
+func (s *Suite) Signer(sk *Secret) *SuiteSigner {
+	return &SuiteSigner{suite: s, sk: sk}
+}
+
+// Secret returns the Secret Key of the signer.
+func (ss *SuiteSigner) Secret() *Secret {
+	return ss.sk
+}
+
+// Verifier returns the SuiteVerifier for the Public Key of the signer.
+func (ss *SuiteSigner) Verifier() *SuiteVerifier {
+	return ss.suite.Verifier(ss.sk.Public())
+}
+
+// Sign signs msg, with a deterministic or hedged nonce as configured. An
+// error is only returned if the random source of hedged nonces fails. It
+// panics in the same cases as Sign.
+func (ss *SuiteSigner) Sign(msg []byte) (Signature, error) {
+	if ss.suite.rand != nil {
+		return ss.sk.signHedged(ss.suite.hash, msg, ss.suite.rand)
+	}
+	var sig, _ = ss.sk.signDom(ss.suite.hash, nil, msg)
+	return sig, nil
+}
+
+// VrfEval evaluates the VRF on the input x, as Secret.VrfEval does, with the
+// hash function of the suite.
+func (ss *SuiteSigner) VrfEval(x []byte) (VrfResult, VrfProof) {
+	return ss.sk.vrfEval(ss.suite.hash, x)
+}
+
+// Derive derives a child signer, as Secret.DeriveVersion does with
+// DerivationV3, with the index namespaced by the derivation label.
+func (ss *SuiteSigner) Derive(index, skey []byte) *SuiteSigner {
+	var child = &Secret{}
+	ss.sk.deriveInto(child, DerivationV3, ss.suite.derivationIndex(index), skey)
+	return ss.suite.Signer(child)
+}
+
+// SuiteVerifier verifies signatures and VRF proofs for a Public Key, as
+// configured by its Suite.
+type SuiteVerifier struct {
+	suite *Suite
+	pk    *Public
+}
+
+// Verifier returns a SuiteVerifier for the Public Key pk.
+func (s *Suite) Verifier(pk *Public) *SuiteVerifier {
+	return &SuiteVerifier{suite: s, pk: pk}
+}
+
+// Public returns the Public Key of the verifier.
+func (sv *SuiteVerifier) Public() *Public {
+	return sv.pk
+}
+
+// Verify checks whether sig is a valid signature on msg, under the
+// verification policy of the suite.
+func (sv *SuiteVerifier) Verify(msg, sig []byte) bool {
+	var As = sv.pk.Key()
+	return sv.pk.verifyOpts(sv.suite.hash, &As, msg, sig, sv.suite.verify)
+}
+
+// VrfVerify checks the VRF proof on the input x, as VrfVerifyErr does, with
+// the hash function of the suite, and returns its output. With
+// RejectNonCanonicalR, a proof whose V is not canonically encoded fails with
+// ErrVrfInvalidPoint.
+func (sv *SuiteVerifier) VrfVerify(x, proof []byte) (VrfResult, error) {
+	if len(proof) != len(VrfProof{}) {
+		return VrfResult{}, ErrVrfProofLength
+	}
+	if sv.suite.verify.RejectNonCanonicalR {
+		var Vs Buffer256
+		var V Point
+		copy(Vs[:], proof[:32])
+		if !DecompressPointStrict(&V, &Vs) {
+			return VrfResult{}, ErrVrfInvalidPoint
+		}
+	}
+
+	// Bv = hashToPoint(As || x)
+	var Bv = sv.pk.VrfInputPoint(x)
+
+	return sv.pk.vrfVerifyInput(sv.suite.hash, x, proof, &Bv)
+}
+
+// Derive derives the child verifier for a child signer derived with the same
+// index and no skey, as Public.DeriveVersion does with DerivationV3, with the
+// index namespaced by the derivation label.
+func (sv *SuiteVerifier) Derive(index []byte) *SuiteVerifier {
+	var child = &Public{}
+	sv.pk.deriveInto(child, DerivationV3, sv.suite.derivationIndex(index))
+	return sv.suite.Verifier(child)
+}
@@ -0,0 +1,65 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"bytes"
+	"testing"
+)
+
+func TestSuiteDerive(t *testing.T) {
+	var suite, err = NewSuite(WithDerivationLabel("wallet"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var sk = testSecret(16)
+	var signer = suite.Signer(sk)
+	var verifier = suite.Verifier(sk.Public())
+
+	// the child signer matches the child verifier, and the label matters
+	var msg = []byte("suite child")
+	var child = signer.Derive([]byte("0"), nil)
+	var sig, err1 = child.Sign(msg)
+	if !verifier.Derive([]byte("0")).Verify(msg, sig[:]) {
+		t.Error("child signature does not verify under the child verifier")
+	}
+	if sk.Public().VerifyChild([]byte("0"), msg, sig[:]) {
+		t.Error("child signature verifies without the derivation label")
+	}
+
+	// siblings do not share a nonce prefix
+	var sibling = signer.Derive([]byte("1"), nil)
+	if child.sk.prefix == sibling.sk.prefix {
+		t.Error("sibling signers share a prefix")
+	}
+	var sig2, err2 = sibling.Sign(msg)
+	if err1 != nil || err2 != nil {
+		t.Fatalf("Sign = %v, %v", err1, err2)
+	}
+	if bytes.Equal(sig[:32], sig2[:32]) {
+		t.Error("sibling signatures on the same message share R")
+	}
+}
@@ -0,0 +1,105 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha512"
+)
+
+//
+//  In threshold (FROST-style) and multi-party signing, each participant i
+//  holds a share a_i of the group's secret scalar, and contributes a nonce
+//  commitment R_i = r_i * G and a partial signature s_i. The coordinator
+//  sums them into an ordinary Ed25519 signature (R, s):
+//
+//    R = R_1 + ... + R_n
+//    c = sha512(Rs || As || m) % q
+//    s_i = r_i + c * l_i * a_i
+//    s = s_1 + ... + s_n
+//
+//  where A is the group public key, and l_i is participant i's Lagrange
+//  coefficient (1 for plain n-of-n signing). Before summing, the coordinator
+//  should check every partial signature on its own, with the equation
+//
+//    s_i * G == R_i + c * (l_i * A_i)
+//
+//  where A_i = a_i * G is the participant's public share. If the final
+//  signature fails to verify, this identifies which participant misbehaved,
+//  so they can be excluded from the next round.
+//
+
+// VerifyPartialSig checks a single participant's partial signature on msg,
+// using the partial-verification equation above. share is the participant's
+// weighted public share l_i * A_i (just A_i for n-of-n signing), and
+// commitment is the 96-byte round commitment:
+//
+//   commitment = Ri || R || A
+//
+// where Ri is the participant's compressed nonce commitment, R is the
+// compressed group commitment (the sum of all participants' Ri), and A is
+// the compressed group public key. It returns false for any malformed input.
+func VerifyPartialSig(share *Public, commitment, msg []byte, partial Scalar) bool {
+	if len(commitment) != 96 || !ValidScalar(&partial) {
+		return false
+	}
+
+	// (Ris || Rs || As) = commitment
+	var Ris, Rs, As Buffer256
+	copy(Ris[:], commitment[:32])
+	copy(Rs[:], commitment[32:64])
+	copy(As[:], commitment[64:])
+
+	// Ri = decompress(Ris), or fail
+	var Ri Point
+	if !DecompressPoint(&Ri, &Ris) {
+		return false
+	}
+
+	// c = sha512(Rs || As || m) % q
+	var hash = sha512.New()
+	var res Buffer512
+	var c Scalar
+	hash.Write(Rs[:])
+	hash.Write(As[:])
+	hash.Write(msg)
+	hash.Sum(res[:0])
+	ScalarReduce512(&c, &res)
+
+	// siG = s_i * G
+	var siG Point
+	ScalarMultBase(&siG, &partial)
+
+	// cAi = c * (l_i * A_i)
+	var cAi Point
+	ScalarMultPointVartime(&cAi, &c, &share.point)
+
+	// RicAi = Ri + cAi
+	var RicAi Point
+	PointAdd(&RicAi, &Ri, &cAi)
+
+	// valid if: s_i * G == Ri + c * (l_i * A_i)
+	return PointEqual(&siG, &RicAi)
+}
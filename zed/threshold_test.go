@@ -0,0 +1,101 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha512"
+	"testing"
+)
+
+func TestVerifyPartialSig(t *testing.T) {
+	var msg = []byte("3-of-3")
+
+	// shares a_i, nonces r_i, and their public points
+	var as = []Scalar{{11}, {22}, {33}}
+	var rs = []Scalar{{44}, {55}, {66}}
+	var shares = make([]*Public, len(as))
+	var Ri = make([]Point, len(rs))
+	var A, R Point
+	PointIdentity(&A)
+	PointIdentity(&R)
+	for i := range as {
+		shares[i] = &Public{}
+		ScalarMultBase(&shares[i].point, &as[i])
+		ScalarMultBase(&Ri[i], &rs[i])
+		PointAdd(&A, &A, &shares[i].point)
+		PointAdd(&R, &R, &Ri[i])
+	}
+	var Rs, As = PointToKey(&R), PointToKey(&A)
+
+	// c = sha512(Rs || As || m) % q
+	var hash = sha512.New()
+	var res Buffer512
+	var c Scalar
+	hash.Write(Rs[:])
+	hash.Write(As[:])
+	hash.Write(msg)
+	hash.Sum(res[:0])
+	ScalarReduce512(&c, &res)
+
+	// s_i = r_i + c * a_i, s = s_1 + ... + s_n
+	var partials = make([]Scalar, len(as))
+	var commitments = make([][]byte, len(as))
+	var s Scalar
+	for i := range as {
+		ScalarMultScalarAddScalar(&partials[i], &c, &as[i], &rs[i])
+		ScalarMultScalarAddScalar(&s, &scalarOne, &s, &partials[i])
+
+		var Ris = PointToKey(&Ri[i])
+		commitments[i] = append(append(Ris[:], Rs[:]...), As[:]...)
+		if !VerifyPartialSig(shares[i], commitments[i], msg, partials[i]) {
+			t.Errorf("valid partial %d rejected", i)
+		}
+	}
+
+	// the partials sum to an ordinary signature
+	var sig Signature
+	copy(sig[:32], Rs[:])
+	copy(sig[32:], s[:])
+	if !(&Public{point: A}).Verify(msg, sig[:]) {
+		t.Error("sum of partials does not verify")
+	}
+
+	// a corrupted partial is pinpointed
+	var bad = partials[1]
+	bad[0] ^= 1
+	if VerifyPartialSig(shares[1], commitments[1], msg, bad) {
+		t.Error("corrupted partial accepted")
+	}
+	if VerifyPartialSig(shares[0], commitments[1], msg, partials[1]) {
+		t.Error("partial accepted for another participant's share")
+	}
+	if VerifyPartialSig(shares[1], commitments[1], []byte("other"), partials[1]) {
+		t.Error("partial accepted for another message")
+	}
+	if VerifyPartialSig(shares[1], commitments[1][:95], msg, partials[1]) {
+		t.Error("partial accepted with a short commitment")
+	}
+}
@@ -0,0 +1,83 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"encoding/binary"
+	"sort"
+)
+
+//
+//  TLV signing allows a set of tagged fields (for example the fields of a
+//  protocol buffer message) to be signed without depending on the order in
+//  which they were stored. Fields are serialized in ascending order of their
+//  tags, each as a type-length-value record:
+//
+//    tag || len(value) || value
+//
+//  where tag is a 2-byte, and len(value) a 4-byte, big-endian integer. Since
+//  every value is length-prefixed, no two different sets of fields produce
+//  the same bytes. The signature is an ordinary Ed25519 signature on those
+//  bytes.
+//
+
+// EncodeTLV returns the canonical TLV encoding of fields, which is the message
+// actually signed by SignTLV.
+func EncodeTLV(fields map[uint16][]byte) []byte {
+
+	// sort tags, and measure the encoding
+	var tags = make([]int, 0, len(fields))
+	var n = 0
+	for tag, value := range fields {
+		tags = append(tags, int(tag))
+		n += 6 + len(value)
+	}
+	sort.Ints(tags)
+
+	// tag || len(value) || value, for each field
+	var buf = make([]byte, 0, n)
+	var hdr [6]byte
+	for _, tag := range tags {
+		var value = fields[uint16(tag)]
+		binary.BigEndian.PutUint16(hdr[:2], uint16(tag))
+		binary.BigEndian.PutUint32(hdr[2:], uint32(len(value)))
+		buf = append(buf, hdr[:]...)
+		buf = append(buf, value...)
+	}
+
+	return buf
+}
+
+// SignTLV signs the canonical TLV encoding of fields with the Secret Key sk.
+func (sk *Secret) SignTLV(fields map[uint16][]byte) Signature {
+	return sk.Sign(EncodeTLV(fields))
+}
+
+// VerifyTLV checks whether sig is a valid signature by pk on the canonical
+// TLV encoding of fields.
+func (pk *Public) VerifyTLV(fields map[uint16][]byte, sig Signature) bool {
+	return pk.Verify(EncodeTLV(fields), sig[:])
+}
@@ -0,0 +1,79 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"bytes"
+	"encoding/hex"
+	"testing"
+)
+
+func TestEncodeTLV(t *testing.T) {
+	var got = EncodeTLV(map[uint16][]byte{0x0102: []byte("b"), 1: []byte("a"), 7: nil})
+	var want, _ = hex.DecodeString("000100000001" + "61" + "000700000000" + "010200000001" + "62")
+	if !bytes.Equal(got, want) {
+		t.Errorf("EncodeTLV = %x, want %x", got, want)
+	}
+	if len(EncodeTLV(nil)) != 0 {
+		t.Error("EncodeTLV(nil) is not empty")
+	}
+}
+
+func TestSignTLV(t *testing.T) {
+	var sk = testSecret(9)
+	var pk = sk.Public()
+
+	// the same fields, inserted in opposite orders
+	var up = map[uint16][]byte{}
+	var down = map[uint16][]byte{}
+	for i := 0; i < 32; i++ {
+		up[uint16(i)] = []byte{byte(i)}
+		down[uint16(31-i)] = []byte{byte(31 - i)}
+	}
+
+	var sig = sk.SignTLV(up)
+	if sk.SignTLV(down) != sig {
+		t.Error("the same fields signed differently")
+	}
+	if !pk.VerifyTLV(down, sig) {
+		t.Error("VerifyTLV rejected a valid signature")
+	}
+
+	// adding, removing, or changing a field invalidates the signature
+	down[32] = nil
+	if pk.VerifyTLV(down, sig) {
+		t.Error("VerifyTLV accepted an added field")
+	}
+	delete(down, 32)
+	delete(down, 0)
+	if pk.VerifyTLV(down, sig) {
+		t.Error("VerifyTLV accepted a removed field")
+	}
+	down[0] = []byte{1}
+	if pk.VerifyTLV(down, sig) {
+		t.Error("VerifyTLV accepted a changed field")
+	}
+}
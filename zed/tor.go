@@ -0,0 +1,221 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha512"
+	"encoding/base32"
+	"encoding/binary"
+	"errors"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/sha3"
+)
+
+//
+//  Tor v3 onion services do not sign their descriptors with their long-term
+//  identity key, but with a "blinded" key which changes every time period,
+//  so that the descriptors of one service cannot be linked across periods by
+//  anyone who does not already know its onion address. The blinding is a
+//  multiplicative derivation like Derive, but with Tor's own parameters, so
+//  that the keys are bit-identical to those Tor computes:
+//
+//    h  = sha3_256(BLIND_STRING || A || s || B || N), then clamped
+//    BLIND_STRING = "Derive temporary signing key" || 0x00
+//    N  = "key-blind" || INT_8(period_number) || INT_8(period_length)
+//    B  = the base point, as the decimal string "(x, y)"
+//
+//    a'  = h * a % q
+//    RH' = sha512("Derive temporary signing key hash input" || RH)[:32]
+//    A'  = h * A
+//
+//  where (a, RH) is the expanded secret key (the private scalar and prefix),
+//  s is an optional shared secret (empty for ordinary services), and INT_8 is
+//  an 8-byte big-endian integer. Periods are numbered as by TorTimePeriod.
+//  The Key of a blinded Secret is the 64-byte expanded secret key (a' || RH')
+//  that Tor uses to sign with the blinded key.
+//
+//  The onion address of a service is its identity key A, with a checksum and
+//  a version byte:
+//
+//    onion_address = base32(A || CHECKSUM || VERSION) || ".onion"
+//    CHECKSUM = sha3_256(".onion checksum" || A || VERSION)[:2]
+//    VERSION = 0x03
+//
+//  REFERENCES:
+//    [1] The Tor Project, "Tor Rendezvous Specification - Version 3",
+//        Appendix A.2, "Tor's key derivation scheme"
+//        https://spec.torproject.org/rend-spec/keyblinding-scheme.html
+//        https://spec.torproject.org/rend-spec/encoding-onion-addresses.html
+//
+
+// TorPeriodLength is the default length of a Tor time period, in minutes.
+const TorPeriodLength = 1440
+
+// torRotationOffset is the offset of Tor time periods from the epoch, in
+// minutes.
+const torRotationOffset = 12 * 60
+
+// torBasePoint is the decimal string form of the base point B, as hashed into
+// the blinding factor.
+const torBasePoint = "(15112221349535400772501151409588531511454012693041857206046113283949847762202, " +
+	"46316835694926478169428394003475163141307993866256225615783033603165251855960)"
+
+// torOnionVersion is the version byte of v3 onion addresses.
+const torOnionVersion = 3
+
+// torOnionEncoding is the base32 alphabet of onion addresses, in lower case.
+var torOnionEncoding = base32.NewEncoding("abcdefghijklmnopqrstuvwxyz234567").WithPadding(base32.NoPadding)
+
+// TorOnionAddress returns the v3 onion address of the onion service whose
+// identity key is pk, in lower case with the ".onion" suffix.
+func (pk *Public) TorOnionAddress() string {
+	var As = pk.Key()
+	var checksum = torOnionChecksum(&As)
+
+	// base32(A || CHECKSUM || VERSION) || ".onion"
+	var buf = append(As[:], checksum[0], checksum[1], torOnionVersion)
+	return torOnionEncoding.EncodeToString(buf) + ".onion"
+}
+
+// ParseTorOnionAddress decodes a v3 onion address, with or without its
+// ".onion" suffix, and returns the identity key of the service. It returns
+// an error if the address is malformed, has a bad checksum or version, or if
+// the key is not a canonical point of the prime-order subgroup, as Tor does.
+func ParseTorOnionAddress(addr string) (*Public, error) {
+	addr = strings.TrimSuffix(strings.ToLower(addr), ".onion")
+	var buf, err = torOnionEncoding.DecodeString(addr)
+	if err != nil || len(buf) != 32+2+1 {
+		return nil, errors.New("ParseTorOnionAddress: malformed address")
+	}
+	if buf[34] != torOnionVersion {
+		return nil, errors.New("ParseTorOnionAddress: unsupported version")
+	}
+
+	// if CHECKSUM != sha3_256(".onion checksum" || A || VERSION)[:2], fail
+	var As Buffer256
+	copy(As[:], buf)
+	var checksum = torOnionChecksum(&As)
+	if buf[32] != checksum[0] || buf[33] != checksum[1] {
+		return nil, errors.New("ParseTorOnionAddress: bad checksum")
+	}
+
+	pk, err := PublicFromKeyStrict(As[:])
+	if err != nil || pk.HasTorsionComponent() {
+		return nil, errors.New("ParseTorOnionAddress: invalid key")
+	}
+	return pk, nil
+}
+
+// torOnionChecksum computes sha3_256(".onion checksum" || A || VERSION)[:2].
+func torOnionChecksum(As *Buffer256) [2]byte {
+	var hash = sha3.New256()
+	var res Buffer256
+	hash.Write([]byte(".onion checksum"))
+	hash.Write(As[:])
+	hash.Write([]byte{torOnionVersion})
+	hash.Sum(res[:0])
+	return [2]byte{res[0], res[1]}
+}
+
+// TorTimePeriod returns the number of the Tor time period of periodLength
+// minutes (normally TorPeriodLength) which contains t:
+//
+//	period_number = (minutes since epoch - 12 * 60) / period_length
+//
+// It panics if periodLength is zero.
+func TorTimePeriod(t time.Time, periodLength uint64) uint64 {
+	if periodLength == 0 {
+		panic("TorTimePeriod: zero period length")
+	}
+	var minutes = uint64(t.Unix()) / 60
+	return (minutes - torRotationOffset) / periodLength
+}
+
+// TorBlind returns the blinded public key of pk for the Tor time period
+// periodNumber of periodLength minutes, and the optional secret s, as
+// described above.
+func (pk *Public) TorBlind(periodNumber, periodLength uint64, s []byte) *Public {
+	var As = pk.Key()
+	var h = torBlindingFactor(&As, periodNumber, periodLength, s)
+
+	// A' = h * A
+	var npk = &Public{}
+	ScalarMultPointVartime(&npk.point, &h, &pk.point)
+
+	return npk
+}
+
+// TorBlind returns the blinded secret key of sk for the Tor time period
+// periodNumber of periodLength minutes, and the optional secret s, as
+// described above. Its public key is pk.TorBlind with the same arguments,
+// and its Key is the expanded secret key Tor signs with. Like a derived key,
+// it has no seed.
+func (sk *Secret) TorBlind(periodNumber, periodLength uint64, s []byte) *Secret {
+	var As = sk.Public().Key()
+	var h = torBlindingFactor(&As, periodNumber, periodLength, s)
+
+	// a' = h * a % q
+	var nsk = &Secret{}
+	ScalarMultScalar(&nsk.scalar, &h, &sk.scalar)
+	wipe(h[:])
+
+	// RH' = sha512(hash_input_str || RH)[:32]
+	var hash = sha512.New()
+	var res Buffer512
+	hash.Write([]byte("Derive temporary signing key hash input"))
+	hash.Write(sk.prefix[:])
+	hash.Sum(res[:0])
+	copy(nsk.prefix[:], res[:32])
+	wipe(res[:])
+
+	return nsk
+}
+
+// torBlindingFactor computes the clamped blinding factor
+// h = sha3_256(BLIND_STRING || A || s || B || N).
+func torBlindingFactor(As *Buffer256, periodNumber, periodLength uint64, s []byte) Scalar {
+
+	// N = "key-blind" || INT_8(period_number) || INT_8(period_length)
+	var N [9 + 8 + 8]byte
+	copy(N[:], "key-blind")
+	binary.BigEndian.PutUint64(N[9:], periodNumber)
+	binary.BigEndian.PutUint64(N[17:], periodLength)
+
+	var hash = sha3.New256()
+	hash.Write([]byte("Derive temporary signing key\x00"))
+	hash.Write(As[:])
+	hash.Write(s)
+	hash.Write([]byte(torBasePoint))
+	hash.Write(N[:])
+
+	var h Scalar
+	hash.Sum(h[:0])
+	ClampScalar(&h)
+
+	return h
+}
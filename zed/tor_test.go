@@ -0,0 +1,183 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"encoding/hex"
+	"strings"
+	"testing"
+	"time"
+)
+
+// torOnionTests are the example addresses of rend-spec-v3, and the address
+// Tor's test_build_address expects for the key of RFC 8032 test 1.
+var torOnionTests = []struct{ addr, key string }{
+	{"pg6mmjiyjmcrsslvykfwnntlaru7p5svn6y2ymmju6nubxndf4pscryd.onion", ""},
+	{"sp3k262uwy4r2k3ycr5awluarykdpag6a7y33jxop4cs2lu5uz5sseqd.onion", ""},
+	{"xa4r2iadxm55fbnqgwwi5mymqdcofiu3w6rpbtqn7b2dyn7mgwj64jyd.onion", ""},
+	{"25njqamcweflpvkl73j4szahhihoc4xt3ktcgjnpaingr5yhkenl5sid.onion",
+		"d75a980182b10ab7d54bfed3c964073a0ee172f3daa62325af021a68f707511a"},
+}
+
+func TestTorOnionAddress(t *testing.T) {
+	for _, test := range torOnionTests {
+		var pk, err = ParseTorOnionAddress(test.addr)
+		if err != nil {
+			t.Fatalf("ParseTorOnionAddress(%s) = %v", test.addr, err)
+		}
+		if got := pk.TorOnionAddress(); got != test.addr {
+			t.Errorf("TorOnionAddress = %s, want %s", got, test.addr)
+		}
+		if As := pk.Key(); test.key != "" && hex.EncodeToString(As[:]) != test.key {
+			t.Errorf("ParseTorOnionAddress(%s) = %x, want %s", test.addr, As, test.key)
+		}
+
+		// the suffix is optional, and case does not matter
+		for _, addr := range []string{strings.TrimSuffix(test.addr, ".onion"), strings.ToUpper(test.addr)} {
+			if got, err := ParseTorOnionAddress(addr); err != nil || got.Key() != pk.Key() {
+				t.Errorf("ParseTorOnionAddress(%s) = %v", addr, err)
+			}
+		}
+	}
+}
+
+var parseTorOnionErrorTests = []string{
+
+	// a changed character, caught by the checksum
+	"25njqamcweflpvkl73j4szahhihoc4xt3ktcgjnpaingr5yhkenl5sia.onion",
+
+	// too short, too long, not base32
+	"25njqamcweflpvkl73j4szahhihoc4xt3ktcgjnpaingr5yhkenl5si.onion",
+	"25njqamcweflpvkl73j4szahhihoc4xt3ktcgjnpaingr5yhkenl5sidaa.onion",
+	"25njqamcweflpvkl73j4szahhihoc4xt3ktcgjnpaingr5yhkenl5si1.onion",
+
+	// version 2, with a valid checksum for it
+	"25njqamcweflpvkl73j4szahhihoc4xt3ktcgjnpaingr5yhkendphqc.onion",
+
+	// A + T for a point T of order 8, with a valid checksum
+	"sfmdcku2rvxdwngishlnmfce7c4cchcrc7v22fn5wc6wrmd6ajct6xqd.onion",
+}
+
+func TestParseTorOnionAddressErrors(t *testing.T) {
+	for _, addr := range parseTorOnionErrorTests {
+		if _, err := ParseTorOnionAddress(addr); err == nil {
+			t.Errorf("ParseTorOnionAddress accepted %s", addr)
+		}
+	}
+}
+
+// torBlindTests were computed with an independent implementation of
+// rend-spec-v3 appendix A.2, for the key of RFC 8032 test 1 and the key of
+// testSecret(2). Each gives the blinded public key, and the blinded expanded
+// secret key a' || RH'.
+var torBlindTests = []struct {
+	seed                 string
+	period, length       uint64
+	s                    string
+	blindedPub, blindKey string
+}{
+	{
+		"9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60", 16903, 1440, "",
+		"7948ed13529f27e000d091082cec9ec457759cf1ca016be18521f7db5cd24682",
+		"67beecf34b16b5fff2318fffab53010d065a68f64c127f4c778b11660122210c" +
+			"58527b14f4d1dfd8b34a003f90307d7eb6f786a66275c9be0a2af4742b7af1cf",
+	},
+	{
+		"9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60", 0, 1440, "",
+		"db97ff7fcba8780607683073ad3e6b55ae8b469ceb0c3b39e7bc32b8ac085857",
+		"19c2ad85cd9c7507fa60819cc6cb6708472da9483909443b0b63f2a277dc7308" +
+			"58527b14f4d1dfd8b34a003f90307d7eb6f786a66275c9be0a2af4742b7af1cf",
+	},
+	{
+		"9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60", 16903, 1440, "shared secret",
+		"3a0dd7460cdc2ea3c325f84f4e1617651ac262ad9ad9c1194172fd1914442a23",
+		"7d59da6ab08f8e8541178f162b4c66a19892046ba6f07da58eb9778336887909" +
+			"58527b14f4d1dfd8b34a003f90307d7eb6f786a66275c9be0a2af4742b7af1cf",
+	},
+	{
+		"9d61b19deffd5a60ba844af492ec2cc44449c5697b326919703bac031cae7f60", 12345, 60, "",
+		"d455e30ac8e4d28d573c0c9840a7ba8485ee05300a0a8a8d52c636bb5fd2280d",
+		"92e211a69f5bddf8d891853f00a40422141616ff0bf7c7affefa3eb6db8d650d" +
+			"58527b14f4d1dfd8b34a003f90307d7eb6f786a66275c9be0a2af4742b7af1cf",
+	},
+	{
+		"0202020202020202020202020202020202020202020202020202020202020202", 16903, 1440, "",
+		"5afe5533309c15d196ba165909f501dd7b006adc212f77091d48dfe9aeb0ce14",
+		"c6b3f003b3f0f2d682ceda070a49ef7d566c4ccfc076ed8cb283089963d80508" +
+			"64964aa1cf44c04c97050ea2f67901af73e7c10f65562502cf393a8bf6cb654f",
+	},
+	{
+		"0202020202020202020202020202020202020202020202020202020202020202", 16903, 1440, "shared secret",
+		"29d2f39ddfbc53398d919a877206c0dbe56f565fd81c228b8dcf70502144d931",
+		"e801f6b30105ecb13e3b12f4328d45dd386ef0e048ac233f728b3a1710a04803" +
+			"64964aa1cf44c04c97050ea2f67901af73e7c10f65562502cf393a8bf6cb654f",
+	},
+}
+
+func TestTorBlind(t *testing.T) {
+	for _, test := range torBlindTests {
+		var seed, _ = hex.DecodeString(test.seed)
+		var sk = SecretFromSeed(seed)
+		var bsk = sk.TorBlind(test.period, test.length, []byte(test.s))
+		var bpk = sk.Public().TorBlind(test.period, test.length, []byte(test.s))
+
+		if As := bpk.Key(); hex.EncodeToString(As[:]) != test.blindedPub {
+			t.Errorf("%.8s/%d: Public.TorBlind = %x, want %s", test.seed, test.period, As, test.blindedPub)
+		}
+		if key := bsk.Key(); hex.EncodeToString(key[:]) != test.blindKey {
+			t.Errorf("%.8s/%d: Secret.TorBlind = %x, want %s", test.seed, test.period, key, test.blindKey)
+		}
+		if bsk.Public().Key() != bpk.Key() {
+			t.Errorf("%.8s/%d: blinded keys do not match", test.seed, test.period)
+		}
+
+		// the blinded key signs like any other, and has no seed
+		var sig = bsk.Sign([]byte("descriptor"))
+		if !bpk.Verify([]byte("descriptor"), sig[:]) {
+			t.Errorf("%.8s/%d: blinded signature does not verify", test.seed, test.period)
+		}
+		if _, ok := bsk.Seed(); ok {
+			t.Errorf("%.8s/%d: blinded key has a seed", test.seed, test.period)
+		}
+	}
+}
+
+func TestTorTimePeriod(t *testing.T) {
+
+	// the example of rend-spec-v3: 2016-04-13 11:15:01 UTC is in period 16903
+	var now = time.Date(2016, 4, 13, 11, 15, 1, 0, time.UTC)
+	if got := TorTimePeriod(now, TorPeriodLength); got != 16903 {
+		t.Errorf("TorTimePeriod = %d, want 16903", got)
+	}
+
+	// periods start at 12:00 UTC
+	var start = time.Date(2016, 4, 13, 12, 0, 0, 0, time.UTC)
+	if TorTimePeriod(start, TorPeriodLength) != 16904 || TorTimePeriod(start.Add(-time.Second), TorPeriodLength) != 16903 {
+		t.Error("period 16904 does not start at 12:00 UTC")
+	}
+
+	expectPanic(t, "TorTimePeriod", func() { TorTimePeriod(now, 0) })
+}
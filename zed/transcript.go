@@ -0,0 +1,100 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha512"
+	"encoding/binary"
+	"hash"
+)
+
+//
+//  A Transcript accumulates the labeled messages of an interactive protocol,
+//  so that a party can sign everything which has been said so far, in the
+//  order it was said, in a single signature. This is similar in spirit to
+//  the Merlin transcripts used by several Rust cryptography libraries, but is
+//  built on SHA-512 and ordinary Ed25519 signatures.
+//
+//  Every Append writes the label and the data with explicit length prefixes:
+//
+//    len(label) || label || len(data) || data
+//
+//  where len(label) is a 4-byte, and len(data) an 8-byte, big-endian integer.
+//  This makes the encoding unambiguous, so data cannot be moved between
+//  labels, messages cannot be reordered, and no extra message can be injected
+//  without changing the transcript hash, and therefore the signature.
+//
+
+// Transcript is a running, labeled hash of protocol messages which can be
+// signed and verified at any point. Use NewTranscript to create one.
+type Transcript struct {
+	hash hash.Hash
+}
+
+// NewTranscript starts a new transcript for the named protocol. Transcripts
+// of different protocols never hash to the same value, even if the same
+// messages are appended to them.
+func NewTranscript(protocol string) *Transcript {
+	var t = &Transcript{hash: sha512.New()}
+	t.Append("zed25519_transcript", []byte(protocol))
+	return t
+}
+
+// Append adds a labeled message to the transcript.
+func (t *Transcript) Append(label string, data []byte) {
+	var l [8]byte
+
+	// len(label) || label
+	binary.BigEndian.PutUint32(l[:4], uint32(len(label)))
+	t.hash.Write(l[:4])
+	t.hash.Write([]byte(label))
+
+	// len(data) || data
+	binary.BigEndian.PutUint64(l[:], uint64(len(data)))
+	t.hash.Write(l[:])
+	t.hash.Write(data)
+}
+
+// Sum gets the hash of all messages appended to the transcript so far. It
+// does not change the transcript, so more messages may still be appended.
+func (t *Transcript) Sum() Buffer512 {
+	var sum Buffer512
+	t.hash.Sum(sum[:0])
+	return sum
+}
+
+// Sign signs the current state of the transcript with the Secret Key sk.
+func (t *Transcript) Sign(sk *Secret) Signature {
+	var sum = t.Sum()
+	return sk.Sign(sum[:])
+}
+
+// Verify checks whether sig is a valid signature by pk on the current state
+// of the transcript.
+func (t *Transcript) Verify(pk *Public, sig Signature) bool {
+	var sum = t.Sum()
+	return pk.Verify(sum[:], sig[:])
+}
@@ -0,0 +1,100 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"testing"
+)
+
+// transcriptEntry is one labeled message of a test transcript.
+type transcriptEntry struct {
+	label string
+	data  string
+}
+
+// testTranscript builds a transcript of the "test" protocol from entries.
+func testTranscript(entries ...transcriptEntry) *Transcript {
+	var t = NewTranscript("test")
+	for _, e := range entries {
+		t.Append(e.label, []byte(e.data))
+	}
+	return t
+}
+
+// transcriptTamperTests are transcripts which differ from
+// {{"x", "1"}, {"y", "2"}} by order, labels, data, protocol or an extra
+// message.
+var transcriptTamperTests = [][]transcriptEntry{
+	{{"y", "2"}, {"x", "1"}},
+	{{"x", "1"}, {"z", "2"}},
+	{{"x", "1"}, {"y", "3"}},
+	{{"x", "12"}, {"y", ""}},
+	{{"x", "1y"}, {"", "2"}},
+	{{"x", "1"}},
+	{{"x", "1"}, {"y", "2"}, {"z", ""}},
+}
+
+func TestTranscript(t *testing.T) {
+	var sk = testSecret(6)
+	var pk = sk.Public()
+
+	var tr = testTranscript(transcriptEntry{"x", "1"}, transcriptEntry{"y", "2"})
+	var sig = tr.Sign(sk)
+	if !tr.Verify(pk, sig) {
+		t.Fatal("Verify rejected a valid transcript signature")
+	}
+	if !testTranscript(transcriptEntry{"x", "1"}, transcriptEntry{"y", "2"}).Verify(pk, sig) {
+		t.Error("Verify rejected an identical transcript")
+	}
+
+	for i, entries := range transcriptTamperTests {
+		var other = testTranscript(entries...)
+		if other.Verify(pk, sig) {
+			t.Errorf("transcriptTamperTests[%d] verifies", i)
+		}
+		if other.Sign(sk) == sig {
+			t.Errorf("transcriptTamperTests[%d] signs the same", i)
+		}
+	}
+
+	// other protocols never match
+	var proto = NewTranscript("other")
+	proto.Append("x", []byte("1"))
+	proto.Append("y", []byte("2"))
+	if proto.Verify(pk, sig) {
+		t.Error("a transcript of another protocol verifies")
+	}
+
+	// Sum does not change the transcript, but Append does
+	tr.Sum()
+	if !tr.Verify(pk, sig) {
+		t.Error("Sum changed the transcript")
+	}
+	tr.Append("z", nil)
+	if tr.Verify(pk, sig) {
+		t.Error("Verify accepted a signature after another Append")
+	}
+}
@@ -0,0 +1,100 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha512"
+)
+
+//
+//  Tweaking adds a commitment to a keypair, in the style of Bitcoin's
+//  Taproot: the tweaked public key A' = A + t * G, where the tweak t is a hash
+//  of the original public key A and an arbitrary commitment string. The owner
+//  of the secret scalar a can sign for A' with a' = a + t, while anyone who
+//  knows A and the commitment can recompute A', and check that it commits to
+//  exactly that string.
+//
+//  Unlike Derive, which multiplies the key by a blind, tweaking adds to it,
+//  so the tweak scalar itself can be published without revealing anything
+//  about the secret key.
+//
+
+// tweakScalar computes t = sha512(tweak_str || As || commitment) % q.
+func tweakScalar(As *Buffer256, commitment []byte) Scalar {
+	var hash = sha512.New()
+	var res Buffer512
+	hash.Write([]byte("zed25519_tweak"))
+	hash.Write(As[:])
+	hash.Write(commitment)
+	hash.Sum(res[:0])
+
+	var t Scalar
+	ScalarReduce512(&t, &res)
+	return t
+}
+
+// Tweak computes the public key tweaked by commitment, A' = A + t * G, along
+// with the tweak scalar t.
+func (pk *Public) Tweak(commitment []byte) (*Public, Scalar) {
+	var npk = &Public{}
+
+	// t = tweak(As, commitment)
+	var As = pk.Key()
+	var t = tweakScalar(&As, commitment)
+
+	// A' = A + t * G
+	var tG Point
+	ScalarMultBase(&tG, &t)
+	PointAdd(&npk.point, &pk.point, &tG)
+
+	return npk, t
+}
+
+// Tweak computes the secret key tweaked by commitment, a' = a + t, which
+// corresponds to the public key returned by Public.Tweak for the same
+// commitment. Signatures made with the tweaked secret key verify under the
+// tweaked public key.
+func (sk *Secret) Tweak(commitment []byte) *Secret {
+	var nsk = &Secret{derived: true}
+
+	// t = tweak(As, commitment)
+	var As = sk.Public().Key()
+	var t = tweakScalar(&As, commitment)
+
+	// a' = a + t
+	ScalarMultScalarAddScalar(&nsk.scalar, &scalarOne, &sk.scalar, &t)
+
+	// (prefix' || _) = sha512(tweak_str || prefix || t)
+	var hash = sha512.New()
+	var res Buffer512
+	hash.Write([]byte("zed25519_tweak_prefix"))
+	hash.Write(sk.prefix[:])
+	hash.Write(t[:])
+	hash.Sum(res[:0])
+	copy(nsk.prefix[:], res[:32])
+
+	return nsk
+}
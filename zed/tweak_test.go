@@ -0,0 +1,63 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"testing"
+)
+
+func TestTweak(t *testing.T) {
+	var sk = testSecret(8)
+	var pk = sk.Public()
+	var msg = []byte("taproot")
+
+	var tpk, tw = pk.Tweak([]byte("script"))
+	var tsk = sk.Tweak([]byte("script"))
+	if tpk.Key() != tsk.Public().Key() {
+		t.Fatal("tweaked secret and public keys disagree")
+	}
+
+	// A' = A + t * G
+	var tG, want Point
+	ScalarMultBase(&tG, &tw)
+	PointAdd(&want, &pk.point, &tG)
+	if PointToKey(&want) != tpk.Key() {
+		t.Error("tweaked public key is not A + t * G")
+	}
+
+	var sig = tsk.Sign(msg)
+	if !tpk.Verify(msg, sig[:]) {
+		t.Error("signature by the tweaked secret does not verify")
+	}
+	if pk.Verify(msg, sig[:]) {
+		t.Error("signature by the tweaked secret verifies under the untweaked key")
+	}
+
+	var opk, _ = pk.Tweak([]byte("other script"))
+	if opk.Key() == tpk.Key() || opk.Verify(msg, sig[:]) {
+		t.Error("different commitments give the same tweaked key")
+	}
+}
@@ -26,9 +26,9 @@
 package zed
 
 import (
-	"bytes"
-
 	"crypto/sha512"
+	"crypto/subtle"
+	"sync"
 )
 
 // Buffer256 is syntax sugar for a generic 32-byte (256-bit) buffer.
@@ -61,6 +61,19 @@ type Scalar = [32]byte
 // back into this format before returning the result to caller.
 type Point = ExtendedGroupElement
 
+// groupOrder is the order q of the Ed25519 prime-order subgroup, encoded as a
+// little-endian Scalar. It is not itself a valid (reduced) scalar.
+var groupOrder = Scalar{
+	0xed, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58,
+	0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10,
+}
+
+// scalarOne is the scalar 1, used to compute plain additions (1 * a + b) with
+// ScalarMultScalarAddScalar.
+var scalarOne = Scalar{1}
+
 // ScalarReduce512 takes a 64-byte buffer and "reduces" it "mod q", producing
 // a valid scalar value. When the 64-byte input is a good unbiased random
 // value, then the output scalar is also a (nearly) unbiased random value.
@@ -84,6 +97,66 @@ func ScalarMultScalar(r, a, b *Scalar) {
 	ScMulAdd(r, a, b, &zero)
 }
 
+// scalarMinusOne is the scalar (q - 1), which is -1 mod q, used to compute
+// negations (-1 * a + 0) with ScalarMultScalarAddScalar.
+var scalarMinusOne = Scalar{
+	0xec, 0xd3, 0xf5, 0x5c, 0x1a, 0x63, 0x12, 0x58,
+	0xd6, 0x9c, 0xf7, 0xa2, 0xde, 0xf9, 0xde, 0x14,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x10,
+}
+
+// ScalarNeg performs the scalar operation (-a), computed as ((q - 1) * a + 0).
+func ScalarNeg(r, a *Scalar) {
+	var zero [32]byte
+	ScMulAdd(r, &scalarMinusOne, a, &zero)
+}
+
+// ScalarSub performs the scalar operation (a - b), computed as
+// ((q - 1) * b + a).
+func ScalarSub(r, a, b *Scalar) {
+	ScMulAdd(r, &scalarMinusOne, b, a)
+}
+
+// ScalarInvert computes the multiplicative inverse (1 / a) of a non-zero
+// scalar, using Fermat's little theorem: a^(q - 2) = a^-1 (mod q). The
+// exponent is fixed, so the sequence of operations does not depend on a. The
+// "inverse" of zero is zero.
+func ScalarInvert(r, a *Scalar) {
+
+	// e = q - 2
+	var e = groupOrder
+	e[0] -= 2
+
+	// x = a^e, by square-and-multiply from the highest bit of e
+	var x = scalarOne
+	var b = *a
+	for i := 252; i >= 0; i-- {
+		ScalarMultScalar(&x, &x, &x)
+		if (e[i/8]>>(i%8))&1 == 1 {
+			ScalarMultScalar(&x, &x, &b)
+		}
+	}
+
+	*r = x
+}
+
+// ClampScalar applies the Ed25519 "clamping" operation to s in place, as done
+// to the hash of the seed when generating a key: the lowest 3 bits are
+// cleared, so that s is a multiple of the cofactor (8), and the highest bit
+// is cleared while the second-highest is set, so that s has a fixed bit
+// length. Clamping an already clamped scalar leaves it unchanged.
+func ClampScalar(s *Scalar) {
+	s[0] &= 248
+	s[31] &= 63
+	s[31] |= 64
+}
+
+// IsClamped checks whether s has the bit pattern produced by ClampScalar.
+func IsClamped(s *Scalar) bool {
+	return s[0]&7 == 0 && s[31]&192 == 64
+}
+
 // TODO: Understand this function better.
 func ValidScalar(s *Scalar) bool {
 	return ScMinimal(s)
@@ -97,6 +170,15 @@ func CompressPoint(r *Buffer256, p *Point) {
 	p.ToBytes(r)
 }
 
+// PointToKey is a convenience wrapper around CompressPoint, which returns the
+// 32-byte compressed form of p directly. For the point of a public key, this
+// is the same as Public.Key.
+func PointToKey(p *Point) Buffer256 {
+	var b Buffer256
+	CompressPoint(&b, p)
+	return b
+}
+
 // DecompressPoint expands the 32-byte compressed canonical binary
 // representation of an Ed25519 curve point into an ExtendedGroupElement. It
 // is a wrapper for the ref10-based function ExtendedGroupElement.FromBytes.
@@ -104,6 +186,21 @@ func DecompressPoint(r *Point, b *Buffer256) bool {
 	return r.FromBytes(b)
 }
 
+// DecompressPointStrict is the same as DecompressPoint, but also fails if b is
+// not the canonical encoding of its point: if its y coordinate is not reduced
+// (y >= p), or if it has the sign bit set for one of the two points with
+// x = 0, whose negation is the point itself. Each point then has exactly one
+// encoding which DecompressPointStrict accepts, which is the one produced by
+// CompressPoint.
+func DecompressPointStrict(r *Point, b *Buffer256) bool {
+	if !r.FromBytes(b) {
+		return false
+	}
+
+	// canonical if: compress(decompress(b)) == b
+	return PointToKey(r) == *b
+}
+
 // ToExtended is a hack that allows recovering an ExtendedGroupElement curve
 // point representation from the ProjectiveGroupElement representation. It
 // does this, highly inefficiently, by serializing the Projective element,
@@ -126,8 +223,11 @@ func PointIdentity(r *Point) {
 }
 
 // PointNeg flips the x-axis of an ExtendedGroupElement, such that P' = -P.
+// r and p may be the same point.
 func PointNeg(r, p *Point) {
 	FeNeg(&r.X, &p.X)
+	FeCopy(&r.Y, &p.Y)
+	FeCopy(&r.Z, &p.Z)
 	FeNeg(&r.T, &p.T)
 }
 
@@ -157,6 +257,22 @@ func PointSub(r, p, q *Point) {
 	rComp.ToExtended(r)
 }
 
+// basePoint holds the Ed25519 base point G in extended form. Like any other
+// precomputation of the base point, it is computed once, on first use, and
+// shared by all callers afterwards; use BasePoint to access it.
+var basePoint Point
+var basePointOnce sync.Once
+
+// BasePoint sets r to the Ed25519 base point G, for use in generic point
+// arithmetic. Multiplying by the base point should still be done with
+// ScalarMultBase, which uses the ref10 precomputed tables.
+func BasePoint(r *Point) {
+	basePointOnce.Do(func() {
+		ScalarMultBase(&basePoint, &scalarOne)
+	})
+	PointCopy(r, &basePoint)
+}
+
 // ScalarMultBase is a wrapper function around the ref10-based implementation's
 // "GeScalarMultBase" function, which takes a Scalar value s, and the implicit
 // Ed25519 base point B, and computes s * B.
@@ -174,6 +290,97 @@ func ScalarMultPointVartime(r *ExtendedGroupElement, a *[32]byte, p *ExtendedGro
 	rProj.ToExtended(r)
 }
 
+// ScalarMultPoint performs a constant-time multiplication of a scalar by an
+// arbitrary curve point, a * P, for use when the scalar is secret. The scalar
+// is recoded into 64 signed 4-bit digits, as in the ref10-based function
+// GeScalarMultBase, and the product is computed with a fixed sequence of
+// doublings and additions, where each addition uses a table entry chosen
+// with constant-time conditional moves. It is several times slower than
+// ScalarMultPointVartime, which should be preferred for public scalars.
+//
+// Preconditions: a[31] <= 127, which holds for any reduced or clamped scalar.
+func ScalarMultPoint(r *Point, a *Scalar, p *Point) {
+
+	// table = P, 2P, 3P, ..., 8P
+	var table [8]CachedGroupElement
+	var c CompletedGroupElement
+	var u Point
+	p.ToCached(&table[0])
+	PointCopy(&u, p)
+	for i := 1; i < 8; i++ {
+		geAdd(&c, &u, &table[0])
+		c.ToExtended(&u)
+		u.ToCached(&table[i])
+	}
+
+	// recode a into signed digits e[i], each between -8 and 8
+	var e [64]int8
+	for i, v := range a {
+		e[2*i] = int8(v & 15)
+		e[2*i+1] = int8((v >> 4) & 15)
+	}
+	var carry = int8(0)
+	for i := 0; i < 63; i++ {
+		e[i] += carry
+		carry = (e[i] + 8) >> 4
+		e[i] -= carry << 4
+	}
+	e[63] += carry
+
+	// r = sum(16^i * e[i] * P)
+	var t CachedGroupElement
+	var s ProjectiveGroupElement
+	r.Zero()
+	for i := 63; i >= 0; i-- {
+		if i != 63 {
+			r.Double(&c)
+			c.ToProjective(&s)
+			s.Double(&c)
+			c.ToProjective(&s)
+			s.Double(&c)
+			c.ToProjective(&s)
+			s.Double(&c)
+			c.ToExtended(r)
+		}
+
+		selectCached(&t, &table, int32(e[i]))
+		geAdd(&c, r, &t)
+		c.ToExtended(r)
+	}
+}
+
+// selectCached sets t = b * P in constant time, for -8 <= b <= 8, given the
+// table of multiples P, 2P, ..., 8P.
+func selectCached(t *CachedGroupElement, table *[8]CachedGroupElement, b int32) {
+	var bNegative = negative(b)
+	var bAbs = b - (((-bNegative) & b) << 1)
+
+	// t = identity
+	FeOne(&t.yPlusX)
+	FeOne(&t.yMinusX)
+	FeOne(&t.Z)
+	FeZero(&t.T2d)
+
+	// t = |b| * P
+	for i := int32(0); i < 8; i++ {
+		var eq = equal(bAbs, i+1)
+		FeCMove(&t.yPlusX, &table[i].yPlusX, eq)
+		FeCMove(&t.yMinusX, &table[i].yMinusX, eq)
+		FeCMove(&t.Z, &table[i].Z, eq)
+		FeCMove(&t.T2d, &table[i].T2d, eq)
+	}
+
+	// if b < 0, t = -t
+	var minusT CachedGroupElement
+	FeCopy(&minusT.yPlusX, &t.yMinusX)
+	FeCopy(&minusT.yMinusX, &t.yPlusX)
+	FeCopy(&minusT.Z, &t.Z)
+	FeNeg(&minusT.T2d, &t.T2d)
+	FeCMove(&t.yPlusX, &minusT.yPlusX, bNegative)
+	FeCMove(&t.yMinusX, &minusT.yMinusX, bNegative)
+	FeCMove(&t.T2d, &minusT.T2d, bNegative)
+}
+
 // PointClearCofactor is a utility which multiplies a curve point by Ed25519's
 // "cofactor", which is 8. This is functionally equivalent to doubling the point
 // 3 times. Clearing the cofactor of a point prevents some malleability which
@@ -197,11 +404,33 @@ func PointClearCofactor(r, p *Point) {
 // if there are multiple "ExtendedGroupElement" representations of the same
 // value or not, because this representation stores ratios between X, Y and Z
 // points internally.
+// The comparison is constant-time, since points are not always public (for
+// example the result of a Diffie-Hellman exchange.)
 func PointEqual(a, b *ExtendedGroupElement) bool {
 	var aBytes, bBytes [32]byte
 	a.ToBytes(&aBytes)
 	b.ToBytes(&bBytes)
-	return bytes.Equal(aBytes[:], bBytes[:])
+	return subtle.ConstantTimeCompare(aBytes[:], bBytes[:]) == 1
+}
+
+// ScalarEqual compares whether two scalars are equal in constant time. Any
+// comparison involving a secret scalar, a secret prefix, a nonce, or any
+// intermediate value hashed from them must be done this way, so that the
+// time taken does not reveal how many leading bytes matched. Comparisons of
+// purely public data (signature bytes, public keys, challenge scalars
+// recomputed by a verifier) do not need to be constant-time.
+func ScalarEqual(a, b *Scalar) bool {
+	return subtle.ConstantTimeCompare(a[:], b[:]) == 1
+}
+
+// pointTorsionFree checks whether p lies in the prime-order subgroup generated
+// by the base point, by checking that q * P is the identity. Any point with a
+// small-order (torsion) component fails this check.
+func pointTorsionFree(p *Point) bool {
+	var qP, I Point
+	ScalarMultPointVartime(&qP, &groupOrder, p)
+	PointIdentity(&I)
+	return PointEqual(&qP, &I)
 }
 
 // PointCopy duplicates the data of the input Point into a new Point object.
@@ -234,7 +463,7 @@ func PointCopy(r, p *Point) {
 //      if ( p = decompress( ob[ 0:32] ) ) break
 //      if ( p = decompress( ob[32:64] ) ) break
 //      ib[0]++
-//    return P
+//    return 8 * P
 //
 //  Intuition: initialize a 64-byte "In Buffer" (ib) with the hash of the input (x). Then set
 //  the first byte of ib to 0, and consider it a counter. Then run a loop, where at each iteration
@@ -268,4 +497,7 @@ func HashToPointVartime(r *Point, x []byte) {
 		}
 		ib[0]++
 	}
+
+	// r = cofactor * p, so r is in the same subgroup as the base point
+	PointClearCofactor(r, &p)
 }
@@ -0,0 +1,167 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"encoding/hex"
+	"sync"
+	"testing"
+)
+
+// Known answers for HashToPointVartime, computed independently from the
+// algorithm in its comment: the first point found, multiplied by 8.
+var hashToPointTests = []struct {
+	x, point string
+}{
+	{"", "14d008a12b488aeaeec2dc5072c3b02c9d779ea19c4340de54a4b0877e6e1ff2"},
+	{"abc", "5c082331c3ca974ad7e870dbecc49fdee2bef9b44b85c47371d3052c5f7044d6"},
+	{"zed25519", "dd92bf9857b7578c9a013e004a30da8b3547f0ab7a04df1c8935cf0893af22bf"},
+}
+
+func TestHashToPointVartime(t *testing.T) {
+	for _, test := range hashToPointTests {
+		var P Point
+		HashToPointVartime(&P, []byte(test.x))
+
+		var Ps Buffer256
+		CompressPoint(&Ps, &P)
+		if got := hex.EncodeToString(Ps[:]); got != test.point {
+			t.Errorf("HashToPointVartime(%q) = %s, want %s", test.x, got, test.point)
+		}
+		if !pointTorsionFree(&P) {
+			t.Errorf("HashToPointVartime(%q) is not in the prime-order subgroup", test.x)
+		}
+	}
+}
+
+// TestBasePointConcurrent calls BasePoint from several goroutines before it
+// has been computed. Run it with -race.
+func TestBasePointConcurrent(t *testing.T) {
+	basePointOnce = sync.Once{}
+
+	// G = 1 * G, computed independently of BasePoint
+	var G Point
+	ScalarMultBase(&G, &scalarOne)
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			var P Point
+			BasePoint(&P)
+			if !PointEqual(&P, &G) {
+				t.Error("BasePoint is not the base point")
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+// testSecret returns a fixed Secret Key for tests, made from a seed of 32
+// bytes of n.
+func testSecret(n byte) *Secret {
+	var seed = make([]byte, 32)
+	for i := range seed {
+		seed[i] = n
+	}
+	return SecretFromSeed(seed)
+}
+
+var clampScalarTests = []Scalar{
+	{},
+	{0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff},
+	{1, 2, 3, 4, 5, 6, 7, 8, 9, 10, 11, 12, 13, 14, 15, 16,
+		17, 18, 19, 20, 21, 22, 23, 24, 25, 26, 27, 28, 29, 30, 31, 0x80},
+}
+
+func TestClampScalar(t *testing.T) {
+	for _, test := range clampScalarTests {
+		var s = test
+		if IsClamped(&s) {
+			t.Errorf("IsClamped(%x) = true before clamping", test)
+		}
+		ClampScalar(&s)
+		if !IsClamped(&s) {
+			t.Errorf("IsClamped(ClampScalar(%x)) = false", test)
+		}
+		var again = s
+		ClampScalar(&again)
+		if again != s {
+			t.Errorf("ClampScalar is not idempotent on %x", test)
+		}
+	}
+
+	for n := byte(0); n < 4; n++ {
+		var a = testSecret(n).Scalar()
+		if !IsClamped(&a) {
+			t.Errorf("scalar of testSecret(%d) is not clamped", n)
+		}
+	}
+}
+
+func TestScalarEqual(t *testing.T) {
+	var a = Scalar{1, 2, 3}
+	var b = a
+	if !ScalarEqual(&a, &b) {
+		t.Error("ScalarEqual is false on equal scalars")
+	}
+	for _, i := range []int{0, 15, 31} {
+		var c = a
+		c[i] ^= 1
+		if ScalarEqual(&a, &c) {
+			t.Errorf("ScalarEqual is true on scalars differing in byte %d", i)
+		}
+	}
+}
+
+func TestPointEqual(t *testing.T) {
+	var P = testSecret(1).Public().Point()
+
+	// P + I is the same point, in another representation
+	var I, Q Point
+	PointIdentity(&I)
+	PointAdd(&Q, &P, &I)
+	if !PointEqual(&P, &Q) {
+		t.Error("PointEqual is false on equal points")
+	}
+
+	var R = testSecret(2).Public().Point()
+	if PointEqual(&P, &R) {
+		t.Error("PointEqual is true on different points")
+	}
+}
+
+func TestPointToKey(t *testing.T) {
+	for n := byte(0); n < 4; n++ {
+		var pk = testSecret(n).Public()
+		var P = pk.Point()
+		if PointToKey(&P) != pk.Key() {
+			t.Errorf("PointToKey differs from Key for testSecret(%d)", n)
+		}
+	}
+}
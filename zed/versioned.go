@@ -0,0 +1,169 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"strconv"
+)
+
+//
+//  The versioned key format is a self-describing serialization of a Secret,
+//  so that the key formats can change in the future while keys stored in the
+//  old formats remain readable:
+//
+//    key = version || tag || body
+//
+//  Currently the only version is 1, with the following tags:
+//
+//    1 (seed):     body is the 32-byte seed the key was created from
+//    2 (expanded): body is the 64-byte form from Secret.Key, for a key with
+//                  no known seed which was not produced by Derive
+//    3 (derived):  body is the 64-byte form from Secret.Key, for a key
+//                  produced by Derive
+//
+//  Keys with a known seed are always stored by seed, since it is the most
+//  portable form, from which all others can be recreated.
+//
+//  A key bundle stores many versioned keys in one blob (for example a wallet
+//  file), each prefixed by its length as a 4-byte big-endian integer:
+//
+//    bundle = len(key_1) || key_1 || ... || len(key_n) || key_n
+//
+
+const (
+	versionedKeyV1 = 1
+
+	versionedKeySeed     = 1
+	versionedKeyExpanded = 2
+	versionedKeyDerived  = 3
+)
+
+// MarshalVersioned serializes the secret key into the versioned key format.
+func (sk *Secret) MarshalVersioned() []byte {
+	if sk.hasSeed {
+		return append([]byte{versionedKeyV1, versionedKeySeed}, sk.seed[:]...)
+	}
+
+	var tag byte = versionedKeyExpanded
+	if sk.derived {
+		tag = versionedKeyDerived
+	}
+	var key = sk.Key()
+	return append([]byte{versionedKeyV1, tag}, key[:]...)
+}
+
+// UnmarshalVersionedSecret decodes a secret key serialized in the versioned
+// key format, returning an error for any unknown version or tag, or a body of
+// the wrong length.
+func UnmarshalVersionedSecret(buf []byte) (*Secret, error) {
+	if len(buf) < 2 {
+		return nil, errors.New("UnmarshalVersionedSecret: key too short")
+	}
+	if buf[0] != versionedKeyV1 {
+		return nil, errors.New("UnmarshalVersionedSecret: unknown version: " + strconv.Itoa(int(buf[0])))
+	}
+
+	var tag, body = buf[1], buf[2:]
+	switch tag {
+	case versionedKeySeed:
+		if len(body) != 32 {
+			return nil, errors.New("UnmarshalVersionedSecret: bad seed length: " + strconv.Itoa(len(body)))
+		}
+		return SecretFromSeed(body), nil
+
+	case versionedKeyExpanded, versionedKeyDerived:
+		if len(body) != 64 {
+			return nil, errors.New("UnmarshalVersionedSecret: bad key length: " + strconv.Itoa(len(body)))
+		}
+		var sk = SecretFromKey(body)
+		sk.derived = tag == versionedKeyDerived
+		return sk, nil
+	}
+
+	return nil, errors.New("UnmarshalVersionedSecret: unknown tag: " + strconv.Itoa(int(tag)))
+}
+
+// MarshalKeyBundle serializes a list of secret keys into a key bundle.
+func MarshalKeyBundle(keys []*Secret) []byte {
+	var bundle []byte
+	var l [4]byte
+	for _, sk := range keys {
+		var key = sk.MarshalVersioned()
+		binary.BigEndian.PutUint32(l[:], uint32(len(key)))
+		bundle = append(bundle, l[:]...)
+		bundle = append(bundle, key...)
+	}
+	return bundle
+}
+
+// ValidateKeyBundle parses every key of a key bundle, checks that its secret
+// scalar is usable, and returns the corresponding public keys in order. If
+// any entry is truncated, malformed or invalid, it returns an error naming the
+// index of the first bad entry, so that a corrupted wallet file is detected
+// as soon as it is loaded.
+func ValidateKeyBundle(bundle []byte) ([]*Public, error) {
+	var pks []*Public
+	for i := 0; len(bundle) > 0; i++ {
+
+		// len(key) || key
+		if len(bundle) < 4 {
+			return nil, fmt.Errorf("ValidateKeyBundle: entry %d: truncated length", i)
+		}
+		var l = binary.BigEndian.Uint32(bundle[:4])
+		bundle = bundle[4:]
+		if uint64(len(bundle)) < uint64(l) {
+			return nil, fmt.Errorf("ValidateKeyBundle: entry %d: truncated key", i)
+		}
+		var key = bundle[:l]
+		bundle = bundle[l:]
+
+		var sk, err = UnmarshalVersionedSecret(key)
+		if err != nil {
+			return nil, fmt.Errorf("ValidateKeyBundle: entry %d: %w", i, err)
+		}
+		if !validSecretScalar(&sk.scalar) {
+			return nil, fmt.Errorf("ValidateKeyBundle: entry %d: invalid scalar", i)
+		}
+
+		pks = append(pks, sk.Public())
+	}
+
+	return pks, nil
+}
+
+// validSecretScalar checks whether s could be the scalar of a secret key: it
+// must either be clamped, as for keys created from a seed, or be a reduced,
+// non-zero scalar, as for keys created by Derive.
+func validSecretScalar(s *Scalar) bool {
+	if IsClamped(s) {
+		return true
+	}
+	var zero Scalar
+	return ValidScalar(s) && !ScalarEqual(s, &zero)
+}
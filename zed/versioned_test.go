@@ -0,0 +1,143 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"strings"
+	"testing"
+)
+
+// testVersionedKeys returns a seed key, an expanded key and a derived key.
+func testVersionedKeys() []*Secret {
+	var seed = testSecret(14)
+	var derived = seed.Derive([]byte("child"), nil)
+	var key = derived.Key()
+	return []*Secret{seed, SecretFromKey(key[:]), derived}
+}
+
+func TestMarshalVersioned(t *testing.T) {
+	var tags = []byte{versionedKeySeed, versionedKeyExpanded, versionedKeyDerived}
+	var lengths = []int{2 + 32, 2 + 64, 2 + 64}
+
+	for i, sk := range testVersionedKeys() {
+		var buf = sk.MarshalVersioned()
+		if len(buf) != lengths[i] || buf[0] != versionedKeyV1 || buf[1] != tags[i] {
+			t.Errorf("key %d: MarshalVersioned = %x", i, buf)
+			continue
+		}
+
+		var nsk, err = UnmarshalVersionedSecret(buf)
+		if err != nil {
+			t.Errorf("key %d: %v", i, err)
+			continue
+		}
+		var seed, hasSeed = sk.Seed()
+		var nseed, nhasSeed = nsk.Seed()
+		if !nsk.Equal(sk) || nseed != seed || nhasSeed != hasSeed || nsk.IsDerived() != sk.IsDerived() {
+			t.Errorf("key %d does not round-trip", i)
+		}
+	}
+}
+
+var unmarshalVersionedErrorTests = []struct {
+	name string
+	buf  []byte
+}{
+	{"empty", nil},
+	{"no tag", []byte{versionedKeyV1}},
+	{"unknown version", append([]byte{2, versionedKeySeed}, make([]byte, 32)...)},
+	{"version 0", append([]byte{0, versionedKeySeed}, make([]byte, 32)...)},
+	{"unknown tag", append([]byte{versionedKeyV1, 4}, make([]byte, 32)...)},
+	{"short seed", append([]byte{versionedKeyV1, versionedKeySeed}, make([]byte, 31)...)},
+	{"long seed", append([]byte{versionedKeyV1, versionedKeySeed}, make([]byte, 64)...)},
+	{"short expanded key", append([]byte{versionedKeyV1, versionedKeyExpanded}, make([]byte, 32)...)},
+	{"long derived key", append([]byte{versionedKeyV1, versionedKeyDerived}, make([]byte, 65)...)},
+}
+
+func TestUnmarshalVersionedErrors(t *testing.T) {
+	for _, test := range unmarshalVersionedErrorTests {
+		if _, err := UnmarshalVersionedSecret(test.buf); err == nil {
+			t.Errorf("%s: UnmarshalVersionedSecret did not fail", test.name)
+		}
+	}
+}
+
+func TestValidateKeyBundle(t *testing.T) {
+	var keys = testVersionedKeys()
+	var bundle = MarshalKeyBundle(keys)
+
+	var pks, err = ValidateKeyBundle(bundle)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pks) != len(keys) {
+		t.Fatalf("ValidateKeyBundle returned %d keys, want %d", len(pks), len(keys))
+	}
+	for i := range keys {
+		if pks[i].Key() != keys[i].Public().Key() {
+			t.Errorf("key %d of the bundle differs", i)
+		}
+	}
+
+	if pks, err := ValidateKeyBundle(nil); err != nil || len(pks) != 0 {
+		t.Errorf("empty bundle: ValidateKeyBundle = %v, %v", pks, err)
+	}
+}
+
+// keyBundleCorruptions corrupt the bundle of testVersionedKeys, whose entries
+// start at offsets 0, 38 and 108, and report the index of the bad entry.
+var keyBundleCorruptions = []struct {
+	name    string
+	corrupt func(b []byte) []byte
+	entry   string
+}{
+	{"version of the first entry", func(b []byte) []byte { b[4] = 9; return b }, "entry 0"},
+	{"tag of the second entry", func(b []byte) []byte { b[38+5] = 9; return b }, "entry 1"},
+	{"length of the second entry", func(b []byte) []byte { b[38+3]--; return b }, "entry 1"},
+	{"scalar of the third entry", func(b []byte) []byte {
+		for i := 108 + 6; i < 108+6+32; i++ {
+			b[i] = 0
+		}
+		return b
+	}, "entry 2"},
+	{"truncated last entry", func(b []byte) []byte { return b[:len(b)-1] }, "entry 2"},
+	{"truncated length", func(b []byte) []byte { return append(b, 0, 0) }, "entry 3"},
+	{"trailing garbage", func(b []byte) []byte { return append(b, 0, 0, 0, 2, 1, 1) }, "entry 3"},
+}
+
+func TestValidateKeyBundleCorrupt(t *testing.T) {
+	for _, test := range keyBundleCorruptions {
+		var bundle = test.corrupt(MarshalKeyBundle(testVersionedKeys()))
+		var pks, err = ValidateKeyBundle(bundle)
+		if err == nil || pks != nil {
+			t.Errorf("%s: ValidateKeyBundle did not fail", test.name)
+			continue
+		}
+		if !strings.Contains(err.Error(), test.entry+":") {
+			t.Errorf("%s: error %q does not name %s", test.name, err, test.entry)
+		}
+	}
+}
@@ -28,6 +28,8 @@ package zed
 import (
 	"bytes"
 	"crypto/sha512"
+	"errors"
+	"hash"
 )
 
 //  TODO: Explain VRF, and Signal VRF
@@ -52,10 +54,11 @@ import (
 //
 //  - The hash-to-point function in this implementation implicitly multiplies
 //    the resulting point by the Ed25519 cofactor (8), to ensure that the result
-//    is always in the same subgroup as the base point. Ed25519 already ensures
-//    that the private scalar a is a multiple of this cofactor, therefore
-//    V = (a * Bv) is guaranteed already to be in the expected subgroup.
-//		Therefore to generate the VRF output, we use V directly, instead of cV.
+//    is always in the same subgroup as the base point, so that V = (a * Bv)
+//    is in that subgroup too for an honest prover. A verifier cannot rely on
+//    that for a V taken from a proof, which may have a small-order component,
+//    so the VRF output is computed from cV = (8 * V) rather than from V
+//    directly, and every valid proof for a given (pk, x) gives the same output.
 //
 //  - Signal's VRF takes an input of random bytes as a nonce to generate the
 //    value r (which must remain secret.) It combines this nonce with other
@@ -83,32 +86,40 @@ import (
 // a 96-byte "proof" that y is the exact correct output for the input pair
 // (sk, x). The output y cannot be predicted by any party who does not possess
 // the secret key sk, but given the "proof", can be verified by any party which
-// possesses the corresponding public key.
+// possesses the corresponding public key. Like Sign, VrfEval panics if the
+// prefix of sk is all zeroes, since the nonce would then be public.
 func (sk *Secret) VrfEval(x []byte) (VrfResult, VrfProof) {
+	return sk.vrfEval(sha512.New, x)
+}
+
+// vrfEval implements VrfEval with the hash function newHash.
+func (sk *Secret) vrfEval(newHash func() hash.Hash, x []byte) (VrfResult, VrfProof) {
+
+	// if prefix is all zeroes, panic
+	if sk.hasZeroPrefix() {
+		panic("VrfEval: secret key has an all-zero prefix")
+	}
 
 	// sha512 instance, result buffer
-	var hash = sha512.New()
+	var hash = newHash()
 	var res Buffer512
 
 	// get private scalar "a", prefix "p", and public point "A" from Secret
 	var a = sk.Scalar()
 	var p = sk.Prefix()
-	var A = sk.Public().Point()
+	var pk = sk.Public()
+	var A = pk.Point()
 
 	// As = compress(A)
 	var As Buffer256
 	CompressPoint(&As, &A)
 
 	// Bv = hashToPoint(As || x)
-	var Bv Point
-	var As_x = make([]byte, 32+len(x))
-	copy(As_x[:32], As[:])
-	copy(As_x[32:], x[:])
-	HashToPointVartime(&Bv, As_x[:])
+	var Bv = pk.VrfInputPoint(x)
 
 	// V = a * Bv
 	var V Point
-	ScalarMultPointVartime(&V, &a, &Bv) // WARNING: VARTIME ALGO USED ON PRIVATE DATA
+	ScalarMultPoint(&V, &a, &Bv)
 
 	// Vs = compress(V)
 	var Vs Buffer256
@@ -132,7 +143,7 @@ func (sk *Secret) VrfEval(x []byte) (VrfResult, VrfProof) {
 
 	// Rv = r * Bv
 	var Rv Point
-	ScalarMultPointVartime(&Rv, &r, &Bv)
+	ScalarMultPoint(&Rv, &r, &Bv)
 
 	// Rvs = compress(Rv)
 	var Rvs Buffer256
@@ -153,6 +164,11 @@ func (sk *Secret) VrfEval(x []byte) (VrfResult, VrfProof) {
 	var s Scalar
 	ScalarMultScalarAddScalar(&s, &h, &a, &r)
 
+	// wipe secret temporaries
+	wipe(a[:])
+	wipe(p[:])
+	wipe(r[:])
+
 	// cV = cofactor * V
 	var cV Point
 	PointClearCofactor(&cV, &V)
@@ -180,14 +196,137 @@ func (sk *Secret) VrfEval(x []byte) (VrfResult, VrfProof) {
 // VrfVerify accepts an input x of arbitrary length, a Public Key pk, and a
 // 96-byte "proof" produced by the owner of the corresponding secret key sk.
 // VrfVerify outputs a 32-byte result y, and a verification result bool (note
-// that y will be 32 zero-bytes if the validation fails.)
+// that y will be 32 zero-bytes if the validation fails.) A proof of any length
+// other than 96 bytes fails validation, rather than causing a panic. Use
+// VrfVerifyErr to learn why a proof failed.
 func (pk *Public) VrfVerify(x, proof []byte) (VrfResult, bool) {
+	var y, err = pk.VrfVerifyErr(x, proof)
+	return y, err == nil
+}
+
+// VrfVerifyStrict is the same as VrfVerify, but also fails if the V point of
+// the proof is not in its canonical encoding (see DecompressPointStrict), so
+// that a proof cannot be re-encoded into another valid 96-byte proof.
+func (pk *Public) VrfVerifyStrict(x, proof []byte) (VrfResult, bool) {
+
+	// if proof length != 96, or V not canonical, fail
+	if len(proof) != len(VrfProof{}) {
+		return VrfResult{}, false
+	}
+	var Vs Buffer256
+	var V Point
+	copy(Vs[:], proof[:32])
+	if !DecompressPointStrict(&V, &Vs) {
+		return VrfResult{}, false
+	}
+
+	return pk.VrfVerify(x, proof)
+}
+
+// Errors returned by VrfVerifyErr for each reason a VRF proof can fail.
+var (
+	ErrVrfProofLength       = errors.New("zed: bad VRF proof length")
+	ErrVrfInvalidPoint      = errors.New("zed: invalid VRF proof point")
+	ErrVrfInvalidScalar     = errors.New("zed: invalid VRF proof scalar")
+	ErrVrfSmallOrder        = errors.New("zed: small-order point in VRF proof")
+	ErrVrfChallengeMismatch = errors.New("zed: VRF proof challenge mismatch")
+)
+
+// VrfVerifyErr performs the same check as VrfVerify, but reports a failure
+// as an error instead of a zero result, so that a failure can never be
+// mistaken for an output. The error is one of: ErrVrfProofLength if the proof
+// is not 96 bytes long; ErrVrfInvalidPoint if V is not a valid point encoding;
+// ErrVrfInvalidScalar if h or s is not fully reduced; ErrVrfSmallOrder if the
+// public key, V or the input point has small order; or ErrVrfChallengeMismatch
+// if the proof does not match the key and input.
+func (pk *Public) VrfVerifyErr(x, proof []byte) (VrfResult, error) {
+
+	// if proof length != 96, fail
+	if len(proof) != len(VrfProof{}) {
+		return VrfResult{}, ErrVrfProofLength
+	}
+
+	// Bv = hashToPoint(As || x)
+	var Bv = pk.VrfInputPoint(x)
+
+	return pk.vrfVerifyInput(sha512.New, x, proof, &Bv)
+}
+
+// VrfVerifyProof is VrfVerifyErr for a proof already held as a VrfProof, so
+// that its length is checked by the type system. A nil proof fails with
+// ErrVrfProofLength.
+func (pk *Public) VrfVerifyProof(x []byte, proof *VrfProof) (VrfResult, error) {
+	if proof == nil {
+		return VrfResult{}, ErrVrfProofLength
+	}
+	return pk.VrfVerifyErr(x, proof[:])
+}
+
+// ParseVrfProof decodes a 96-byte VRF proof (Vs || h || s) and checks each of
+// its components on its own, without a key or input: it returns
+// ErrVrfProofLength if buf is not 96 bytes long, ErrVrfInvalidPoint if Vs is
+// not a valid point encoding, ErrVrfSmallOrder if V has small order, or
+// ErrVrfInvalidScalar if h or s is not fully reduced. It never panics, so it
+// is safe on untrusted input, such as proofs read from the network, which can
+// then be rejected before they are stored. A proof which parses may still
+// fail verification.
+func ParseVrfProof(buf []byte) (*VrfProof, error) {
+	if len(buf) != len(VrfProof{}) {
+		return nil, ErrVrfProofLength
+	}
+
+	// V = decompress(buf[:32]), or fail
+	var Vs Buffer256
+	var V Point
+	copy(Vs[:], buf[:32])
+	if !DecompressPoint(&V, &Vs) {
+		return nil, ErrVrfInvalidPoint
+	}
+	if V.IsSmallOrder() {
+		return nil, ErrVrfSmallOrder
+	}
+
+	// h = buf[32:64], s = buf[64:], or fail if not reduced
+	var h, s Scalar
+	copy(h[:], buf[32:64])
+	copy(s[:], buf[64:])
+	if !ValidScalar(&h) || !ValidScalar(&s) {
+		return nil, ErrVrfInvalidScalar
+	}
+
+	var proof VrfProof
+	copy(proof[:], buf)
+	return &proof, nil
+}
+
+// VrfInputPoint computes the VRF input point Bv = hashToPoint(As || x) for the
+// public key pk and input x, which is the most expensive step of both VrfEval
+// and VrfVerify. Bv depends only on the public key and the input, not on any
+// proof, so a verifier checking several proofs from the same key on the same
+// input can compute it once.
+func (pk *Public) VrfInputPoint(x []byte) Point {
+	var As = pk.Key()
+
+	// Bv = hashToPoint(As || x)
+	var Bv Point
+	var As_x = make([]byte, 32+len(x))
+	copy(As_x[:32], As[:])
+	copy(As_x[32:], x[:])
+	HashToPointVartime(&Bv, As_x[:])
+
+	return Bv
+}
+
+// vrfVerifyInput is VrfVerify with the input point Bv already computed by
+// VrfInputPoint, and the hash function newHash. The proof must be 96 bytes
+// long.
+func (pk *Public) vrfVerifyInput(newHash func() hash.Hash, x, proof []byte, Bv *Point) (VrfResult, error) {
 
 	// all-zeroes result for validation failure
 	var zeros VrfResult
 
 	// sha512 instance, result buffer
-	var hash = sha512.New()
+	var hash = newHash()
 	var res Buffer512
 
 	// get public point "A", and its byte encoding, from the Public
@@ -201,30 +340,23 @@ func (pk *Public) VrfVerify(x, proof []byte) (VrfResult, bool) {
 	// V = decompress(Vs), or fail
 	var V Point
 	if !DecompressPoint(&V, &Vs) {
-		return zeros, false
+		return zeros, ErrVrfInvalidPoint
 	}
 
 	// h = proof[32:64]
 	var h Scalar
 	copy(h[:], proof[32:64])
 	if !ValidScalar(&h) {
-		return zeros, false
+		return zeros, ErrVrfInvalidScalar
 	}
 
 	// s = proof[64:]
 	var s Scalar
 	copy(s[:], proof[64:])
 	if !ValidScalar(&s) {
-		return zeros, false
+		return zeros, ErrVrfInvalidScalar
 	}
 
-	// Bv = hashToPoint(As || x)
-	var Bv Point
-	var As_x = make([]byte, 32+len(x))
-	copy(As_x[:32], As[:])
-	copy(As_x[32:], x[:])
-	HashToPointVartime(&Bv, As_x[:])
-
 	// I = "point at infinity" (group operation identity element)
 	var I Point
 	PointIdentity(&I)
@@ -235,7 +367,7 @@ func (pk *Public) VrfVerify(x, proof []byte) (VrfResult, bool) {
 
 	// if cA == I, fail
 	if PointEqual(&cA, &I) {
-		return zeros, false
+		return zeros, ErrVrfSmallOrder
 	}
 
 	// cV = cofactor * V
@@ -244,16 +376,16 @@ func (pk *Public) VrfVerify(x, proof []byte) (VrfResult, bool) {
 
 	// if cV == I, fail
 	if PointEqual(&cV, &I) {
-		return zeros, false
+		return zeros, ErrVrfSmallOrder
 	}
 
 	// cBv = cofactor * Bv
 	var cBv Point
-	PointClearCofactor(&cBv, &Bv)
+	PointClearCofactor(&cBv, Bv)
 
 	// if cBv == I, fail
 	if PointEqual(&cBv, &I) {
-		return zeros, false
+		return zeros, ErrVrfSmallOrder
 	}
 
 	// sB = s * B
@@ -262,7 +394,7 @@ func (pk *Public) VrfVerify(x, proof []byte) (VrfResult, bool) {
 
 	// hA = h * A
 	var hA Point
-	ScalarMultPointVartime(&hA, &h, &A)
+	pk.scalarMult(&hA, &h)
 
 	// R = sB - hA
 	var R Point
@@ -274,7 +406,7 @@ func (pk *Public) VrfVerify(x, proof []byte) (VrfResult, bool) {
 
 	// sBv = s * Bv
 	var sBv Point
-	ScalarMultPointVartime(&sBv, &s, &Bv)
+	ScalarMultPointVartime(&sBv, &s, Bv)
 
 	// hV = h * V
 	var hV Point
@@ -298,9 +430,9 @@ func (pk *Public) VrfVerify(x, proof []byte) (VrfResult, bool) {
 	hash.Sum(res[:0])
 	ScalarReduce512(&hCheck, &res)
 
-	// if h != hCheck, fail
+	// if h != hCheck, fail (both are public, no constant-time compare needed)
 	if !bytes.Equal(h[:], hCheck[:]) {
-		return zeros, false
+		return zeros, ErrVrfChallengeMismatch
 	}
 
 	// cVs = compress(cV)
@@ -315,5 +447,97 @@ func (pk *Public) VrfVerify(x, proof []byte) (VrfResult, bool) {
 	copy(y[:], res[:32])
 
 	// verified
-	return y, true
+	return y, nil
+}
+
+// DetectVrfEquivocation checks two VRF proofs by the same public key pk on the
+// same input x, and reports whether they are both valid but yield different
+// outputs. The VRF output for a given (key, input) pair is unique, so this can
+// never happen for an honest signer using a correct implementation: a true
+// result is evidence of a serious fault, such as a compromised key or a broken
+// VRF implementation, and should be treated as such. An error is returned if
+// either proof fails to verify.
+func DetectVrfEquivocation(pk *Public, x []byte, proof1, proof2 []byte) (equivocated bool, err error) {
+	var y1, ok1 = pk.VrfVerify(x, proof1)
+	if !ok1 {
+		return false, errors.New("DetectVrfEquivocation: first proof is invalid")
+	}
+
+	var y2, ok2 = pk.VrfVerify(x, proof2)
+	if !ok2 {
+		return false, errors.New("DetectVrfEquivocation: second proof is invalid")
+	}
+
+	return !bytes.Equal(y1[:], y2[:]), nil
+}
+
+// VrfChain evaluates a chain of n VRF outputs, such as a sequential random
+// beacon, where the first input is seed and each output is used as the input
+// of the next evaluation:
+//
+//   y_0 = VrfEval(seed), y_i = VrfEval(y_i-1)
+//
+// It returns every output along with its proof. The chain can be checked
+// with VrfChainIsConsistent.
+func (sk *Secret) VrfChain(seed []byte, n int) ([]VrfResult, []VrfProof) {
+	var outputs = make([]VrfResult, n)
+	var proofs = make([]VrfProof, n)
+
+	var x = seed
+	for i := 0; i < n; i++ {
+		outputs[i], proofs[i] = sk.VrfEval(x)
+		x = outputs[i][:]
+	}
+
+	return outputs, proofs
+}
+
+// VrfChainIsConsistent verifies a chain of VRF proofs produced by VrfChain,
+// checking that each proof is valid for pk with the previous output (or seed,
+// for the first proof) as its input. It returns the outputs of the chain, and
+// true if every proof is consistent. At the first proof which is not, it
+// returns the outputs verified so far and false.
+func VrfChainIsConsistent(pk *Public, seed []byte, proofs []VrfProof) (outputs []VrfResult, ok bool) {
+	outputs = make([]VrfResult, 0, len(proofs))
+
+	var x = seed
+	for i := range proofs {
+		var y, valid = pk.VrfVerify(x, proofs[i][:])
+		if !valid {
+			return outputs, false
+		}
+		outputs = append(outputs, y)
+		x = outputs[i][:]
+	}
+
+	return outputs, true
+}
+
+// VrfTag evaluates the VRF on input x under a key which is blinded by the
+// given context, for use as a pseudonymous tag: the same (sk, context, x)
+// always gives the same tag, but tags for the same x under different contexts
+// are unrelated outputs of different keys, so they cannot be linked to each
+// other without knowing the public key. The blinded key is the public child
+// key of sk for the index EncodeIndex("zed25519_vrf_tag", context), derived
+// with DerivationV3 so that the key of each context has its own prefix, and a
+// verifier holding the public key can re-derive it with VrfTagVerify.
+func (sk *Secret) VrfTag(context, x []byte) (tag VrfResult, proof VrfProof) {
+	var blinded Secret
+	sk.deriveInto(&blinded, DerivationV3, vrfTagIndex(context), nil)
+	return blinded.VrfEval(x)
+}
+
+// VrfTagVerify checks that tag and proof were produced by VrfTag for the
+// Secret Key corresponding to pk, with the given context and input x.
+func VrfTagVerify(pk *Public, context, x []byte, tag VrfResult, proof VrfProof) bool {
+	var blinded Public
+	pk.deriveInto(&blinded, DerivationV3, vrfTagIndex(context))
+
+	var y, ok = blinded.VrfVerify(x, proof[:])
+	return ok && y == tag
+}
+
+// vrfTagIndex computes the derivation index of the VrfTag key for a context.
+func vrfTagIndex(context []byte) []byte {
+	return EncodeIndex([]byte("zed25519_vrf_tag"), context)
 }
@@ -0,0 +1,258 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"bytes"
+	"crypto/sha512"
+	"testing"
+)
+
+func TestDetectVrfEquivocation(t *testing.T) {
+	var sk = testSecret(10)
+	var pk = sk.Public()
+	var x = []byte("round 7")
+
+	var _, proof1 = sk.VrfEval(x)
+	var _, proof2 = sk.VrfEval(x)
+	var eq, err = DetectVrfEquivocation(pk, x, proof1[:], proof2[:])
+	if err != nil || eq {
+		t.Errorf("two honest proofs: DetectVrfEquivocation = %v, %v", eq, err)
+	}
+
+	// a proof on another input, or by another key, does not verify
+	var _, other = sk.VrfEval([]byte("round 8"))
+	if _, err := DetectVrfEquivocation(pk, x, proof1[:], other[:]); err == nil {
+		t.Error("DetectVrfEquivocation accepted a second proof on another input")
+	}
+	var _, foreign = testSecret(11).VrfEval(x)
+	if _, err := DetectVrfEquivocation(pk, x, foreign[:], proof2[:]); err == nil {
+		t.Error("DetectVrfEquivocation accepted a first proof by another key")
+	}
+	if _, err := DetectVrfEquivocation(pk, x, proof1[:], proof2[:95]); err == nil {
+		t.Error("DetectVrfEquivocation accepted a truncated proof")
+	}
+}
+
+func TestVrfChain(t *testing.T) {
+	var sk = testSecret(10)
+	var pk = sk.Public()
+	var seed = []byte("genesis")
+
+	var outputs, proofs = sk.VrfChain(seed, 5)
+	var got, ok = VrfChainIsConsistent(pk, seed, proofs)
+	if !ok || len(got) != len(outputs) {
+		t.Fatalf("valid chain: VrfChainIsConsistent = %d outputs, %v", len(got), ok)
+	}
+	for i := range outputs {
+		if got[i] != outputs[i] {
+			t.Errorf("output %d differs", i)
+		}
+	}
+
+	// the first output is the VRF of the seed, and each feeds the next
+	if y, _ := sk.VrfEval(seed); y != outputs[0] {
+		t.Error("first output is not the VRF of the seed")
+	}
+	if y, _ := sk.VrfEval(outputs[2][:]); y != outputs[3] {
+		t.Error("output 3 is not the VRF of output 2")
+	}
+
+	// replace the middle proof with one on another input
+	var broken = append([]VrfProof(nil), proofs...)
+	_, broken[2] = sk.VrfEval([]byte("not output 1"))
+	got, ok = VrfChainIsConsistent(pk, seed, broken)
+	if ok || len(got) != 2 || got[1] != outputs[1] {
+		t.Errorf("broken chain: VrfChainIsConsistent = %d outputs, %v", len(got), ok)
+	}
+
+	if _, ok := VrfChainIsConsistent(pk, []byte("other seed"), proofs); ok {
+		t.Error("chain verified from another seed")
+	}
+	if _, ok := VrfChainIsConsistent(testSecret(11).Public(), seed, proofs); ok {
+		t.Error("chain verified under another key")
+	}
+	if got, ok := VrfChainIsConsistent(pk, seed, nil); !ok || len(got) != 0 {
+		t.Error("empty chain is not consistent")
+	}
+}
+
+func TestVrfTag(t *testing.T) {
+	var sk = testSecret(10)
+	var pk = sk.Public()
+	var x = []byte("credential")
+
+	var tagA, proofA = sk.VrfTag([]byte("service A"), x)
+	var tagB, proofB = sk.VrfTag([]byte("service B"), x)
+	if !VrfTagVerify(pk, []byte("service A"), x, tagA, proofA) ||
+		!VrfTagVerify(pk, []byte("service B"), x, tagB, proofB) {
+		t.Fatal("valid VRF tags rejected")
+	}
+
+	// tags are unrelated to each other and to the plain VRF output
+	var y, _ = sk.VrfEval(x)
+	if tagA == tagB || tagA == y || tagB == y {
+		t.Error("VRF tags under different contexts are linkable")
+	}
+	if string(proofA[:32]) == string(proofB[:32]) {
+		t.Error("VRF tag proofs under different contexts share V")
+	}
+
+	// deterministic per context
+	// the tag key is the DerivationV3 child for the context
+	var child, _ = sk.DeriveVersion(DerivationV3, vrfTagIndex([]byte("service A")), nil)
+	if y, _ := child.VrfEval(x); y != tagA {
+		t.Error("VrfTag is not the VRF of the DerivationV3 child")
+	}
+	if again, _ := sk.VrfTag([]byte("service A"), x); again != tagA {
+		t.Error("VrfTag is not deterministic")
+	}
+
+	// each proof only verifies for its own context, key and input
+	if VrfTagVerify(pk, []byte("service B"), x, tagA, proofA) {
+		t.Error("VRF tag verified under another context")
+	}
+	if VrfTagVerify(pk, []byte("service A"), x, tagB, proofA) {
+		t.Error("VRF tag verified with another tag")
+	}
+	if VrfTagVerify(testSecret(11).Public(), []byte("service A"), x, tagA, proofA) {
+		t.Error("VRF tag verified under another key")
+	}
+	if VrfTagVerify(pk, []byte("service A"), []byte("other"), tagA, proofA) {
+		t.Error("VRF tag verified on another input")
+	}
+	if _, ok := pk.VrfVerify(x, proofA[:]); ok {
+		t.Error("VRF tag proof verified under the unblinded key")
+	}
+}
+
+func TestVrfInputPoint(t *testing.T) {
+	var sk = testSecret(12)
+	var pk = sk.Public()
+	var x = []byte("round 9")
+	var Bv = pk.VrfInputPoint(x)
+
+	// a precomputed Bv gives the same result as VrfVerifyErr, for each proof
+	for i := 0; i < 3; i++ {
+		var y, proof = sk.VrfEval(x)
+		var y1, err1 = pk.VrfVerifyErr(x, proof[:])
+		var y2, err2 = pk.vrfVerifyInput(sha512.New, x, proof[:], &Bv)
+		if err1 != nil || err2 != nil {
+			t.Fatalf("proof %d: VrfVerifyErr = %v, with Bv = %v", i, err1, err2)
+		}
+		if y1 != y || y2 != y {
+			t.Errorf("proof %d: result with precomputed Bv differs", i)
+		}
+	}
+
+	// Bv depends on the key and the input
+	var other = pk.VrfInputPoint([]byte("round 10"))
+	if PointEqual(&Bv, &other) {
+		t.Error("VrfInputPoint is the same for two inputs")
+	}
+	other = testSecret(13).Public().VrfInputPoint(x)
+	if PointEqual(&Bv, &other) {
+		t.Error("VrfInputPoint is the same for two keys")
+	}
+
+	// the input point of another input does not verify the proof
+	var _, proof = sk.VrfEval(x)
+	other = pk.VrfInputPoint([]byte("round 10"))
+	if _, err := pk.vrfVerifyInput(sha512.New, x, proof[:], &other); err != ErrVrfChallengeMismatch {
+		t.Errorf("wrong Bv: err = %v, want %v", err, ErrVrfChallengeMismatch)
+	}
+}
+
+func TestVrfVerifyErr(t *testing.T) {
+	var sk = testSecret(14)
+	var pk = sk.Public()
+	var x = []byte("round 11")
+	var y, proof = sk.VrfEval(x)
+
+	if res, err := pk.VrfVerifyErr(x, proof[:]); err != nil || res != y {
+		t.Fatalf("VrfVerifyErr = %v", err)
+	}
+	if res, err := pk.VrfVerifyProof(x, &proof); err != nil || res != y {
+		t.Fatalf("VrfVerifyProof = %v", err)
+	}
+	if _, err := ParseVrfProof(proof[:]); err != nil {
+		t.Fatalf("ParseVrfProof = %v", err)
+	}
+
+	// modify returns a copy of proof with bytes [from, from+len(b)) set to b
+	var modify = func(from int, b []byte) []byte {
+		var p = append([]byte(nil), proof[:]...)
+		copy(p[from:], b)
+		return p
+	}
+	var badPoint = make([]byte, 32) // y = 2 has no x on the curve
+	badPoint[0] = 2
+	var unreduced = bytes.Repeat([]byte{0xff}, 32)
+	var flipped = modify(64, []byte{proof[64] ^ 1})
+
+	var vrfErrTests = []struct {
+		name  string
+		proof []byte
+		err   error
+		parse bool // whether ParseVrfProof also rejects it
+	}{
+		{"short", proof[:95], ErrVrfProofLength, true},
+		{"long", append(proof[:], 0), ErrVrfProofLength, true},
+		{"empty", nil, ErrVrfProofLength, true},
+		{"invalid V", modify(0, badPoint), ErrVrfInvalidPoint, true},
+		{"unreduced h", modify(32, unreduced), ErrVrfInvalidScalar, true},
+		{"unreduced s", modify(64, unreduced), ErrVrfInvalidScalar, true},
+		{"small-order V", modify(0, SmallOrderPoints[1][:]), ErrVrfSmallOrder, true},
+		{"flipped s", flipped, ErrVrfChallengeMismatch, false},
+	}
+	for _, test := range vrfErrTests {
+		if res, err := pk.VrfVerifyErr(x, test.proof); err != test.err || res != (VrfResult{}) {
+			t.Errorf("%s: VrfVerifyErr = %v, want %v", test.name, err, test.err)
+		}
+		if _, ok := pk.VrfVerify(x, test.proof); ok {
+			t.Errorf("%s: VrfVerify accepted the proof", test.name)
+		}
+		var _, err = ParseVrfProof(test.proof)
+		if test.parse && err != test.err {
+			t.Errorf("%s: ParseVrfProof = %v, want %v", test.name, err, test.err)
+		}
+		if !test.parse && err != nil {
+			t.Errorf("%s: ParseVrfProof = %v", test.name, err)
+		}
+	}
+
+	// a nil proof, a proof on another input, and a small-order public key
+	if _, err := pk.VrfVerifyProof(x, nil); err != ErrVrfProofLength {
+		t.Errorf("nil proof: VrfVerifyProof = %v, want %v", err, ErrVrfProofLength)
+	}
+	if _, err := pk.VrfVerifyErr([]byte("round 12"), proof[:]); err != ErrVrfChallengeMismatch {
+		t.Errorf("other input: VrfVerifyErr = %v, want %v", err, ErrVrfChallengeMismatch)
+	}
+	var smallPk = PublicFromKey(SmallOrderPoints[1][:])
+	if _, err := smallPk.VrfVerifyErr(x, proof[:]); err != ErrVrfSmallOrder {
+		t.Errorf("small-order key: VrfVerifyErr = %v, want %v", err, ErrVrfSmallOrder)
+	}
+}
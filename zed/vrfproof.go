@@ -0,0 +1,127 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/sha512"
+	"crypto/subtle"
+	"errors"
+)
+
+//
+//  The encoded VRF proof format wraps the raw 96-byte proof of VrfEval with a
+//  version byte, so that the proof format can change in the future (for
+//  example to the ECVRF of RFC 9381) while proofs already stored, such as in
+//  a chain history, remain decodable:
+//
+//    encoded = version || kc || proof
+//    kc      = sha512(vrf_key_str || As)[:16]
+//
+//  Currently the only version is 1, whose proof is the 96-byte (Vs || h || s)
+//  of VrfEval. The key commitment kc binds the encoded proof to the public key
+//  it was made with, so that a proof stored or relayed alongside the wrong key
+//  is rejected when it is decoded, before any curve arithmetic, rather than
+//  later failing verification for no apparent reason.
+//
+
+// Version bytes of the encoded VRF proof format. VrfProofVersion is the one
+// EncodeProof writes.
+const (
+	VrfProofVersion1 = 1
+	VrfProofVersion  = VrfProofVersion1
+)
+
+// vrfKeyCommitmentSize is the size of the key commitment kc.
+const vrfKeyCommitmentSize = 16
+
+// Errors returned by DecodeProof.
+var (
+	ErrVrfProofVersion = errors.New("zed: unknown VRF proof version")
+	ErrVrfProofKey     = errors.New("zed: VRF proof is for another key")
+)
+
+// EncodeProof encodes a raw VRF proof made with the secret key of pk into the
+// current version of the encoded VRF proof format. It returns
+// ErrVrfProofLength if the proof is not 96 bytes long. The proof itself is
+// not checked until it is verified.
+func EncodeProof(pk *Public, proof []byte) ([]byte, error) {
+	if len(proof) != len(VrfProof{}) {
+		return nil, ErrVrfProofLength
+	}
+
+	// encoded = version || kc || proof
+	var kc = vrfKeyCommitment(pk)
+	var encoded = make([]byte, 0, 1+len(kc)+len(proof))
+	encoded = append(encoded, VrfProofVersion)
+	encoded = append(encoded, kc[:]...)
+	encoded = append(encoded, proof...)
+
+	return encoded, nil
+}
+
+// DecodeProof decodes a proof in the encoded VRF proof format, checks that it
+// was made for the public key pk, and returns the raw proof for VrfVerify. It
+// returns ErrVrfProofVersion for an unknown version, ErrVrfProofKey if the key
+// commitment does not match pk, or ErrVrfProofLength if the encoding is
+// truncated or too long. The proof itself is not checked until it is
+// verified.
+func DecodeProof(pk *Public, encoded []byte) (VrfProof, error) {
+	var proof VrfProof
+
+	if len(encoded) < 1 {
+		return proof, ErrVrfProofLength
+	}
+	if encoded[0] != VrfProofVersion1 {
+		return proof, ErrVrfProofVersion
+	}
+	if len(encoded) != 1+vrfKeyCommitmentSize+len(proof) {
+		return proof, ErrVrfProofLength
+	}
+
+	// if kc != commitment(pk), fail
+	var kc = vrfKeyCommitment(pk)
+	if subtle.ConstantTimeCompare(kc[:], encoded[1:1+vrfKeyCommitmentSize]) != 1 {
+		return proof, ErrVrfProofKey
+	}
+
+	copy(proof[:], encoded[1+vrfKeyCommitmentSize:])
+	return proof, nil
+}
+
+// vrfKeyCommitment computes kc = sha512(vrf_key_str || As)[:16].
+func vrfKeyCommitment(pk *Public) [vrfKeyCommitmentSize]byte {
+	var As = pk.Key()
+	var hash = sha512.New()
+	hash.Write([]byte("zed25519_vrf_key"))
+	hash.Write(As[:])
+
+	var res Buffer512
+	hash.Sum(res[:0])
+
+	var kc [vrfKeyCommitmentSize]byte
+	copy(kc[:], res[:])
+	return kc
+}
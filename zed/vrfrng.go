@@ -0,0 +1,121 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"golang.org/x/crypto/sha3"
+)
+
+// VrfRng is a deterministic stream of pseudo-random bytes, seeded by a VRF
+// output, which both the owner of a secret key and any verifier of the VRF
+// proof can reproduce exactly. It implements io.Reader, and never runs out:
+//
+//   stream = shake256(rng_str || cVs)
+//
+// where cVs is the compressed point cofactor * V from which the VRF result is
+// also computed. The stream is only as unpredictable as the VRF output
+// itself: anyone who can predict the VRF output for (pk, x) can predict the
+// whole stream, and once the proof is published, anyone can compute it.
+type VrfRng struct {
+	xof sha3.ShakeHash
+}
+
+// Read fills p with the next len(p) bytes of the stream. It never fails.
+func (rng *VrfRng) Read(p []byte) (int, error) {
+	return rng.xof.Read(p)
+}
+
+// VrfRng evaluates the VRF on input x, as VrfEval does, and returns a random
+// stream seeded by its output, along with the proof which lets a verifier
+// reproduce the same stream with VrfRngFromProof.
+func (sk *Secret) VrfRng(x []byte) (*VrfRng, VrfProof) {
+	var _, proof = sk.VrfEval(x)
+
+	// V = decompress(proof[:32]), which is always valid for our own proof
+	var Vs Buffer256
+	var V Point
+	copy(Vs[:], proof[:32])
+	DecompressPoint(&V, &Vs)
+
+	return newVrfRng(&V), proof
+}
+
+// VrfRngFromProof verifies the VRF proof for pk on input x, and returns the
+// same random stream as the VrfRng call which produced the proof. An error is
+// returned, as by VrfVerifyErr, if the proof is invalid.
+func VrfRngFromProof(pk *Public, x, proof []byte) (*VrfRng, error) {
+	if _, err := pk.VrfVerifyErr(x, proof); err != nil {
+		return nil, err
+	}
+
+	// V = decompress(proof[:32]), which VrfVerifyErr has checked
+	var Vs Buffer256
+	var V Point
+	copy(Vs[:], proof[:32])
+	DecompressPoint(&V, &Vs)
+
+	return newVrfRng(&V), nil
+}
+
+// newVrfRng seeds a stream with shake256(rng_str || compress(cofactor * V)).
+func newVrfRng(V *Point) *VrfRng {
+
+	// cVs = compress(cofactor * V)
+	var cV Point
+	var cVs Buffer256
+	PointClearCofactor(&cV, V)
+	CompressPoint(&cVs, &cV)
+
+	var xof = sha3.NewShake256()
+	xof.Write([]byte("zed25519_vrf_rng"))
+	xof.Write(cVs[:])
+
+	return &VrfRng{xof: xof}
+}
+
+// VrfResultExpand expands the 32-byte VRF result y into n bytes of output,
+// for protocols which need more verifiable randomness per evaluation than a
+// single result:
+//
+//   out = shake256(expand_str || y)[:n]
+//
+// Since y is itself a hash of cofactor * V, the output is a function of the
+// VRF point only, and any verifier who has checked the proof (and so knows
+// y) computes the same bytes. The first bytes of a longer expansion are the
+// same as a shorter one. It panics if n is negative.
+func VrfResultExpand(y VrfResult, n int) []byte {
+	if n < 0 {
+		panic("VrfResultExpand: negative length")
+	}
+
+	var xof = sha3.NewShake256()
+	xof.Write([]byte("zed25519_vrf_expand"))
+	xof.Write(y[:])
+
+	var out = make([]byte, n)
+	xof.Read(out)
+	return out
+}
@@ -0,0 +1,83 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"bytes"
+	"io"
+	"testing"
+)
+
+func TestVrfRng(t *testing.T) {
+	var sk = testSecret(15)
+	var pk = sk.Public()
+	var x = []byte("shuffle 3")
+
+	var signer, proof = sk.VrfRng(x)
+	var verifier, err = VrfRngFromProof(pk, x, proof[:])
+	if err != nil {
+		t.Fatalf("VrfRngFromProof = %v", err)
+	}
+
+	// both streams match byte for byte, however they are read
+	var a = make([]byte, 1000)
+	var b = make([]byte, 1000)
+	if _, err := io.ReadFull(signer, a); err != nil {
+		t.Fatal(err)
+	}
+	for i, n := 0, 1; i < len(b); i, n = i+n, n+7 {
+		if i+n > len(b) {
+			n = len(b) - i
+		}
+		if _, err := verifier.Read(b[i : i+n]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if !bytes.Equal(a, b) {
+		t.Error("signer and verifier streams differ")
+	}
+
+	// each proof on the same input gives the same stream
+	var again, _ = sk.VrfRng(x)
+	var c = make([]byte, 1000)
+	again.Read(c)
+	if !bytes.Equal(a, c) {
+		t.Error("stream differs between two evaluations")
+	}
+
+	// another input gives another stream
+	var other, _ = sk.VrfRng([]byte("shuffle 4"))
+	other.Read(c)
+	if bytes.Equal(a, c) {
+		t.Error("stream is the same for two inputs")
+	}
+
+	// an invalid proof gives no stream
+	proof[64] ^= 1
+	if rng, err := VrfRngFromProof(pk, x, proof[:]); err != ErrVrfChallengeMismatch || rng != nil {
+		t.Errorf("bad proof: VrfRngFromProof = %v, want %v", err, ErrVrfChallengeMismatch)
+	}
+}
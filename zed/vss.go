@@ -0,0 +1,192 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"crypto/rand"
+	"errors"
+	"strconv"
+)
+
+//
+//  Feldman verifiable secret sharing (VSS) extends Shamir's secret sharing so
+//  that participants can check their shares, without trusting the dealer.
+//  The dealer shares a secret scalar a with a random polynomial of degree
+//  t - 1, and publishes a commitment to each of its coefficients:
+//
+//    f(x) = a + c_1 * x + ... + c_t-1 * x^(t-1)
+//    a_i = f(i), for i = 1..n
+//    C_0 = a * G, C_k = c_k * G, for k = 1..t-1
+//
+//  Participant i then checks its share against the commitments with
+//
+//    a_i * G == C_0 + i * C_1 + ... + i^(t-1) * C_t-1
+//
+//  which holds for every share if and only if all the shares lie on the same
+//  polynomial of degree t - 1, so any t of them reconstruct the same secret
+//  a, whose public key is C_0. The right-hand side is also the public share
+//  A_i = a_i * G of participant i, which anyone can compute.
+//
+//  The commitments reveal nothing about a beyond its public key C_0, but
+//  they do reveal C_0, so VSS is only suited to sharing secret keys, whose
+//  public key is public anyway.
+//
+//  REFERENCES:
+//    [1] Paul Feldman, "A Practical Scheme for Non-interactive Verifiable
+//        Secret Sharing"
+//
+
+// VssShare is one participant's share a_i = f(i) of a secret shared with
+// VssSplit. Its Value is secret.
+type VssShare struct {
+	Index uint16
+	Value Scalar
+}
+
+// VssCommitments are the published commitments C_0, ..., C_t-1 to the
+// coefficients of the polynomial a secret was shared with. Their length is
+// the threshold t.
+type VssCommitments []Buffer256
+
+// VssSplit splits the secret scalar into n shares, any t of which can
+// reconstruct it, and returns the commitments which every participant can
+// check their share against with VerifyShare. It returns an error if t or n
+// are out of range.
+func VssSplit(secret *Scalar, t, n int) ([]VssShare, VssCommitments, error) {
+	if t < 1 || t > n || n > 65535 {
+		return nil, nil, errors.New("VssSplit: bad threshold " + strconv.Itoa(t) + " of " + strconv.Itoa(n))
+	}
+
+	var coef, err = vssPolynomial(secret, t)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var shares = make([]VssShare, n)
+	for i := range shares {
+		shares[i] = VssShare{Index: uint16(i + 1), Value: vssEval(coef, uint16(i+1))}
+	}
+	var commitments = vssCommit(coef)
+
+	for k := range coef {
+		wipe(coef[k][:])
+	}
+
+	return shares, commitments, nil
+}
+
+// VerifyShare checks a share against the dealer's commitments, returning true
+// if a_i * G == C_0 + i * C_1 + ... + i^(t-1) * C_t-1. It returns false for
+// malformed commitments.
+func VerifyShare(share VssShare, commitments VssCommitments) bool {
+	if !ValidScalar(&share.Value) || share.Index == 0 {
+		return false
+	}
+	var Ai, err = commitments.ShareKey(share.Index)
+	if err != nil {
+		return false
+	}
+
+	// valid if: a_i * G == A_i
+	var aiG Point
+	ScalarMultBase(&aiG, &share.Value)
+	return PointEqual(&aiG, &Ai.point)
+}
+
+// Public gets the public key C_0 of the shared secret.
+func (c VssCommitments) Public() (*Public, error) {
+	if len(c) < 1 {
+		return nil, errors.New("VssCommitments: no commitments")
+	}
+	var pk, valid = decodePublic(c[0][:])
+	if !valid {
+		return nil, errors.New("VssCommitments: invalid commitment 0")
+	}
+	return pk, nil
+}
+
+// ShareKey computes the public share A_i = C_0 + i * C_1 + ... of the
+// participant with the given index, which is the public key of its share.
+func (c VssCommitments) ShareKey(index uint16) (*Public, error) {
+	if len(c) < 1 {
+		return nil, errors.New("VssCommitments: no commitments")
+	}
+	var x = frostIndexScalar(index)
+
+	// A_i = C_0 + x * (C_1 + x * (C_2 + ...)), by Horner's method
+	var pk = &Public{}
+	for k := len(c) - 1; k >= 0; k-- {
+		var Ck Point
+		if !DecompressPoint(&Ck, &c[k]) {
+			return nil, errors.New("VssCommitments: invalid commitment " + strconv.Itoa(k))
+		}
+		if k == len(c)-1 {
+			pk.point = Ck
+			continue
+		}
+		ScalarMultPointVartime(&pk.point, &x, &pk.point)
+		PointAdd(&pk.point, &pk.point, &Ck)
+	}
+
+	return pk, nil
+}
+
+// vssPolynomial picks the coefficients (a % q, c_1, ..., c_t-1) of a random
+// polynomial of degree t - 1 with f(0) = a.
+func vssPolynomial(a *Scalar, t int) ([]Scalar, error) {
+	var coef = make([]Scalar, t)
+	ScalarMultScalarAddScalar(&coef[0], &scalarOne, a, &Scalar{})
+	for k := 1; k < t; k++ {
+		var buf Buffer512
+		if _, err := rand.Read(buf[:]); err != nil {
+			return nil, err
+		}
+		ScalarReduce512(&coef[k], &buf)
+	}
+	return coef, nil
+}
+
+// vssEval evaluates the polynomial at the participant index i, by Horner's
+// method.
+func vssEval(coef []Scalar, i uint16) Scalar {
+	var x = frostIndexScalar(i)
+	var y Scalar
+	for k := len(coef) - 1; k >= 0; k-- {
+		ScalarMultScalarAddScalar(&y, &y, &x, &coef[k])
+	}
+	return y
+}
+
+// vssCommit computes the commitments C_k = c_k * G to the coefficients.
+func vssCommit(coef []Scalar) VssCommitments {
+	var commitments = make(VssCommitments, len(coef))
+	for k := range coef {
+		var Ck Point
+		ScalarMultBase(&Ck, &coef[k])
+		CompressPoint(&commitments[k], &Ck)
+	}
+	return commitments
+}
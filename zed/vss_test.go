@@ -0,0 +1,159 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import "testing"
+
+// vssRecover interpolates f(0) from the given shares.
+func vssRecover(shares []VssShare) Scalar {
+	var signers = make([]FrostCommitment, len(shares))
+	for i := range shares {
+		signers[i].Index = shares[i].Index
+	}
+	var a Scalar
+	for i := range shares {
+		var l = frostLagrange(signers, i)
+		ScalarMultScalarAddScalar(&a, &l, &shares[i].Value, &a)
+	}
+	return a
+}
+
+func TestVssSplit(t *testing.T) {
+	var secret Scalar
+	var buf = Buffer512{1, 2, 3, 4, 5}
+	ScalarReduce512(&secret, &buf)
+
+	var shares, commitments, err = VssSplit(&secret, 3, 5)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(shares) != 5 || len(commitments) != 3 {
+		t.Fatalf("VssSplit: got %d shares and %d commitments", len(shares), len(commitments))
+	}
+
+	// C_0 = a * G
+	var A Point
+	ScalarMultBase(&A, &secret)
+	var pk, _ = commitments.Public()
+	if pk.Key() != PointToKey(&A) {
+		t.Error("Public is not a * G")
+	}
+
+	for i, share := range shares {
+		if share.Index != uint16(i+1) {
+			t.Errorf("share %d has index %d", i+1, share.Index)
+		}
+		if !VerifyShare(share, commitments) {
+			t.Errorf("share %d does not verify", i+1)
+		}
+
+		// A_i = a_i * G
+		var Ai Point
+		ScalarMultBase(&Ai, &share.Value)
+		var key, err = commitments.ShareKey(share.Index)
+		if err != nil || key.Key() != PointToKey(&Ai) {
+			t.Errorf("ShareKey(%d) is not a_%d * G: %v", i+1, i+1, err)
+		}
+	}
+
+	// any 3 shares recover the secret, 2 do not
+	for _, set := range [][]int{{0, 1, 2}, {0, 2, 4}, {4, 3, 1}} {
+		var subset = []VssShare{shares[set[0]], shares[set[1]], shares[set[2]]}
+		if a := vssRecover(subset); !ScalarEqual(&a, &secret) {
+			t.Errorf("shares %v do not recover the secret", set)
+		}
+	}
+	if a := vssRecover(shares[:2]); ScalarEqual(&a, &secret) {
+		t.Error("2 shares recover the secret")
+	}
+}
+
+func TestVerifyShareTampered(t *testing.T) {
+	var secret = Scalar{7}
+	var shares, commitments, _ = VssSplit(&secret, 2, 3)
+	var others, _, _ = VssSplit(&secret, 2, 3)
+
+	var tampered = shares[1]
+	tampered.Value[0] ^= 1
+	if VerifyShare(tampered, commitments) {
+		t.Error("VerifyShare accepted a tampered value")
+	}
+
+	var moved = shares[1]
+	moved.Index = 3
+	if VerifyShare(moved, commitments) {
+		t.Error("VerifyShare accepted a share under another index")
+	}
+
+	// the same secret, shared with another polynomial
+	if VerifyShare(others[1], commitments) {
+		t.Error("VerifyShare accepted a share of another split")
+	}
+
+	var zero = shares[0]
+	zero.Index = 0
+	if VerifyShare(zero, commitments) {
+		t.Error("VerifyShare accepted index 0")
+	}
+
+	var badCommitments = append(VssCommitments{}, commitments...)
+	badCommitments[1] = Buffer256{2}
+	if VerifyShare(shares[0], badCommitments) {
+		t.Error("VerifyShare accepted an invalid commitment")
+	}
+	if _, err := badCommitments.ShareKey(1); err == nil {
+		t.Error("ShareKey accepted an invalid commitment")
+	}
+	if VerifyShare(shares[0], nil) {
+		t.Error("VerifyShare accepted no commitments")
+	}
+	if _, err := VssCommitments(nil).Public(); err == nil {
+		t.Error("Public accepted no commitments")
+	}
+}
+
+var vssSplitErrorTests = []struct{ t, n int }{
+	{0, 3},
+	{4, 3},
+	{1, 65536},
+}
+
+func TestVssSplitErrors(t *testing.T) {
+	var secret = Scalar{1}
+	for _, test := range vssSplitErrorTests {
+		if _, _, err := VssSplit(&secret, test.t, test.n); err == nil {
+			t.Errorf("VssSplit(%d, %d) did not fail", test.t, test.n)
+		}
+	}
+
+	// a threshold of 1 gives every participant the secret
+	var shares, _, _ = VssSplit(&secret, 1, 2)
+	for _, share := range shares {
+		if !ScalarEqual(&share.Value, &secret) {
+			t.Errorf("share %d of a 1-of-2 split is not the secret", share.Index)
+		}
+	}
+}
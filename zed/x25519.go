@@ -0,0 +1,107 @@
+// ZooBC zed25519
+//
+// Copyright © 2020 Quasisoft Limited - Hong Kong
+//
+// ZooBC is architected by Roberto Capodieci & Barton Johnston
+//             contact us at roberto.capodieci[at]blockchainzoo.com
+//             and barton.johnston[at]blockchainzoo.com
+//
+// Permission is hereby granted, free of charge, to any person obtaining a
+// copy of this software and associated documentation files (the "Software"),
+// to deal in the Software without restriction, including without limitation
+// the rights to use, copy, modify, merge, publish, distribute, sublicense,
+// and/or sell copies of the Software, and to permit persons to whom the
+// Software is furnished to do so, subject to the following conditions:
+//
+// The above copyright notice and this permission notice shall be included in
+// all copies or substantial portions of the Software.
+//
+// THE SOFTWARE IS PROVIDED "AS IS", WITHOUT WARRANTY OF ANY KIND, EXPRESS OR
+// IMPLIED, INCLUDING BUT NOT LIMITED TO THE WARRANTIES OF MERCHANTABILITY,
+// FITNESS FOR A PARTICULAR PURPOSE AND NONINFRINGEMENT. IN NO EVENT SHALL THE
+// AUTHORS OR COPYRIGHT HOLDERS BE LIABLE FOR ANY CLAIM, DAMAGES OR OTHER
+// LIABILITY, WHETHER IN AN ACTION OF CONTRACT, TORT OR OTHERWISE, ARISING FROM,
+// OUT OF OR IN CONNECTION WITH THE SOFTWARE OR THE USE OR OTHER DEALINGS IN THE
+// SOFTWARE.
+package zed
+
+import (
+	"errors"
+)
+
+//
+//  X25519 is the Diffie-Hellman key agreement function on Curve25519, which
+//  is the same curve as Ed25519 in Montgomery form instead of twisted Edwards
+//  form. A point (x, y) on the Edwards curve corresponds to the point with
+//  Montgomery u-coordinate:
+//
+//    u = (1 + y) / (1 - y)
+//
+//  so an Ed25519 identity keypair can also be used for key agreement, as
+//  libsodium allows with crypto_sign_ed25519_pk_to_curve25519 and
+//  crypto_sign_ed25519_sk_to_curve25519. Using one keypair for both is safe
+//  for Ed25519 and X25519 as specified, but ties the two uses together: a
+//  compromise of either is a compromise of both.
+//
+//  DH does its multiplication on the Edwards form of the curve, and only
+//  converts the result, so that it works with every Secret, including derived
+//  keys whose scalars are not clamped. For keys created with SecretFromSeed,
+//  the result is the same as X25519(sk.ToX25519(), pk.ToX25519()).
+//
+
+// ToX25519 gets the X25519 private key corresponding to the secret key, which
+// is its private scalar. For a key created with SecretFromSeed, this is the
+// same key as libsodium's crypto_sign_ed25519_sk_to_curve25519 produces. For
+// other keys, the scalar may not be clamped, and X25519 implementations will
+// clamp it into a different key; use DH with such keys instead.
+func (sk *Secret) ToX25519() Buffer256 {
+	return sk.scalar
+}
+
+// ToX25519 gets the X25519 public key (the Montgomery u-coordinate)
+// corresponding to the public key:
+//
+//   u = (Z + Y) / (Z - Y)
+func (pk *Public) ToX25519() Buffer256 {
+	return pointToMontgomery(&pk.point)
+}
+
+// DH computes the Diffie-Hellman shared secret between the secret key sk and
+// a peer's public key, as an X25519 u-coordinate, such that
+// DH(aliceSecret, bobPublic) == DH(bobSecret, alicePublic). The raw shared
+// secret should be passed through a key derivation function before it is used
+// as a key. An error is returned if the peer's public key has a small-order
+// (torsion) component, which an honest peer's key never has, since it could
+// otherwise be used to learn the low bits of a derived key's scalar.
+func DH(sk *Secret, peer *Public) (Buffer256, error) {
+	if peer.HasTorsionComponent() {
+		return Buffer256{}, errors.New("DH: peer public key has a torsion component")
+	}
+
+	// S = a * A_peer
+	var S Point
+	ScalarMultPoint(&S, &sk.scalar, &peer.point)
+
+	// if S == I, fail
+	var I Point
+	PointIdentity(&I)
+	if PointEqual(&S, &I) {
+		return Buffer256{}, errors.New("DH: shared secret is the identity")
+	}
+
+	return pointToMontgomery(&S), nil
+}
+
+// pointToMontgomery computes u = (Z + Y) / (Z - Y) for a point in extended
+// coordinates.
+func pointToMontgomery(p *Point) Buffer256 {
+	var n, d, u FieldElement
+	FeAdd(&n, &p.Z, &p.Y)
+	FeSub(&d, &p.Z, &p.Y)
+	FeInvert(&d, &d)
+	FeMul(&u, &n, &d)
+
+	var us Buffer256
+	FeToBytes(&us, &u)
+	return us
+}